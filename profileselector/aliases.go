@@ -0,0 +1,72 @@
+package profileselector
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// aliasesPath はプロファイルの別名を記述するファイルのパスを返します。
+func aliasesPath() (string, error) {
+	dir, err := toolConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "aliases.ini"), nil
+}
+
+// loadAliases は別名ファイルを読み込み、プロファイル名と別名の対応を返します。
+// ファイルには 1 行に 1 つ「別名 = プロファイル名」の形式で記述し、同じプロファイルに複数の別名がある場合は先に記述したものを使います。
+// ファイルが存在しない場合は空の対応を返します。
+func loadAliases(path string) (map[string]string, error) {
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	cfg, err := ini.Load(path)
+	if err != nil {
+		return nil, readFileError("別名ファイル", path, err)
+	}
+	aliases := map[string]string{}
+	for _, key := range cfg.Section(ini.DefaultSection).Keys() {
+		alias, name := strings.TrimSpace(key.Name()), strings.TrimSpace(key.Value())
+		if alias == "" || name == "" {
+			continue
+		}
+		if _, ok := aliases[name]; !ok {
+			aliases[name] = alias
+		}
+	}
+	return aliases, nil
+}
+
+// loadAliasesOrEmpty は別名を読み込みます。読み込めない場合は空の対応を返します。
+func loadAliasesOrEmpty() map[string]string {
+	path, err := aliasesPath()
+	if err != nil {
+		return map[string]string{}
+	}
+	aliases, err := loadAliases(path)
+	if err != nil {
+		return map[string]string{}
+	}
+	return aliases
+}
+
+// applyAliases は profiles の各プロファイルに、aliases に記述された別名を設定します。
+func applyAliases(profiles []awsProfile, aliases map[string]string) {
+	for i := range profiles {
+		profiles[i].Alias = aliases[profiles[i].Name]
+	}
+}
+
+// DisplayName は一覧に表示するプロファイルの名前を返します。別名があれば別名を、なければプロファイル名を返します。
+func (p awsProfile) DisplayName() string {
+	if p.Alias != "" {
+		return p.Alias
+	}
+	return p.Name
+}
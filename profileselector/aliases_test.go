@@ -0,0 +1,90 @@
+package profileselector
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadAliases(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string // 空の場合はファイルを作成しない
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "ファイルなし", want: map[string]string{}},
+		{
+			name:    "別名 = プロファイル名",
+			content: "prod = acct-123456789012-admin\ndev = acct-210987654321-dev\n",
+			want:    map[string]string{"acct-123456789012-admin": "prod", "acct-210987654321-dev": "dev"},
+		},
+		{
+			name:    "同じプロファイルの別名は先に記述したもの",
+			content: "prod = acct-1\nproduction = acct-1\n",
+			want:    map[string]string{"acct-1": "prod"},
+		},
+		{name: "値が空の行は無視", content: "prod =\n", want: map[string]string{}},
+		{name: "解析できない", content: "[broken\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "aliases.ini")
+			if tt.content != "" {
+				path = writePatternFile(t, tt.content)
+			}
+			got, err := loadAliases(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadAliases() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadAliases() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyAliases(t *testing.T) {
+	profiles := testProfiles("acct-1", "acct-2")
+	applyAliases(profiles, map[string]string{"acct-1": "prod"})
+	tests := []struct {
+		profile     awsProfile
+		wantDisplay string
+	}{
+		{profile: profiles[0], wantDisplay: "prod"},
+		{profile: profiles[1], wantDisplay: "acct-2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.profile.Name, func(t *testing.T) {
+			if got := tt.profile.DisplayName(); got != tt.wantDisplay {
+				t.Errorf("DisplayName() = %q, want %q", got, tt.wantDisplay)
+			}
+		})
+	}
+}
+
+func TestSearchAndSelectByAlias(t *testing.T) {
+	profiles := testProfiles("acct-1", "acct-2", "staging")
+	applyAliases(profiles, map[string]string{"acct-1": "prod", "acct-2": "dev"})
+	tests := []struct {
+		name         string
+		keys         []string
+		wantNames    []string
+		wantSelected string
+	}{
+		{name: "別名で検索", keys: []string{"/", "p", "r", "o", "enter"}, wantNames: []string{"acct-1"}},
+		{name: "プロファイル名でも検索", keys: []string{"/", "a", "c", "c", "t", "-", "2", "enter"}, wantNames: []string{"acct-2"}},
+		{name: "選択するとプロファイル名を出力", keys: []string{"/", "d", "e", "v", "enter", "enter"}, wantNames: []string{"acct-2"}, wantSelected: "acct-2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := pressKeys(t, newTestModel(t, options{}, profiles), tt.keys...)
+			if got := profileNames(m.profiles); !reflect.DeepEqual(got, tt.wantNames) {
+				t.Errorf("一覧 = %v, want %v", got, tt.wantNames)
+			}
+			if m.selectedProfile != tt.wantSelected {
+				t.Errorf("selectedProfile = %q, want %q", m.selectedProfile, tt.wantSelected)
+			}
+		})
+	}
+}
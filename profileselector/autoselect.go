@@ -0,0 +1,33 @@
+package profileselector
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// timeoutTickMsg は --timeout による自動選択までの残り時間を 1 秒ごとに減らすためのメッセージです。
+type timeoutTickMsg struct{}
+
+// timeoutTickCmd は 1 秒後に timeoutTickMsg を送るコマンドです。
+func timeoutTickCmd() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return timeoutTickMsg{}
+	})
+}
+
+// updateTimeout は自動選択までの残り時間を 1 秒減らし、0 になったらカーソル位置のプロファイルを selectProfile で選択します。
+// キー入力で自動選択が取り消されている場合は何もしません。
+func (m model) updateTimeout() (tea.Model, tea.Cmd) {
+	if m.timeoutRemaining <= 0 {
+		return m, nil
+	}
+	m.timeoutRemaining--
+	if m.timeoutRemaining > 0 {
+		return m, timeoutTickCmd()
+	}
+	if len(m.profiles) == 0 {
+		return m, nil
+	}
+	return m.selectProfile(m.profiles[m.cursor])
+}
@@ -0,0 +1,96 @@
+package profileselector
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+	"time"
+)
+
+// defaultBenchmarkRuns は benchmark サブコマンドでプロファイルを読み込む回数の既定値です。
+const defaultBenchmarkRuns = 100
+
+// benchmarkResult はプロファイルの読み込みを繰り返し計測した結果です。
+type benchmarkResult struct {
+	runs     int           // 計測した回数
+	profiles int           // 最後の読み込みで得られたプロファイルの数
+	min      time.Duration // 最短の所要時間
+	max      time.Duration // 最長の所要時間
+	mean     time.Duration // 所要時間の平均
+	p99      time.Duration // 所要時間の 99 パーセンタイル
+	err      error         // 読み込みに失敗した場合のエラー
+}
+
+// runBenchmark は loader を runs 回呼び出して、1 回ごとの所要時間の統計を返します。
+// loader がエラーを返した場合は、その時点で計測をやめて err にエラーを設定した結果を返します。
+func runBenchmark(loader func() ([]awsProfile, error), runs int) benchmarkResult {
+	durations := make([]time.Duration, 0, runs)
+	var profiles []awsProfile
+	for i := 0; i < runs; i++ {
+		start := time.Now()
+		var err error
+		profiles, err = loader()
+		if err != nil {
+			return benchmarkResult{runs: i, err: fmt.Errorf("%d 回目の読み込みに失敗しました: %w", i+1, err)}
+		}
+		durations = append(durations, time.Since(start))
+	}
+	return summarizeDurations(durations, len(profiles))
+}
+
+// summarizeDurations は所要時間の一覧から最短、最長、平均、99 パーセンタイル (最近傍順位法) を求めます。
+func summarizeDurations(durations []time.Duration, profiles int) benchmarkResult {
+	result := benchmarkResult{runs: len(durations), profiles: profiles}
+	if len(durations) == 0 {
+		return result
+	}
+	sorted := slices.Clone(durations)
+	slices.Sort(sorted)
+
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	rank := int(math.Ceil(0.99 * float64(len(sorted))))
+	result.min = sorted[0]
+	result.max = sorted[len(sorted)-1]
+	result.mean = total / time.Duration(len(sorted))
+	result.p99 = sorted[rank-1]
+	return result
+}
+
+// milliseconds は d をミリ秒単位の小数で返します。
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// runBenchmarkCommand は benchmark サブコマンドを実行し、終了コードを返します。
+// プロファイルの読み込みを --runs 回繰り返し、所要時間の統計を標準出力に書き込みます。
+func runBenchmarkCommand(args []string) int {
+	fs := flag.NewFlagSet("benchmark", flag.ContinueOnError)
+	runs := fs.Int("runs", defaultBenchmarkRuns, "プロファイルを読み込む回数")
+	configPaths := fs.String("config", "", "読み込む設定ファイルのパス (カンマ区切りで複数指定すると順に重ねて読み込む)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *runs < 1 {
+		fmt.Fprintf(os.Stderr, "エラー: --runs には 1 以上の回数を指定してください: %d\n", *runs)
+		return 2
+	}
+	sources := profileSources{configPaths: splitConfigPaths(*configPaths)}
+
+	result := runBenchmark(func() ([]awsProfile, error) { return loadAWSProfiles(sources) }, *runs)
+	if result.err != nil {
+		fmt.Fprintf(os.Stderr, "エラー: %s\n", describeLoadError(result.err))
+		return 1
+	}
+	fmt.Printf("プロファイル数: %d\n", result.profiles)
+	fmt.Printf("読み込み回数: %d\n", result.runs)
+	fmt.Printf("min:  %.3f ms\n", milliseconds(result.min))
+	fmt.Printf("max:  %.3f ms\n", milliseconds(result.max))
+	fmt.Printf("mean: %.3f ms\n", milliseconds(result.mean))
+	fmt.Printf("p99:  %.3f ms\n", milliseconds(result.p99))
+	return 0
+}
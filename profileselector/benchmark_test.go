@@ -0,0 +1,79 @@
+package profileselector
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSummarizeDurations(t *testing.T) {
+	ms := time.Millisecond
+	hundred := make([]time.Duration, 100)
+	for i := range hundred {
+		hundred[i] = time.Duration(100-i) * ms
+	}
+	tests := []struct {
+		name      string
+		durations []time.Duration
+		want      benchmarkResult
+	}{
+		{name: "計測なし", want: benchmarkResult{}},
+		{name: "1 回", durations: []time.Duration{5 * ms}, want: benchmarkResult{runs: 1, min: 5 * ms, max: 5 * ms, mean: 5 * ms, p99: 5 * ms}},
+		{name: "並びは問わない", durations: []time.Duration{3 * ms, 1 * ms, 2 * ms}, want: benchmarkResult{runs: 3, min: ms, max: 3 * ms, mean: 2 * ms, p99: 3 * ms}},
+		{name: "100 回の 99 パーセンタイル", durations: hundred, want: benchmarkResult{runs: 100, min: ms, max: 100 * ms, mean: 50500 * time.Microsecond, p99: 99 * ms}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarizeDurations(tt.durations, 0); got != tt.want {
+				t.Errorf("summarizeDurations() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunBenchmark(t *testing.T) {
+	tests := []struct {
+		name         string
+		runs         int
+		failAt       int // 0 の場合は失敗しない
+		wantRuns     int
+		wantProfiles int
+		wantErr      bool
+	}{
+		{name: "全て成功", runs: 20, wantRuns: 20, wantProfiles: 3},
+		{name: "1 回だけ", runs: 1, wantRuns: 1, wantProfiles: 3},
+		{name: "途中で失敗", runs: 20, failAt: 5, wantRuns: 4, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			loader := func() ([]awsProfile, error) {
+				calls++
+				if calls == tt.failAt {
+					return nil, errors.New("読み込みに失敗しました")
+				}
+				time.Sleep(time.Duration(calls%3) * 100 * time.Microsecond)
+				return testProfiles("dev", "prod", "stg"), nil
+			}
+			got := runBenchmark(loader, tt.runs)
+			if (got.err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", got.err, tt.wantErr)
+			}
+			if got.runs != tt.wantRuns {
+				t.Errorf("runs = %d, want %d", got.runs, tt.wantRuns)
+			}
+			if tt.wantErr {
+				if calls != tt.failAt {
+					t.Errorf("失敗した後も読み込みを続けました (%d 回)", calls)
+				}
+				return
+			}
+			if got.profiles != tt.wantProfiles {
+				t.Errorf("profiles = %d, want %d", got.profiles, tt.wantProfiles)
+			}
+			if got.min <= 0 || got.min > got.mean || got.mean > got.max || got.p99 < got.min || got.p99 > got.max {
+				t.Errorf("統計の大小関係が不正です: min=%v mean=%v p99=%v max=%v", got.min, got.mean, got.p99, got.max)
+			}
+		})
+	}
+}
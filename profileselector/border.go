@@ -0,0 +1,63 @@
+package profileselector
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// borderHeaderHeight は枠付きの表示 (--border) でのヘッダーの行数です。
+// 1行目: タイトルを含む枠の上辺, 2〜3行目: タブバー, 4行目: 種類ごとのプロファイル数
+const borderHeaderHeight = 4
+
+// borderFooterHeight は枠付きの表示 (--border) でのフッターの行数です。
+// 1. 枠の下辺
+// 2. ヘルプテキスト (ウィンドウの幅で折り返した 2 行目以降は helpHeight で数える)
+// 3. ステータス情報
+const borderFooterHeight = 3
+
+// borderInset は枠付きの表示で、左右の枠線と内側の余白が占める幅の合計です。
+const borderInset = 4
+
+// chromeHeight はウィンドウの高さのうち、プロファイルの一覧以外 (ヘッダー、フッター、一覧の下のプレビューペイン) が占める行数を返します。
+// --compact の指定時はヘッダーもフッターも表示しません。
+func (m model) chromeHeight() int {
+	switch {
+	case m.compact:
+		return m.previewPaneHeight()
+	case m.border:
+		return borderHeaderHeight + borderFooterHeight + m.helpHeight() - 1 + m.previewPaneHeight()
+	}
+	return headerHeight + footerHeight + m.helpHeight() - 1 + m.previewPaneHeight()
+}
+
+// innerWidth はプロファイルの一覧を描画できる幅を返します。枠付きの表示では枠線と余白の分だけ狭くなります。
+func (m model) innerWidth() int {
+	if m.border {
+		return max(m.windowWidth-borderInset, 0)
+	}
+	return m.windowWidth
+}
+
+// renderBox は content を角の丸い枠で囲み、枠の上辺に label を表示します。
+// 枠の高さは一覧の表示行数 (一覧の下のプレビューペインを含む) に合わせ、一覧が短い場合も下辺の位置を変えません。
+func (m model) renderBox(label, content string) string {
+	box := m.theme.style().
+		Border(lipgloss.RoundedBorder(), false, true, true, true).
+		BorderForeground(m.theme.muted).
+		Padding(0, 1).
+		Width(max(m.windowWidth-2, 0)).
+		Height(borderHeaderHeight - 1 + m.listVisibleHeight + m.previewPaneHeight())
+	return renderBoxTop(label, m.windowWidth, m.theme.style().Foreground(m.theme.muted)) + "\n" + box.Render(strings.TrimSuffix(content, "\n"))
+}
+
+// renderBoxTop は label を埋め込んだ幅 width の枠の上辺を lineStyle の線で描画します。
+// label が収まらない場合は枠線だけを描画します。
+func renderBoxTop(label string, width int, lineStyle lipgloss.Style) string {
+	b := lipgloss.RoundedBorder()
+	fill := width - lipgloss.Width(label) - 5 // "╭─ " と " " と "╮" の分
+	if fill < 0 {
+		return lineStyle.Render(b.TopLeft + strings.Repeat(b.Top, max(width-2, 0)) + b.TopRight)
+	}
+	return lineStyle.Render(b.TopLeft+b.Top+" ") + label + lineStyle.Render(" "+strings.Repeat(b.Top, fill)+b.TopRight)
+}
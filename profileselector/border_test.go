@@ -0,0 +1,94 @@
+package profileselector
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestRenderBoxTop(t *testing.T) {
+	tests := []struct {
+		name  string
+		label string
+		width int
+		want  string
+	}{
+		{name: "ラベルが収まる", label: "AWS", width: 12, want: "╭─ AWS ────╮"},
+		{name: "ちょうど収まる", label: "AWS", width: 8, want: "╭─ AWS ╮"},
+		{name: "ラベルが収まらない", label: "AWS", width: 7, want: "╭─────╮"},
+		{name: "幅が 2 未満", label: "AWS", width: 1, want: "╭╮"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := renderBoxTop(tt.label, tt.width, lipgloss.NewStyle()); got != tt.want {
+				t.Errorf("renderBoxTop(%q, %d) = %q, want %q", tt.label, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseOptionsBorder(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{args: nil, want: false},
+		{args: []string{"--border"}, want: true},
+		{args: []string{"--border", "--no-border"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			opts, err := parseOptionsTo(io.Discard, tt.args)
+			if err != nil {
+				t.Fatalf("parseOptionsTo() error = %v", err)
+			}
+			if opts.border != tt.want {
+				t.Errorf("border = %v, want %v", opts.border, tt.want)
+			}
+		})
+	}
+}
+
+func TestBorderLayoutFitsWindow(t *testing.T) {
+	tests := []struct {
+		name   string
+		border bool
+		width  int
+		height int
+	}{
+		{name: "枠なし", width: 100, height: 30},
+		{name: "枠付き", border: true, width: 100, height: 30},
+		{name: "枠付きで狭いウィンドウ", border: true, width: 40, height: 20},
+		{name: "枠なしで幅 80", width: 80, height: 24},
+		{name: "枠付きで幅 80", border: true, width: 80, height: 24},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t, options{border: tt.border}, syntheticProfiles(50))
+			next, _ := m.Update(tea.WindowSizeMsg{Width: tt.width, Height: tt.height})
+			m = next.(model)
+			if want := tt.height - m.chromeHeight(); m.listVisibleHeight != want {
+				t.Errorf("listVisibleHeight = %d, want %d", m.listVisibleHeight, want)
+			}
+			lines := strings.Split(strings.TrimSuffix(m.View(), "\n"), "\n")
+			if len(lines) > tt.height {
+				t.Errorf("View() の行数 = %d, ウィンドウの高さ %d を超えています", len(lines), tt.height)
+			}
+			for i, line := range lines {
+				if w := ansi.StringWidth(line); w > tt.width {
+					t.Errorf("%d 行目の幅 = %d, ウィンドウの幅 %d を超えています: %q", i+1, w, tt.width, line)
+				}
+			}
+			if !strings.Contains(ansi.Strip(m.View()), "q/Ctrl+C:終了") {
+				t.Error("View() に終了のキーの説明が表示されていません")
+			}
+			if got := strings.HasPrefix(lines[0], "╭"); got != tt.border {
+				t.Errorf("1 行目が枠の上辺か = %v, want %v", got, tt.border)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package profileselector
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// defaultPreviewHeight は --preview の指定時に一覧の下に表示するプレビューペインのデフォルトの行数です。
+const defaultPreviewHeight = 8
+
+// previewPaneHeight は一覧の下のプレビューペインが占める行数 (区切り線を含む) を返します。
+// --preview が指定されていない場合は 0 を返します。
+func (m model) previewPaneHeight() int {
+	if m.previewHeight <= 0 {
+		return 0
+	}
+	return m.previewHeight + 1
+}
+
+// initialPreviewHeight は起動時の一覧の下のプレビューペインの行数を返します。--preview が指定されていなければ 0 を返します。
+func initialPreviewHeight(opts options) int {
+	if !opts.preview {
+		return 0
+	}
+	return opts.previewHeight
+}
+
+// renderBottomPreview はカーソル位置のプロファイル p のキーと値を 1 行に 1 つ「キー = 値」の形式で、
+// 区切り線の下の幅 width、高さ height 以内のペインとして描画します。
+// 秘密情報のキーの値は previewValue で伏せ字にします。
+func renderBottomPreview(p awsProfile, width, height int, th theme) string {
+	var lines []string
+	switch {
+	case p.Unset:
+		lines = append(lines, th.style().Faint(true).Render("選択すると AWS_DEFAULT_PROFILE を削除します"))
+	case len(p.RawKeys) == 0:
+		lines = append(lines, th.style().Faint(true).Render("(キーがありません)"))
+	default:
+		keys := make([]string, 0, len(p.RawKeys))
+		for key := range p.RawKeys {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		keyStyle := th.style().Foreground(th.accent)
+		for _, key := range keys {
+			lines = append(lines, fmt.Sprintf("%s = %s", keyStyle.Render(key), previewValue(key, p.RawKeys[key])))
+		}
+	}
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, width, "…")
+	}
+	rule := th.style().Foreground(th.muted).Render(strings.Repeat("─", width))
+	return rule + "\n" + strings.Join(lines, "\n") + "\n"
+}
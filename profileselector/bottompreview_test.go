@@ -0,0 +1,60 @@
+package profileselector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestRenderBottomPreview(t *testing.T) {
+	rule := strings.Repeat("─", 60)
+	tests := []struct {
+		name    string
+		profile awsProfile
+		height  int
+		want    string
+	}{
+		{
+			name:    "キーを名前順に表示",
+			profile: awsProfile{Name: "dev", RawKeys: map[string]string{"region": "ap-northeast-1", "output": "json"}},
+			height:  4,
+			want:    rule + "\noutput = json\nregion = ap-northeast-1\n",
+		},
+		{
+			name:    "高さを超える行は表示しない",
+			profile: awsProfile{Name: "dev", RawKeys: map[string]string{"a": "1", "b": "2", "c": "3"}},
+			height:  2,
+			want:    rule + "\na = 1\nb = 2\n",
+		},
+		{
+			name: "秘密情報のキーの値は伏せ字",
+			profile: awsProfile{Name: "dev", RawKeys: map[string]string{
+				"aws_secret_access_key": "super-secret",
+				"aws_session_token":     "session-token",
+			}},
+			height: 4,
+			want:   rule + "\naws_secret_access_key = ********\naws_session_token = ********\n",
+		},
+		{
+			name:    "キーがない",
+			profile: awsProfile{Name: "empty"},
+			height:  4,
+			want:    rule + "\n(キーがありません)\n",
+		},
+		{
+			name:    "プロファイルの設定を解除する項目",
+			profile: awsProfile{Name: unsetProfileName, Unset: true},
+			height:  4,
+			want:    rule + "\n選択すると AWS_DEFAULT_PROFILE を削除します\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ansi.Strip(renderBottomPreview(tt.profile, 60, tt.height, darkTheme))
+			if got != tt.want {
+				t.Errorf("renderBottomPreview() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
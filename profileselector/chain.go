@@ -0,0 +1,95 @@
+package profileselector
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxChainDepth は source_profile をたどる最大の深さです。これを超えた場合は循環しているとみなします。
+const maxChainDepth = 10
+
+// profilesByName はプロファイル名をキーにしたマップを返します。同じ名前のプロファイルは先に出現したものを優先します。
+func profilesByName(profiles []awsProfile) map[string]awsProfile {
+	byName := make(map[string]awsProfile, len(profiles))
+	for _, p := range profiles {
+		if _, ok := byName[p.Name]; !ok {
+			byName[p.Name] = p
+		}
+	}
+	return byName
+}
+
+// ResolveChain は source_profile をたどり、起点のプロファイルからこのプロファイルまでの継承チェーンを返します。
+// 参照先のプロファイルが存在しない場合や、チェーンが循環している場合はエラーを返します。
+// 自分自身を source_profile に指定したプロファイルはチェーンの起点として扱います。
+func (p awsProfile) ResolveChain(all map[string]awsProfile) ([]awsProfile, error) {
+	chain := []awsProfile{p}
+	current := p
+	for {
+		source := current.RawKeys["source_profile"]
+		if source == "" || source == current.Name {
+			break
+		}
+		if len(chain) > maxChainDepth {
+			return nil, fmt.Errorf("source_profile が循環しています: %s", formatChain(chain))
+		}
+		next, ok := all[source]
+		if !ok {
+			return nil, fmt.Errorf("source_profile で指定されたプロファイル %s が見つかりません (参照元: %s)", source, current.Name)
+		}
+		chain = append(chain, next)
+		current = next
+	}
+
+	// 起点 (root) から順に並べる
+	for i, j := 0, len(chain)-1; i < j; i, j = i+1, j-1 {
+		chain[i], chain[j] = chain[j], chain[i]
+	}
+	return chain, nil
+}
+
+// markSourceCycles は source_profile をたどると循環するプロファイルに SourceCycle を設定します。
+// 循環の一部であるプロファイルだけでなく、循環に行き着くプロファイルも対象になります。
+func markSourceCycles(profiles []awsProfile) {
+	byName := profilesByName(profiles)
+	for i, p := range profiles {
+		seen := map[string]bool{p.Name: true}
+		current := p
+		for {
+			source := current.RawKeys["source_profile"]
+			if source == "" || source == current.Name {
+				break
+			}
+			if seen[source] {
+				profiles[i].SourceCycle = true
+				break
+			}
+			next, ok := byName[source]
+			if !ok {
+				break
+			}
+			seen[source] = true
+			current = next
+		}
+	}
+}
+
+// cyclicProfileNames は SourceCycle が設定されたプロファイルの名前を返します。
+func cyclicProfileNames(profiles []awsProfile) []string {
+	var names []string
+	for _, p := range profiles {
+		if p.SourceCycle {
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
+
+// formatChain は継承チェーンを "a → b → c" の形式で返します。
+func formatChain(chain []awsProfile) string {
+	names := make([]string, len(chain))
+	for i, p := range chain {
+		names[i] = p.Name
+	}
+	return strings.Join(names, " → ")
+}
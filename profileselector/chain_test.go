@@ -0,0 +1,122 @@
+package profileselector
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// sourceProfile は source_profile に source を指定したプロファイルを作ります (source が空の場合は指定しません)。
+func sourceProfile(name, source string) awsProfile {
+	p := awsProfile{Name: name, RawKeys: map[string]string{}}
+	if source != "" {
+		p.RawKeys["source_profile"] = source
+	}
+	return p
+}
+
+func TestResolveChain(t *testing.T) {
+	tests := []struct {
+		name     string
+		profiles []awsProfile
+		start    string
+		want     []string
+		wantErr  bool
+	}{
+		{name: "source_profile なし", profiles: []awsProfile{sourceProfile("base", "")}, start: "base", want: []string{"base"}},
+		{
+			name:     "2 段のチェーン",
+			profiles: []awsProfile{sourceProfile("base", ""), sourceProfile("admin", "base")},
+			start:    "admin",
+			want:     []string{"base", "admin"},
+		},
+		{
+			name:     "3 段のチェーン",
+			profiles: []awsProfile{sourceProfile("base", ""), sourceProfile("admin", "base"), sourceProfile("deploy", "admin")},
+			start:    "deploy",
+			want:     []string{"base", "admin", "deploy"},
+		},
+		{name: "自分自身を参照", profiles: []awsProfile{sourceProfile("base", "base")}, start: "base", want: []string{"base"}},
+		{name: "参照先がない", profiles: []awsProfile{sourceProfile("admin", "missing")}, start: "admin", wantErr: true},
+		{
+			name:     "循環",
+			profiles: []awsProfile{sourceProfile("a", "b"), sourceProfile("b", "a")},
+			start:    "a",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			all := profilesByName(tt.profiles)
+			chain, err := all[tt.start].ResolveChain(all)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveChain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := profileNames(chain); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ResolveChain() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMarkSourceCycles(t *testing.T) {
+	tests := []struct {
+		name     string
+		profiles []awsProfile
+		want     []string
+	}{
+		{name: "循環なし", profiles: []awsProfile{sourceProfile("base", ""), sourceProfile("admin", "base")}},
+		{name: "自分自身の参照は循環ではない", profiles: []awsProfile{sourceProfile("base", "base")}},
+		{name: "参照先がない", profiles: []awsProfile{sourceProfile("admin", "missing")}},
+		{name: "2 つのプロファイルの循環", profiles: []awsProfile{sourceProfile("a", "b"), sourceProfile("b", "a")}, want: []string{"a", "b"}},
+		{
+			name:     "循環に行き着くプロファイルも対象",
+			profiles: []awsProfile{sourceProfile("a", "b"), sourceProfile("b", "c"), sourceProfile("c", "b"), sourceProfile("ok", "")},
+			want:     []string{"a", "b", "c"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			markSourceCycles(tt.profiles)
+			if got := cyclicProfileNames(tt.profiles); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("cyclicProfileNames() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadAWSProfilesWarnsSourceCycles(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	config := filepath.Join(dir, "config")
+	fixture := `[profile a]
+role_arn = arn:aws:iam::123456789012:role/A
+source_profile = b
+
+[profile b]
+role_arn = arn:aws:iam::123456789012:role/B
+source_profile = a
+
+[profile dev]
+region = ap-northeast-1
+`
+	if err := os.WriteFile(config, []byte(fixture), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	profiles, err := loadAWSProfiles(profileSources{configPaths: []string{config}, quiet: true})
+	if err != nil {
+		t.Fatalf("loadAWSProfiles() error = %v", err)
+	}
+	if got := cyclicProfileNames(profiles); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("cyclicProfileNames() = %v, want [a b]", got)
+	}
+
+	m := newTestModel(t, options{}, profiles)
+	if footer := renderFooter(m); !strings.Contains(footer, "source_profile が循環しています: a, b") {
+		t.Errorf("フッターに循環の警告がありません:\n%s", footer)
+	}
+}
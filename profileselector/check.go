@@ -0,0 +1,72 @@
+package profileselector
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// checkResult は --check でのプロファイル 1 件分の検査結果です。
+type checkResult struct {
+	Name   string   // プロファイル名
+	OK     bool     // 問題がなかったか
+	Issues []string // 見つかった問題の説明
+}
+
+// checkAllProfiles は全てのプロファイルについて、不明なキー、SSO のトークンの有効期限切れ、source_profile の循環や参照先の不在を検査します。
+// 結果は profiles と同じ順に返します。
+func checkAllProfiles(profiles []awsProfile) []checkResult {
+	byName := profilesByName(profiles)
+	results := make([]checkResult, 0, len(profiles))
+	for _, p := range profiles {
+		var issues []string
+		if unknown := validateProfileKeys(p); len(unknown) > 0 {
+			issues = append(issues, "不明なキー: "+strings.Join(unknown, ", "))
+		}
+		if p.SSOExpired {
+			issues = append(issues, "SSO のトークンの有効期限が切れています (aws sso login が必要です)")
+		}
+		if p.RawKeys["source_profile"] != "" {
+			if _, err := p.ResolveChain(byName); err != nil {
+				issues = append(issues, err.Error())
+			}
+		}
+		results = append(results, checkResult{Name: p.Name, OK: len(issues) == 0, Issues: issues})
+	}
+	return results
+}
+
+// writeCheckReport は検査結果を 1 行に 1 件ずつ ✓ または ✗ を付けて w に書き込み、最後に要約を書き込みます。
+// 問題のあったプロファイルの数を返します。
+func writeCheckReport(w io.Writer, results []checkResult) int {
+	failed := 0
+	for _, r := range results {
+		if r.OK {
+			fmt.Fprintf(w, "✓ %s\n", r.Name)
+			continue
+		}
+		failed++
+		fmt.Fprintf(w, "✗ %s: %s\n", r.Name, strings.Join(r.Issues, "; "))
+	}
+	if failed == 0 {
+		fmt.Fprintf(w, "\n全 %d 件のプロファイルに問題はありません。\n", len(results))
+	} else {
+		fmt.Fprintf(w, "\n%d 件中 %d 件のプロファイルに問題があります。\n", len(results), failed)
+	}
+	return failed
+}
+
+// runCheck は --check の指定時に全てのプロファイルを検査して結果を標準出力に書き込み、終了コードを返します。
+// 問題のあるプロファイルが 1 件でもあれば 1 を返します。
+func runCheck(opts options) int {
+	profiles, err := loadSortedProfiles(opts.sources(), opts.sort, loadHistoryOrEmpty())
+	if err != nil {
+		logErr(opts.quiet, "エラー: %s\n", describeLoadError(err))
+		return 1
+	}
+	if writeCheckReport(os.Stdout, checkAllProfiles(opts.filter.apply(profiles))) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,104 @@
+package profileselector
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckAllProfiles(t *testing.T) {
+	expired := awsProfile{Name: "sso", RawKeys: map[string]string{"sso_session": "corp"}, SSOExpired: true}
+	typo := awsProfile{Name: "typo", RawKeys: map[string]string{"regoin": "us-east-1"}}
+	tests := []struct {
+		name       string
+		profiles   []awsProfile
+		wantOK     map[string]bool
+		wantIssues map[string]string // 問題の説明に含まれるはずの文字列
+	}{
+		{
+			name:     "問題なし",
+			profiles: []awsProfile{sourceProfile("base", ""), sourceProfile("admin", "base")},
+			wantOK:   map[string]bool{"base": true, "admin": true},
+		},
+		{
+			name:       "不明なキー",
+			profiles:   []awsProfile{typo},
+			wantOK:     map[string]bool{"typo": false},
+			wantIssues: map[string]string{"typo": "regoin"},
+		},
+		{
+			name:       "SSO のトークンの有効期限切れ",
+			profiles:   []awsProfile{expired},
+			wantOK:     map[string]bool{"sso": false},
+			wantIssues: map[string]string{"sso": "aws sso login"},
+		},
+		{
+			name:     "source_profile の循環",
+			profiles: []awsProfile{sourceProfile("a", "b"), sourceProfile("b", "a"), sourceProfile("c", "")},
+			wantOK:   map[string]bool{"a": false, "b": false, "c": true},
+		},
+		{
+			name:     "source_profile の参照先がない",
+			profiles: []awsProfile{sourceProfile("admin", "missing")},
+			wantOK:   map[string]bool{"admin": false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := checkAllProfiles(tt.profiles)
+			if len(results) != len(tt.profiles) {
+				t.Fatalf("結果の件数 = %d, want %d", len(results), len(tt.profiles))
+			}
+			for i, r := range results {
+				if r.Name != tt.profiles[i].Name {
+					t.Errorf("%d 件目の名前 = %q, want %q", i, r.Name, tt.profiles[i].Name)
+				}
+				if r.OK != tt.wantOK[r.Name] {
+					t.Errorf("%s の OK = %v, want %v (問題: %v)", r.Name, r.OK, tt.wantOK[r.Name], r.Issues)
+				}
+				if r.OK != (len(r.Issues) == 0) {
+					t.Errorf("%s の OK = %v ですが問題が %d 件あります", r.Name, r.OK, len(r.Issues))
+				}
+				if want, ok := tt.wantIssues[r.Name]; ok && !strings.Contains(strings.Join(r.Issues, "; "), want) {
+					t.Errorf("%s の問題 = %v, want %q を含む", r.Name, r.Issues, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteCheckReport(t *testing.T) {
+	tests := []struct {
+		name       string
+		results    []checkResult
+		want       string
+		wantFailed int
+	}{
+		{
+			name:    "全て問題なし",
+			results: []checkResult{{Name: "dev", OK: true}, {Name: "prod", OK: true}},
+			want:    "✓ dev\n✓ prod\n\n全 2 件のプロファイルに問題はありません。\n",
+		},
+		{
+			name:       "問題あり",
+			results:    []checkResult{{Name: "dev", OK: true}, {Name: "typo", Issues: []string{"不明なキー: regoin", "循環"}}},
+			want:       "✓ dev\n✗ typo: 不明なキー: regoin; 循環\n\n2 件中 1 件のプロファイルに問題があります。\n",
+			wantFailed: 1,
+		},
+		{
+			name:    "プロファイルなし",
+			results: nil,
+			want:    "\n全 0 件のプロファイルに問題はありません。\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if failed := writeCheckReport(&b, tt.results); failed != tt.wantFailed {
+				t.Errorf("writeCheckReport() = %d, want %d", failed, tt.wantFailed)
+			}
+			if b.String() != tt.want {
+				t.Errorf("レポート = %q, want %q", b.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package profileselector
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// toastDuration はステータス行のトーストメッセージを表示しておく時間です。
+const toastDuration = 2 * time.Second
+
+// clipboardMsg はクリップボードへのコピーが完了したときに送られるメッセージです。
+type clipboardMsg struct {
+	err error // コピー時に発生したエラー
+}
+
+// clearToastMsg はトーストメッセージを消すときに送られるメッセージです。
+type clearToastMsg struct {
+	id int // 消す対象のトーストの ID (新しいトーストが表示されていれば消さない)
+}
+
+// clipboardCommand はクリップボードに書き込むための外部コマンドを返します。
+// 利用可能なコマンドが見つからない場合はエラーを返します。
+func clipboardCommand() (*exec.Cmd, error) {
+	candidates := [][]string{
+		{"xclip", "-selection", "clipboard"},
+		{"xsel", "--clipboard", "--input"},
+		{"clip.exe"},
+	}
+	switch {
+	case runtime.GOOS == "darwin":
+		candidates = append([][]string{{"pbcopy"}}, candidates...)
+	case os.Getenv("WAYLAND_DISPLAY") != "":
+		candidates = append([][]string{{"wl-copy"}}, candidates...)
+	}
+
+	for _, c := range candidates {
+		if path, err := exec.LookPath(c[0]); err == nil {
+			return exec.Command(path, c[1:]...), nil
+		}
+	}
+	return nil, errors.New("クリップボードにコピーするコマンド (pbcopy, wl-copy, xclip, xsel) が見つかりません")
+}
+
+// writeClipboard は s をクリップボードに書き込みます。
+func writeClipboard(s string) error {
+	cmd, err := clipboardCommand()
+	if err != nil {
+		return err
+	}
+	cmd.Stdin = strings.NewReader(s)
+	return cmd.Run()
+}
+
+// copyToClipboard は s をクリップボードに書き込み、結果を clipboardMsg として返すコマンドです。
+func copyToClipboard(s string) tea.Cmd {
+	return func() tea.Msg {
+		return clipboardMsg{err: writeClipboard(s)}
+	}
+}
+
+// clearToastCmd は toastDuration 経過後に id のトーストを消すコマンドです。
+func clearToastCmd(id int) tea.Cmd {
+	return tea.Tick(toastDuration, func(time.Time) tea.Msg {
+		return clearToastMsg{id: id}
+	})
+}
@@ -0,0 +1,116 @@
+package profileselector
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestClipboardToast(t *testing.T) {
+	tests := []struct {
+		name      string
+		msgs      []tea.Msg
+		wantToast string
+	}{
+		{name: "コピーに成功", msgs: []tea.Msg{clipboardMsg{}}, wantToast: "Copied!"},
+		{name: "コピーに失敗", msgs: []tea.Msg{clipboardMsg{err: errors.New("xclip がありません")}}, wantToast: "コピーに失敗しました: xclip がありません"},
+		{name: "時間が経つと消える", msgs: []tea.Msg{clipboardMsg{}, clearToastMsg{id: 1}}, wantToast: ""},
+		{name: "古いトーストの消去では新しいトーストを消さない", msgs: []tea.Msg{clipboardMsg{}, clipboardMsg{}, clearToastMsg{id: 1}}, wantToast: "Copied!"},
+		{name: "新しいトーストも時間が経つと消える", msgs: []tea.Msg{clipboardMsg{}, clipboardMsg{}, clearToastMsg{id: 1}, clearToastMsg{id: 2}}, wantToast: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var next tea.Model = newTestModel(t, options{}, testProfiles("dev"))
+			for _, msg := range tt.msgs {
+				var cmd tea.Cmd
+				next, cmd = next.Update(msg)
+				if _, ok := msg.(clipboardMsg); ok && cmd == nil {
+					t.Errorf("clipboardMsg の後にトーストを消すコマンドが返されませんでした")
+				}
+			}
+			if got := next.(model).toast; got != tt.wantToast {
+				t.Errorf("toast = %q, want %q", got, tt.wantToast)
+			}
+		})
+	}
+}
+
+func TestCopyKeyReturnsCommand(t *testing.T) {
+	m := newTestModel(t, options{}, testProfiles("dev"))
+	next, cmd := m.Update(keyPress("ctrl+y"))
+	if cmd == nil {
+		t.Fatal("ctrl+y でクリップボードにコピーするコマンドが返されませんでした")
+	}
+	if toast := next.(model).toast; toast != "" {
+		t.Errorf("コピーが終わる前に toast = %q が表示されました", toast)
+	}
+}
+
+func TestRunSelectClipboardOnly(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("クリップボードのコマンドの代わりにシェルスクリプトを使うため")
+	}
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv(credentialsFileEnv, filepath.Join(dir, "credentials"))
+	t.Setenv("WAYLAND_DISPLAY", "")
+	config := filepath.Join(dir, "config")
+	if err := os.WriteFile(config, []byte("[profile dev]\n[profile prod]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// xclip の代わりに、標準入力をファイルに書き込むスクリプトを置く
+	binDir := filepath.Join(dir, "bin")
+	clipboard := filepath.Join(dir, "clipboard")
+	if err := os.Mkdir(binDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	script := "#!/bin/sh\nexec /bin/cat > " + clipboard + "\n"
+	if err := os.WriteFile(filepath.Join(binDir, "xclip"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		path          string
+		args          []string
+		wantCode      int
+		wantClipboard string
+		wantStderr    string
+	}{
+		{name: "コピーして終了", path: binDir, args: []string{"--index", "1"}, wantClipboard: "prod", wantStderr: "'prod' をクリップボードにコピーしました。"},
+		{name: "コピーするコマンドがない", path: t.TempDir(), args: []string{"--index", "1"}, wantCode: 1, wantStderr: "コマンド (pbcopy, wl-copy, xclip, xsel) が見つかりません"},
+		{name: "--exec とは同時に指定できない", path: binDir, args: []string{"--index", "1", "--exec", "--", "true"}, wantCode: 2, wantStderr: "--clipboard-only と --exec は同時に指定できません"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Remove(clipboard)
+			t.Setenv("PATH", tt.path)
+			opts, err := parseOptionsTo(io.Discard, append([]string{"--config", config, "--clipboard-only"}, tt.args...))
+			if err != nil {
+				t.Fatalf("parseOptionsTo() error = %v", err)
+			}
+			var code int
+			stdout, stderr := captureOutput(t, func() { code = runSelect(opts) })
+			if code != tt.wantCode {
+				t.Errorf("終了コード = %d, want %d (stderr: %s)", code, tt.wantCode, stderr)
+			}
+			if stdout != "" {
+				t.Errorf("標準出力 = %q, want 空", stdout)
+			}
+			if !strings.Contains(stderr, tt.wantStderr) {
+				t.Errorf("標準エラー出力 = %q, want %q を含む", stderr, tt.wantStderr)
+			}
+			got, _ := os.ReadFile(clipboard)
+			if string(got) != tt.wantClipboard {
+				t.Errorf("クリップボード = %q, want %q", got, tt.wantClipboard)
+			}
+		})
+	}
+}
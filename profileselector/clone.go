@@ -0,0 +1,84 @@
+package profileselector
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// cloneProfileInConfig は configPath の設定ファイルで、srcName のプロファイルの全てのキーを dstName の新しいプロファイルに複製します。
+// dstName のプロファイルが既に存在する場合は、overwrite が true のときだけ既存のキーを置き換えます。
+func cloneProfileInConfig(configPath, srcName, dstName string, overwrite bool) error {
+	cfg, err := loadConfigForUpdate(configPath)
+	if err != nil {
+		return err
+	}
+	src := findProfileSection(cfg, srcName)
+	if src == nil {
+		return fmt.Errorf("複製元のプロファイル '%s' が見つかりません (ファイル: %s)", srcName, configPath)
+	}
+
+	dst := findProfileSection(cfg, dstName)
+	if dst != nil {
+		if !overwrite {
+			return fmt.Errorf("プロファイル '%s' は既に存在します。上書きするには --overwrite を指定してください (ファイル: %s)", dstName, configPath)
+		}
+		for _, key := range dst.KeyStrings() {
+			dst.DeleteKey(key)
+		}
+	} else {
+		dst, err = cfg.NewSection(profileSectionName(dstName))
+		if err != nil {
+			return fmt.Errorf("セクションの作成に失敗しました: %w (プロファイル: %s)", err, dstName)
+		}
+	}
+
+	if err := copySectionKeys(dst, src); err != nil {
+		return err
+	}
+	return saveConfig(cfg, configPath, configFileMode)
+}
+
+// runClone は clone サブコマンドを実行し、終了コードを返します。
+// プロファイルを複製した後、複製したプロファイルにカーソルを置いた状態でプロファイルの選択を始めます。
+// --source, --dest, --overwrite 以降の引数は、プロファイルの選択のオプションとして扱います。
+func runClone(args []string) int {
+	fs := flag.NewFlagSet("clone", flag.ContinueOnError)
+	source := fs.String("source", "", "複製元のプロファイル名")
+	dest := fs.String("dest", "", "複製先のプロファイル名")
+	overwrite := fs.Bool("overwrite", false, "複製先のプロファイルが既に存在する場合に上書きする")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *source == "" || *dest == "" {
+		fmt.Fprintln(os.Stderr, "エラー: --source と --dest を指定してください")
+		return 2
+	}
+	if err := validateProfileName(*dest); err != nil {
+		fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+		return 2
+	}
+
+	opts, err := parseOptions(fs.Args())
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	configFile, err := opts.sources().configPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+		return 1
+	}
+	if err := cloneProfileInConfig(configFile, *source, *dest, *overwrite); err != nil {
+		fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "プロファイル '%s' を '%s' として %s に複製しました。\n", *source, *dest, configFile)
+
+	opts.defaultProfile = *dest
+	return runSelect(opts)
+}
@@ -0,0 +1,87 @@
+package profileselector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCloneProfileInConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    string
+		src       string
+		dst       string
+		overwrite bool
+		want      string
+		wantErr   bool
+	}{
+		{
+			name:   "全てのキーを複製",
+			config: "[profile dev]\nregion = ap-northeast-1\nrole_arn = arn:aws:iam::123456789012:role/Dev\nsource_profile = base\n",
+			src:    "dev",
+			dst:    "dev-copy",
+			want: "[profile dev]\nregion = ap-northeast-1\nrole_arn = arn:aws:iam::123456789012:role/Dev\nsource_profile = base\n\n" +
+				"[profile dev-copy]\nregion = ap-northeast-1\nrole_arn = arn:aws:iam::123456789012:role/Dev\nsource_profile = base\n",
+		},
+		{
+			name:   "default から複製",
+			config: "[default]\nregion = us-east-1\n",
+			src:    "default",
+			dst:    "main",
+			want:   "[default]\nregion = us-east-1\n\n[profile main]\nregion = us-east-1\n",
+		},
+		{
+			name:    "複製先が既に存在する",
+			config:  "[profile dev]\nregion = ap-northeast-1\n\n[profile prod]\nregion = us-east-1\n",
+			src:     "dev",
+			dst:     "prod",
+			wantErr: true,
+		},
+		{
+			name:      "--overwrite で既存のキーを置き換える",
+			config:    "[profile dev]\nregion = ap-northeast-1\n\n[profile prod]\nregion = us-east-1\noutput = json\n",
+			src:       "dev",
+			dst:       "prod",
+			overwrite: true,
+			want:      "[profile dev]\nregion = ap-northeast-1\n\n[profile prod]\nregion = ap-northeast-1\n",
+		},
+		{
+			name:    "複製元が存在しない",
+			config:  "[profile dev]\n",
+			src:     "staging",
+			dst:     "stg",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config")
+			if err := os.WriteFile(path, []byte(tt.config), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			err := cloneProfileInConfig(path, tt.src, tt.dst, tt.overwrite)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("cloneProfileInConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			want := tt.want
+			if tt.wantErr {
+				want = tt.config
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != want {
+				t.Errorf("設定ファイル = %q, want %q", data, want)
+			}
+		})
+	}
+}
+
+func TestCursorOnClonedProfile(t *testing.T) {
+	m := newTestModel(t, options{defaultProfile: "dev-copy"}, testProfiles("dev", "dev-copy", "prod"))
+	if got := m.profiles[m.cursor].Name; got != "dev-copy" {
+		t.Errorf("カーソル位置のプロファイル = %q, want %q", got, "dev-copy")
+	}
+}
@@ -0,0 +1,47 @@
+package profileselector
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// defaultMultiColumns は --columns を指定せずに列表示切替キーを押したときの列数です。
+const defaultMultiColumns = 3
+
+// columnCount は現在の表示列数を返します (1 未満の場合は 1)。
+func (m model) columnCount() int {
+	if m.columns < 1 || m.compact {
+		return 1
+	}
+	return m.columns
+}
+
+// renderColumns はプロファイルを左から右、上から下の順に複数列で並べて描画します。
+// 複数列表示では説明や RoleARN は表示せず、プロファイル名と印だけを表示します。
+func (m model) renderColumns() string {
+	cols := m.columnCount()
+	cellWidth := m.innerWidth() / cols
+	if cellWidth < 2 {
+		cellWidth = 2
+	}
+	cellStyle := m.theme.style().Width(cellWidth)
+
+	var s strings.Builder
+	for row := m.scrollOffset; row < m.scrollOffset+m.listVisibleHeight; row++ {
+		start := row * cols
+		if start >= len(m.profiles) {
+			break
+		}
+
+		var cells []string
+		for i := start; i < start+cols && i < len(m.profiles); i++ {
+			p := m.profiles[i]
+			name := m.rowStyle(i, m.theme.style()).renderProfileName(p, i == m.cursor, m.isActive(p))
+			cells = append(cells, cellStyle.Render(ansi.Truncate(name, cellWidth-1, "…")))
+		}
+		s.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, cells...) + "\n")
+	}
+	return s.String()
+}
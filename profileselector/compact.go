@@ -0,0 +1,54 @@
+package profileselector
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// renderCompact は --compact の指定時の画面を描画します。
+// タイトル、タブバー、区切り線、フッターを表示せず、プロファイルを 1 行に 1 つずつ並べます。
+// ヘルプの表示中 (? キー) や入力欄の使用中は、一覧の下にフッターも表示します。
+func renderCompact(m model) string {
+	footer := ""
+	rows := m.listVisibleHeight
+	if m.compactFooterVisible() {
+		footer = renderFooter(m)
+		rows -= lipgloss.Height(footer)
+	}
+	rows = max(rows, 1)
+
+	var s strings.Builder
+	switch {
+	case m.diffState == diffShowing:
+		s.WriteString(m.renderDiff())
+	case len(m.profiles) == 0:
+		s.WriteString(m.theme.style().Italic(true).Render(fmt.Sprintf("%q に一致するプロファイルがありません。", m.searchQuery)) + "\n")
+	default:
+		// フッターの分だけ表示できる行が減っても、カーソル位置の行は表示する
+		start := m.scrollOffset
+		if m.cursor >= start+rows {
+			start = m.cursor - rows + 1
+		}
+		end := min(start+rows, len(m.profiles))
+		cursorStyle := m.theme.style().Bold(true).Foreground(m.cursorColor)
+		for i := start; i < end; i++ {
+			if i == m.cursor {
+				s.WriteString(cursorStyle.Render(m.cursorIndicator+m.profiles[i].DisplayName()) + "\n")
+			} else {
+				s.WriteString(m.cursorBlank() + m.profiles[i].DisplayName() + "\n")
+			}
+		}
+		if m.previewHeight > 0 {
+			s.WriteString(renderBottomPreview(m.profiles[m.cursor], m.windowWidth, m.previewHeight, m.theme))
+		}
+	}
+	s.WriteString(footer)
+	return s.String()
+}
+
+// compactFooterVisible は --compact の指定時に、一覧の下にフッターを表示するかを返します。
+func (m model) compactFooterVisible() bool {
+	return m.showHelp || m.searching || m.editingNote || m.renaming || m.enteringMFA || m.confirmingDelete || m.diffState != diffOff
+}
@@ -0,0 +1,55 @@
+package profileselector
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderCompact(t *testing.T) {
+	tests := []struct {
+		name       string
+		profiles   int
+		keys       []string
+		wantLines  int // 0 の場合はプロファイルの数
+		wantFooter bool
+	}{
+		{name: "1 件", profiles: 1},
+		{name: "ウィンドウに収まる", profiles: 5},
+		{name: "カーソルを移動", profiles: 5, keys: []string{"down", "down"}},
+		{name: "ウィンドウに収まらない", profiles: 100, wantLines: 30},
+		{name: "? でヘルプを表示", profiles: 5, keys: []string{"?"}, wantFooter: true},
+		{name: "ウィンドウに収まらない一覧でヘルプを表示", profiles: 100, keys: []string{"?"}, wantFooter: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t, options{compact: true}, syntheticProfiles(tt.profiles))
+			m = pressKeys(t, m, tt.keys...)
+			got := m.View()
+			if got != renderCompact(m) {
+				t.Error("--compact の View() が renderCompact() の結果ではありません")
+			}
+			if tt.wantFooter {
+				if !strings.Contains(got, "q/Ctrl+C:終了") {
+					t.Errorf("ヘルプが表示されていません:\n%s", got)
+				}
+				if n := strings.Count(got, "\n"); n > 30 {
+					t.Errorf("改行の数 = %d, ウィンドウの高さ 30 を超えています", n)
+				}
+				return
+			}
+			want := tt.wantLines
+			if want == 0 {
+				want = tt.profiles
+			}
+			if n := strings.Count(got, "\n"); n != want {
+				t.Errorf("改行の数 = %d, want %d:\n%s", n, want, got)
+			}
+			if !strings.Contains(got, defaultCursor+m.profiles[m.cursor].Name+"\n") {
+				t.Errorf("カーソル位置の行 %q がありません:\n%s", defaultCursor+m.profiles[m.cursor].Name, got)
+			}
+			if strings.Contains(got, "─") || strings.Contains(got, "AWSプロファイルを選択してください") {
+				t.Errorf("タイトルや区切り線が表示されています:\n%s", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package profileselector
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// completionFunctionName は bash と zsh の補完スクリプトで定義する関数の名前です。
+const completionFunctionName = "_aws_profile_selector"
+
+// profileValueFlags は値としてプロファイル名を補完するフラグです。
+var profileValueFlags = []string{"default", "profile-prefix"}
+
+// fileValueFlags は値としてファイルのパスを補完するフラグです。
+var fileValueFlags = []string{"config", "allow-list", "deny-list", "envrc-file"}
+
+// runList は --list の指定時に、絞り込んで並べ替えたプロファイル名を 1 行に 1 つずつ標準出力に書き込み、終了コードを返します。
+// 補完スクリプトはプロファイル名の候補をこの出力から取得します。
+func runList(opts options) int {
+	profiles, err := loadSortedProfiles(opts.sources(), opts.sort, loadHistoryOrEmpty())
+	if err != nil {
+		logErr(opts.quiet, "エラー: %s\n", describeLoadError(err))
+		return 1
+	}
+	for _, p := range opts.filter.apply(profiles) {
+		fmt.Println(p.Name)
+	}
+	return 0
+}
+
+// completionFlag は補完スクリプトで補完するフラグです。
+type completionFlag struct {
+	name       string   // フラグ名 (先頭の -- を除く)
+	usage      string   // フラグの説明
+	takesValue bool     // 値を取るか (真偽値のフラグでなければ true)
+	choices    []string // 値の候補 (候補が決まっていなければ nil)
+}
+
+// completionFlags はプロファイルの選択のオプションのフラグを名前順に返します。
+func completionFlags() []completionFlag {
+	var flags []completionFlag
+	newOptionFlagSet(&options{}).VisitAll(func(f *flag.Flag) {
+		boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool })
+		flags = append(flags, completionFlag{
+			name:       f.Name,
+			usage:      f.Usage,
+			takesValue: !ok || !boolFlag.IsBoolFlag(),
+			choices:    flagChoices(f.Name),
+		})
+	})
+	return flags
+}
+
+// flagChoices は値の候補が決まっているフラグの候補を返します。
+func flagChoices(name string) []string {
+	switch name {
+	case "sort":
+		return []string{string(sortAlpha), string(sortLastUsed), string(sortType), string(sortNone)}
+	case "shell":
+		return append(slices.Clone(supportedShells), direnvShell)
+	case "theme":
+		return []string{themeDark, themeLight, themeAuto}
+	case "print-init", "completion":
+		return supportedShells
+	}
+	return nil
+}
+
+// completionScript はフラグとプロファイル名を補完する shell の補完スクリプトを返します。
+// name は補完を登録するコマンド名、command はプロファイル名を --list で取得するときに実行する実行ファイルのパスです。
+// --print-init で定義するシェル関数 (awsp) にも同じ補完を登録します。
+func completionScript(shell, name, command string) string {
+	flags := completionFlags()
+	listCommand := quoteShellWord(shell, command) + " --list 2>/dev/null"
+	switch shell {
+	case "fish":
+		return fishCompletionScript(name, listCommand, flags)
+	case "zsh":
+		return zshCompletionScript(name, listCommand, flags)
+	}
+	return bashCompletionScript(name, listCommand, flags)
+}
+
+// bashCompletionScript は bash の補完スクリプトを返します。
+func bashCompletionScript(name, listCommand string, flags []completionFlag) string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "%s() {\n", completionFunctionName)
+	s.WriteString("  local cur=\"${COMP_WORDS[COMP_CWORD]}\" prev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	s.WriteString("  case \"$prev\" in\n")
+	fmt.Fprintf(&s, "    %s)\n      COMPREPLY=($(compgen -W \"$(%s)\" -- \"$cur\")); return ;;\n", flagPattern(profileValueFlags), listCommand)
+	fmt.Fprintf(&s, "    %s)\n      COMPREPLY=($(compgen -f -- \"$cur\")); return ;;\n", flagPattern(fileValueFlags))
+	for _, f := range flags {
+		if len(f.choices) > 0 {
+			fmt.Fprintf(&s, "    --%s)\n      COMPREPLY=($(compgen -W \"%s\" -- \"$cur\")); return ;;\n", f.name, strings.Join(f.choices, " "))
+		}
+	}
+	s.WriteString("  esac\n")
+	fmt.Fprintf(&s, "  COMPREPLY=($(compgen -W \"%s\" -- \"$cur\"))\n", strings.Join(flagWords(flags), " "))
+	s.WriteString("}\n")
+	fmt.Fprintf(&s, "complete -F %s %s %s\n", completionFunctionName, name, shellFunctionName)
+	return s.String()
+}
+
+// zshCompletionScript は zsh の補完スクリプトを返します。
+func zshCompletionScript(name, listCommand string, flags []completionFlag) string {
+	var s strings.Builder
+	fmt.Fprintf(&s, "#compdef %s %s\n", name, shellFunctionName)
+	fmt.Fprintf(&s, "%s() {\n", completionFunctionName)
+	s.WriteString("  case \"${words[CURRENT-1]}\" in\n")
+	fmt.Fprintf(&s, "    %s)\n      compadd -- ${(f)\"$(%s)\"}; return ;;\n", flagPattern(profileValueFlags), listCommand)
+	fmt.Fprintf(&s, "    %s)\n      _files; return ;;\n", flagPattern(fileValueFlags))
+	for _, f := range flags {
+		if len(f.choices) > 0 {
+			fmt.Fprintf(&s, "    --%s)\n      compadd -- %s; return ;;\n", f.name, strings.Join(f.choices, " "))
+		}
+	}
+	s.WriteString("  esac\n")
+	fmt.Fprintf(&s, "  compadd -- %s\n", strings.Join(flagWords(flags), " "))
+	s.WriteString("}\n")
+	fmt.Fprintf(&s, "compdef %s %s %s\n", completionFunctionName, name, shellFunctionName)
+	return s.String()
+}
+
+// fishCompletionScript は fish の補完スクリプトを返します。
+func fishCompletionScript(name, listCommand string, flags []completionFlag) string {
+	var s strings.Builder
+	for _, command := range []string{name, shellFunctionName} {
+		fmt.Fprintf(&s, "complete -c %s -f\n", command)
+		for _, f := range flags {
+			fmt.Fprintf(&s, "complete -c %s -l %s", command, f.name)
+			switch {
+			case slices.Contains(profileValueFlags, f.name):
+				fmt.Fprintf(&s, " -x -a %s", quoteShellWord("fish", "("+listCommand+")"))
+			case slices.Contains(fileValueFlags, f.name):
+				s.WriteString(" -r -F")
+			case len(f.choices) > 0:
+				fmt.Fprintf(&s, " -x -a %s", quoteShellWord("fish", strings.Join(f.choices, " ")))
+			case f.takesValue:
+				s.WriteString(" -x")
+			}
+			fmt.Fprintf(&s, " -d %s\n", quoteShellWord("fish", f.usage))
+		}
+	}
+	return s.String()
+}
+
+// flagPattern は case 文でフラグ名のいずれかに一致するパターンを返します。
+func flagPattern(names []string) string {
+	words := make([]string, len(names))
+	for i, name := range names {
+		words[i] = "--" + name
+	}
+	return strings.Join(words, "|")
+}
+
+// flagWords は補完の候補にするフラグ (--名前) を返します。
+func flagWords(flags []completionFlag) []string {
+	words := make([]string, len(flags))
+	for i, f := range flags {
+		words[i] = "--" + f.name
+	}
+	return words
+}
@@ -0,0 +1,150 @@
+package profileselector
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// configLoadOptions は設定ファイルを読み込むときのオプションです。
+// [services ...] セクションのサービスごとのサブセクションはインデントされたキーで記述されるため、複数行の値として読み込みます。
+// 書き込み用に読み込むときも同じオプションを使い、サブセクションを崩さずに書き戻します。
+var configLoadOptions = ini.LoadOptions{AllowPythonMultilineValues: true}
+
+// profileSectionName はプロファイル名に対応する ~/.aws/config のセクション名を返します。
+// default プロファイルは "default"、それ以外は "profile <名前>" になります。
+func profileSectionName(profileName string) string {
+	if profileName == "default" {
+		return "default"
+	}
+	return "profile " + profileName
+}
+
+// findProfileSection は設定ファイルからプロファイル名に対応するセクションを探します。
+// "profile <名前>" 形式と、プレフィックスなしの "<名前>" 形式の両方を対象にします。
+func findProfileSection(cfg *ini.File, profileName string) *ini.Section {
+	for _, name := range []string{profileSectionName(profileName), profileName} {
+		if section, err := cfg.GetSection(name); err == nil {
+			return section
+		}
+	}
+	return nil
+}
+
+// copySectionKeys は src のセクションの全てのキーを、値とコメントを含めて dst のセクションに追加します。
+func copySectionKeys(dst, src *ini.Section) error {
+	for _, key := range src.Keys() {
+		k, err := dst.NewKey(key.Name(), key.Value())
+		if err != nil {
+			return fmt.Errorf("キーの設定に失敗しました: %w (セクション: %s, キー: %s)", err, dst.Name(), key.Name())
+		}
+		k.Comment = key.Comment
+	}
+	return nil
+}
+
+// loadConfigForUpdate は書き込み用に設定ファイルを読み込みます。
+// ファイルが存在しない場合は空の設定を返します。
+func loadConfigForUpdate(configPath string) (*ini.File, error) {
+	if _, err := os.Stat(configPath); errors.Is(err, fs.ErrNotExist) {
+		return ini.Empty(), nil
+	}
+	cfg, err := ini.LoadSources(configLoadOptions, configPath)
+	if err != nil {
+		return nil, readFileError("設定ファイル", configPath, err)
+	}
+	return cfg, nil
+}
+
+// configFileMode は新しく作成する設定ファイルの権限です。
+const configFileMode = 0o644
+
+// credentialsFileMode は認証情報ファイルの権限です。秘密情報を含むため、所有者以外は読めないようにします。
+const credentialsFileMode = 0o600
+
+// saveConfig は設定をファイルに書き込みます。親ディレクトリが存在しない場合は作成します。
+// 新しく作成するファイルは最初から権限を perm にし、既存のファイルは内容を書き込む前に perm より広い権限を外します。
+// 認証情報が一時的にも他のユーザーから読める状態にならないよう、権限を変えてから書き込みます。
+func saveConfig(cfg *ini.File, configPath string, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(configPath), 0o755); err != nil {
+		return fmt.Errorf("設定ディレクトリの作成に失敗しました: %w", err)
+	}
+	f, err := os.OpenFile(configPath, os.O_WRONLY|os.O_CREATE, perm)
+	if err != nil {
+		return fmt.Errorf("設定ファイルの書き込みに失敗しました: %w (ファイル: %s)", err, configPath)
+	}
+	if err := writeConfigFile(f, formatConfig(cfg), perm); err != nil {
+		f.Close()
+		return fmt.Errorf("設定ファイルの書き込みに失敗しました: %w (ファイル: %s)", err, configPath)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("設定ファイルの書き込みに失敗しました: %w (ファイル: %s)", err, configPath)
+	}
+	return nil
+}
+
+// writeConfigFile は開いたファイル f の perm より広い権限を外してから、f の内容を data に置き換えます。
+func writeConfigFile(f *os.File, data []byte, perm os.FileMode) error {
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if mode := info.Mode().Perm(); mode&^perm != 0 {
+		if err := f.Chmod(mode & perm); err != nil {
+			return err
+		}
+	}
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
+
+// formatConfig は AWS CLI と同じく、"=" を揃えずに "key = value" 形式で cfg を書き出した内容を返します。
+// ini パッケージの書き出し形式はパッケージ全体の設定で決まるため、利用側のプログラムに影響しないようにここで書き出します。
+// [services ...] セクションのサブセクションなどの複数行の値は、インデントされた続きの行としてそのまま書き出します。
+func formatConfig(cfg *ini.File) []byte {
+	var buf bytes.Buffer
+	for _, section := range cfg.Sections() {
+		isDefault := section.Name() == ini.DefaultSection
+		if isDefault && len(section.Keys()) == 0 && section.Comment == "" {
+			continue
+		}
+		if buf.Len() > 0 {
+			buf.WriteString("\n")
+		}
+		writeConfigComment(&buf, section.Comment)
+		if !isDefault {
+			buf.WriteString("[" + section.Name() + "]\n")
+		}
+		for _, key := range section.Keys() {
+			writeConfigComment(&buf, key.Comment)
+			if value := key.Value(); strings.HasPrefix(value, "\n") {
+				buf.WriteString(key.Name() + " =" + value + "\n")
+			} else {
+				buf.WriteString(key.Name() + " = " + value + "\n")
+			}
+		}
+	}
+	return buf.Bytes()
+}
+
+// writeConfigComment はコメントの各行を、コメント記号がなければ "#" を付けて buf に書き出します。
+func writeConfigComment(buf *bytes.Buffer, comment string) {
+	if comment == "" {
+		return
+	}
+	for _, line := range strings.Split(comment, "\n") {
+		if !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, ";") {
+			line = "# " + line
+		}
+		buf.WriteString(line + "\n")
+	}
+}
@@ -0,0 +1,161 @@
+package profileselector
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/ini.v1"
+)
+
+func TestFormatConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   string
+	}{
+		{
+			name:   "key = value 形式に揃える",
+			config: "[profile dev]\nregion=us-east-1\noutput   =   json\n",
+			want:   "[profile dev]\nregion = us-east-1\noutput = json\n",
+		},
+		{
+			name:   "セクションの間に空行を入れる",
+			config: "[default]\nregion = us-east-1\n[profile dev]\n",
+			want:   "[default]\nregion = us-east-1\n\n[profile dev]\n",
+		},
+		{
+			name:   "コメントを残す",
+			config: "# 開発用\n[profile dev]\n; リージョン\nregion = us-east-1\n",
+			want:   "# 開発用\n[profile dev]\n; リージョン\nregion = us-east-1\n",
+		},
+		{
+			name:   "セクションの前のキー",
+			config: "region = us-east-1\n\n[profile dev]\n",
+			want:   "region = us-east-1\n\n[profile dev]\n",
+		},
+		{
+			name:   "空の設定",
+			config: "",
+			want:   "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := ini.Load([]byte(tt.config))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(formatConfig(cfg)); got != tt.want {
+				t.Errorf("formatConfig() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIniPackageSettingsUnchanged(t *testing.T) {
+	// ライブラリとして読み込んだプログラムの ini の書き出し形式を変えないよう、パッケージ全体の設定は既定値のままにする
+	if !ini.PrettyFormat || ini.PrettyEqual {
+		t.Errorf("ini の書き出し設定が既定値から変更されています: PrettyFormat = %v, PrettyEqual = %v", ini.PrettyFormat, ini.PrettyEqual)
+	}
+}
+
+func TestConfigUpdateKeepsServicesSection(t *testing.T) {
+	const config = "[profile local]\nservices = local-services\n\n" +
+		"[services local-services]\ns3 =\n  endpoint_url = http://localhost:4566\ndynamodb =\n  endpoint_url = http://localhost:8000\n\n" +
+		"[profile dev]\nregion = us-east-1\n"
+	wantEndpoints := map[string]string{"s3": "http://localhost:4566", "dynamodb": "http://localhost:8000"}
+	tests := []struct {
+		name   string
+		update func(path string) error
+	}{
+		{name: "削除", update: func(path string) error { return deleteProfileFromConfig(path, "dev") }},
+		{name: "名前の変更", update: func(path string) error { return renameProfileInConfig(path, "dev", "development") }},
+		{name: "複製", update: func(path string) error { return cloneProfileInConfig(path, "dev", "dev-copy", false) }},
+		{name: "追加", update: func(path string) error { return appendProfileToConfig(path, awsProfile{Name: "staging"}) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config")
+			if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := tt.update(path); err != nil {
+				t.Fatalf("更新に失敗しました: %v", err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := "[services local-services]\ns3 =\n  endpoint_url = http://localhost:4566\ndynamodb =\n  endpoint_url = http://localhost:8000\n"; !strings.Contains(string(data), want) {
+				t.Errorf("services セクションが書き換えられました:\n%s", data)
+			}
+			profiles, err := loadAWSProfilesConcurrent(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, p := range profiles {
+				if p.Name != "local" {
+					continue
+				}
+				if !p.CustomEndpoint || !reflect.DeepEqual(p.ServiceEndpoints, wantEndpoints) {
+					t.Errorf("local: CustomEndpoint = %v, ServiceEndpoints = %v, want true, %v", p.CustomEndpoint, p.ServiceEndpoints, wantEndpoints)
+				}
+				return
+			}
+			t.Errorf("local プロファイルが見つかりません: %v", profileNames(profiles))
+		})
+	}
+}
+
+func TestSaveConfigPermissions(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing os.FileMode // 0 の場合はファイルを作成しない
+		perm     os.FileMode
+		want     os.FileMode
+	}{
+		{name: "新しい認証情報ファイル", perm: credentialsFileMode, want: 0o600},
+		{name: "他のユーザーが読める認証情報ファイル", existing: 0o644, perm: credentialsFileMode, want: 0o600},
+		{name: "新しい設定ファイル", perm: configFileMode, want: 0o644},
+		{name: "所有者だけが読める設定ファイルはそのまま", existing: 0o600, perm: configFileMode, want: 0o600},
+		{name: "設定ファイルの権限はそのまま", existing: 0o644, perm: configFileMode, want: 0o644},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "aws", "credentials")
+			if tt.existing != 0 {
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte("[old]\naws_access_key_id = AKIAOLD\naws_secret_access_key = old-secret-that-is-long\n"), 0o600); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Chmod(path, tt.existing); err != nil {
+					t.Fatal(err)
+				}
+			}
+			cfg := ini.Empty()
+			cfg.Section("dev").Key("aws_access_key_id").SetValue("AKIANEW")
+			if err := saveConfig(cfg, path, tt.perm); err != nil {
+				t.Fatalf("saveConfig() error = %v", err)
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := info.Mode().Perm(); got != tt.want {
+				t.Errorf("権限 = %o, want %o", got, tt.want)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := "[dev]\naws_access_key_id = AKIANEW\n"; string(data) != want {
+				t.Errorf("ファイルの内容 = %q, want %q", data, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package profileselector
+
+import (
+	"maps"
+	"strings"
+)
+
+// profileSources はプロファイルの読み込み元です。
+// コマンドのオプションや ProfileSelector の設定ごとに持ち、環境変数などのプロセス全体の状態は変更しません。
+type profileSources struct {
+	configPaths []string // --config で指定された設定ファイルのパス (後のものほど優先, 空の場合は環境変数 AWS_CONFIG_FILE または ~/.aws/config)
+	stdin       bool     // パイプで渡された標準入力を設定ファイルとして読み込んでよいか
+	ssmPrefix   string   // --ssm-prefix で指定された SSM パラメータストアのパス (空の場合は読み込まない)
+	quiet       bool     // 読み飛ばした SSM パラメータなどの警告を標準エラー出力に書き込まない
+}
+
+// splitConfigPaths は --config の値をカンマで区切った設定ファイルのパスを返します。空の要素は無視します。
+func splitConfigPaths(s string) []string {
+	var paths []string
+	for _, path := range strings.Split(s, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// configPath は読み込みと書き込みの対象にする設定ファイルのパスを返します。
+// --config が指定された場合は最後に指定したパスを、指定されていない場合は resolveConfigPath のパスを返します。
+// 編集や削除などの書き込みもこのファイルに対して行います。
+func (s profileSources) configPath() (string, error) {
+	if len(s.configPaths) == 0 {
+		return resolveConfigPath()
+	}
+	return expandHome(s.configPaths[len(s.configPaths)-1])
+}
+
+// credentialsPath は読み込みと書き込みの対象にする認証情報ファイルのパスを返します。
+// MFA で取得した一時的な認証情報もこのファイルに保存し、一覧に表示しているファイルと保存先を揃えます。
+func (s profileSources) credentialsPath() (string, error) {
+	return resolveCredentialsPath()
+}
+
+// sourcePath はプロファイルの読み込み元ファイルのパスを返します。
+func (s profileSources) sourcePath(source profileSource) (string, error) {
+	if source == sourceCredentials {
+		return s.credentialsPath()
+	}
+	return s.configPath()
+}
+
+// loadBaseConfigProfiles は --config に複数のパスが指定された場合に、最後のもの以外の設定ファイルを順に読み込み、
+// 後のファイルほど優先してマージしたプロファイルを返します。これらのプロファイルを configPath の設定ファイルの同じ名前のプロファイルで上書きします。
+func (s profileSources) loadBaseConfigProfiles() ([]awsProfile, error) {
+	var profiles []awsProfile
+	for _, path := range s.configPaths[:max(len(s.configPaths)-1, 0)] {
+		name, err := expandHome(path)
+		if err != nil {
+			return nil, err
+		}
+		layer, err := loadAWSProfilesConcurrent(name)
+		if err != nil {
+			return nil, err
+		}
+		profiles = mergeConfigProfiles(profiles, layer)
+	}
+	return profiles, nil
+}
+
+// mergeConfigProfiles は base のプロファイルに overlay のプロファイルを重ねます。
+// 同じ名前のプロファイルはキーごとに overlay の値で上書きし、一覧での位置は base の位置のままにします。
+// overlay にだけあるプロファイルは末尾に追加します。
+func mergeConfigProfiles(base, overlay []awsProfile) []awsProfile {
+	merged := make([]awsProfile, len(base), len(base)+len(overlay))
+	copy(merged, base)
+	index := make(map[string]int, len(base))
+	for i, p := range base {
+		index[p.Name] = i
+	}
+	for _, o := range overlay {
+		i, ok := index[o.Name]
+		if !ok {
+			index[o.Name] = len(merged)
+			merged = append(merged, o)
+			continue
+		}
+		b := merged[i]
+		keys := maps.Clone(b.RawKeys)
+		if keys == nil {
+			keys = map[string]string{}
+		}
+		maps.Copy(keys, o.RawKeys)
+		p := newAWSProfile(o.Name, keys, o.Source)
+		// services セクションは定義したファイルでしか解決できないため、overlay で解決できなければ base の結果を使う
+		p.ServiceEndpoints = o.ServiceEndpoints
+		if p.ServiceEndpoints == nil {
+			p.ServiceEndpoints = b.ServiceEndpoints
+		}
+		p.CustomEndpoint = keys["endpoint_url"] != "" || len(p.ServiceEndpoints) > 0
+		merged[i] = p
+	}
+	return merged
+}
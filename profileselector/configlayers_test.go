@@ -0,0 +1,131 @@
+package profileselector
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseOptionsConfigPaths(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "指定なし", want: nil},
+		{name: "1 つ", args: []string{"--config", "a"}, want: []string{"a"}},
+		{name: "カンマ区切り", args: []string{"--config", "a, b,,c"}, want: []string{"a", "b", "c"}},
+		{name: "繰り返し", args: []string{"--config", "a", "--config", "b,c"}, want: []string{"a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseOptionsTo(io.Discard, tt.args)
+			if err != nil {
+				t.Fatalf("parseOptionsTo() error = %v", err)
+			}
+			if !reflect.DeepEqual(opts.configPaths, tt.want) {
+				t.Errorf("configPaths = %v, want %v", opts.configPaths, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadAWSProfilesLayeredConfig(t *testing.T) {
+	const team = `[profile dev]
+region = us-east-1
+role_arn = arn:aws:iam::111111111111:role/Team
+output = json
+
+[profile shared]
+region = us-west-2
+`
+	const personal = `[profile dev]
+region = ap-northeast-1
+role_arn = arn:aws:iam::111111111111:role/Personal
+
+[profile mine]
+region = eu-west-1
+`
+	const extra = `[profile dev]
+region = eu-central-1
+`
+	tests := []struct {
+		name        string
+		layers      []string
+		wantNames   []string
+		wantRegion  map[string]string
+		wantRoleArn map[string]string
+		wantOutput  map[string]string
+	}{
+		{
+			name:        "1 つだけ",
+			layers:      []string{team},
+			wantNames:   []string{"dev", "shared"},
+			wantRegion:  map[string]string{"dev": "us-east-1"},
+			wantRoleArn: map[string]string{"dev": "arn:aws:iam::111111111111:role/Team"},
+		},
+		{
+			name:        "後のファイルが region と role_arn を上書き",
+			layers:      []string{team, personal},
+			wantNames:   []string{"dev", "shared", "mine"},
+			wantRegion:  map[string]string{"dev": "ap-northeast-1", "shared": "us-west-2", "mine": "eu-west-1"},
+			wantRoleArn: map[string]string{"dev": "arn:aws:iam::111111111111:role/Personal"},
+			wantOutput:  map[string]string{"dev": "json"},
+		},
+		{
+			name:        "順番を逆にすると前のファイルが負ける",
+			layers:      []string{personal, team},
+			wantNames:   []string{"dev", "mine", "shared"},
+			wantRegion:  map[string]string{"dev": "us-east-1"},
+			wantRoleArn: map[string]string{"dev": "arn:aws:iam::111111111111:role/Team"},
+		},
+		{
+			name:        "3 つ重ねると最後のファイルが最優先",
+			layers:      []string{team, personal, extra},
+			wantNames:   []string{"dev", "shared", "mine"},
+			wantRegion:  map[string]string{"dev": "eu-central-1"},
+			wantRoleArn: map[string]string{"dev": "arn:aws:iam::111111111111:role/Personal"},
+			wantOutput:  map[string]string{"dev": "json"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Setenv(credentialsFileEnv, filepath.Join(dir, "credentials"))
+			var paths []string
+			for i, content := range tt.layers {
+				path := filepath.Join(dir, fmt.Sprintf("config%d", i))
+				if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				paths = append(paths, path)
+			}
+			profiles, err := loadAWSProfiles(profileSources{configPaths: paths, quiet: true})
+			if err != nil {
+				t.Fatalf("loadAWSProfiles() error = %v", err)
+			}
+			if got := profileNames(profiles); !reflect.DeepEqual(got, tt.wantNames) {
+				t.Errorf("プロファイル名 = %v, want %v", got, tt.wantNames)
+			}
+			byName := profilesByName(profiles)
+			for name, want := range tt.wantRegion {
+				if got := byName[name].Region; got != want {
+					t.Errorf("%s の region = %q, want %q", name, got, want)
+				}
+			}
+			for name, want := range tt.wantRoleArn {
+				if got := byName[name].RoleArn; got != want {
+					t.Errorf("%s の role_arn = %q, want %q", name, got, want)
+				}
+			}
+			for name, want := range tt.wantOutput {
+				if got := byName[name].Output; got != want {
+					t.Errorf("%s の output = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
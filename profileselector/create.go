@@ -0,0 +1,376 @@
+package profileselector
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// createField は create サブコマンドのウィザードで入力する設定ファイルのキーです。
+type createField struct {
+	key         string // 設定ファイルのキー
+	label       string // 入力欄に表示する説明
+	required    bool   // 入力が必須か
+	secret      bool   // 入力内容を伏せて表示するか
+	credentials bool   // 設定ファイルではなく認証情報ファイルに書き込むか
+}
+
+// createTypes はウィザードで選択できる認証情報の種類です。
+var createTypes = []credentialType{credentialIAM, credentialSSO, credentialRole}
+
+// createTypeLabels は認証情報の種類の選択肢に表示する説明です。
+var createTypeLabels = map[credentialType]string{
+	credentialIAM:  "IAM (アクセスキー)",
+	credentialSSO:  "SSO (IAM Identity Center)",
+	credentialRole: "AssumeRole (ロールの引き受け)",
+}
+
+// createFields は認証情報の種類ごとにウィザードで入力するキーです。
+var createFields = map[credentialType][]createField{
+	credentialIAM: {
+		{key: "aws_access_key_id", label: "アクセスキー ID", required: true, credentials: true},
+		{key: "aws_secret_access_key", label: "シークレットアクセスキー", required: true, secret: true, credentials: true},
+		{key: "region", label: "リージョン (省略可)"},
+	},
+	credentialSSO: {
+		{key: "sso_start_url", label: "SSO のスタート URL", required: true},
+		{key: "sso_region", label: "SSO のリージョン", required: true},
+		{key: "sso_account_id", label: "アカウント ID", required: true},
+		{key: "sso_role_name", label: "ロール名", required: true},
+		{key: "region", label: "リージョン (省略可)"},
+	},
+	credentialRole: {
+		{key: "role_arn", label: "ロールの ARN", required: true},
+		{key: "source_profile", label: "引き受け元のプロファイル", required: true},
+		{key: "mfa_serial", label: "MFA デバイスの ARN (省略可)"},
+		{key: "region", label: "リージョン (省略可)"},
+	},
+}
+
+// createStep はウィザードの入力段階です。
+type createStep int
+
+const (
+	createStepName   createStep = iota // プロファイル名の入力
+	createStepType                     // 認証情報の種類の選択
+	createStepFields                   // 種類ごとのキーの入力
+)
+
+// createModel は create サブコマンドのウィザードのモデルです。
+type createModel struct {
+	configPath string            // プロファイルを追加する設定ファイルのパス
+	step       createStep        // 現在の入力段階
+	input      textinput.Model   // プロファイル名とキーの入力欄
+	name       string            // 入力されたプロファイル名
+	typeCursor int               // 認証情報の種類の選択肢のカーソル位置
+	fieldIndex int               // 入力中のキーの createFields 内のインデックス
+	values     map[string]string // 入力されたキーと値
+	message    string            // 入力内容の誤りなどを伝えるメッセージ
+	done       bool              // 全ての入力が完了したか
+	cancelled  bool              // 入力が中断されたか
+}
+
+// newCreateModel は configPath の設定ファイルにプロファイルを追加するウィザードのモデルを生成します。
+func newCreateModel(configPath string) createModel {
+	input := textinput.New()
+	input.Prompt = "> "
+	input.Placeholder = "プロファイル名"
+	input.Width = 60
+	input.Focus()
+	return createModel{
+		configPath: configPath,
+		input:      input,
+		values:     make(map[string]string),
+	}
+}
+
+// selectedType はウィザードで選択された認証情報の種類を返します。
+func (m createModel) selectedType() credentialType {
+	return createTypes[m.typeCursor]
+}
+
+func (m createModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+func (m createModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		var cmd tea.Cmd
+		m.input, cmd = m.input.Update(msg)
+		return m, cmd
+	}
+
+	switch keyMsg.String() {
+	case "ctrl+c", "esc":
+		m.cancelled = true
+		return m, tea.Quit
+	}
+
+	switch m.step {
+	case createStepName:
+		if keyMsg.String() == "enter" {
+			return m.submitName()
+		}
+	case createStepType:
+		switch keyMsg.String() {
+		case "up", "k":
+			if m.typeCursor > 0 {
+				m.typeCursor--
+			}
+		case "down", "j":
+			if m.typeCursor < len(createTypes)-1 {
+				m.typeCursor++
+			}
+		case "enter":
+			m.step = createStepFields
+			m.fieldIndex = 0
+			m = m.prepareField()
+		}
+		return m, nil
+	case createStepFields:
+		if keyMsg.String() == "enter" {
+			return m.submitField()
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// submitName は入力されたプロファイル名を確認し、認証情報の種類の選択に進みます。
+func (m createModel) submitName() (tea.Model, tea.Cmd) {
+	name := strings.TrimSpace(m.input.Value())
+	if err := validateProfileName(name); err != nil {
+		m.message = err.Error()
+		return m, nil
+	}
+
+	cfg, err := loadConfigForUpdate(m.configPath)
+	if err != nil {
+		m.message = err.Error()
+		return m, nil
+	}
+	if findProfileSection(cfg, name) != nil {
+		m.message = fmt.Sprintf("プロファイル '%s' は既に存在します", name)
+		return m, nil
+	}
+
+	m.name = name
+	m.message = ""
+	m.step = createStepType
+	m.input.Blur()
+	return m, nil
+}
+
+// prepareField は入力欄を fieldIndex 番目のキーの入力用に切り替えます。
+func (m createModel) prepareField() createModel {
+	field := createFields[m.selectedType()][m.fieldIndex]
+	m.input.Reset()
+	m.input.Placeholder = field.key
+	m.input.EchoMode = textinput.EchoNormal
+	if field.secret {
+		m.input.EchoMode = textinput.EchoPassword
+	}
+	m.input.Focus()
+	return m
+}
+
+// submitField は入力されたキーの値を確認し、次のキーの入力に進みます。最後のキーの場合はウィザードを終了します。
+func (m createModel) submitField() (tea.Model, tea.Cmd) {
+	fields := createFields[m.selectedType()]
+	field := fields[m.fieldIndex]
+	value := strings.TrimSpace(m.input.Value())
+	if value == "" && field.required {
+		m.message = field.label + "を入力してください"
+		return m, nil
+	}
+	if value != "" {
+		m.values[field.key] = value
+	}
+	m.message = ""
+
+	m.fieldIndex++
+	if m.fieldIndex >= len(fields) {
+		m.done = true
+		return m, tea.Quit
+	}
+	return m.prepareField(), nil
+}
+
+func (m createModel) View() string {
+	if m.done || m.cancelled {
+		return ""
+	}
+
+	titleStyle := lipgloss.NewStyle().Bold(true)
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("AWS プロファイルの作成") + "\n\n")
+
+	switch m.step {
+	case createStepName:
+		b.WriteString("プロファイル名:\n" + m.input.View() + "\n")
+	case createStepType:
+		b.WriteString(fmt.Sprintf("プロファイル名: %s\n\n認証情報の種類:\n", m.name))
+		for i, t := range createTypes {
+			indicator := cursorIndicatorFromEnv()
+			cursor := strings.Repeat(" ", lipgloss.Width(indicator))
+			if i == m.typeCursor {
+				cursor = lipgloss.NewStyle().Foreground(cursorColorFromEnv(lipgloss.Color(defaultCursorColor))).Render(indicator)
+			}
+			b.WriteString(cursor + createTypeLabels[t] + "\n")
+		}
+	case createStepFields:
+		fields := createFields[m.selectedType()]
+		b.WriteString(fmt.Sprintf("プロファイル名: %s (%s)\n\n", m.name, m.selectedType()))
+		b.WriteString(fmt.Sprintf("%s (%d/%d):\n", fields[m.fieldIndex].label, m.fieldIndex+1, len(fields)))
+		b.WriteString(m.input.View() + "\n")
+	}
+
+	if m.message != "" {
+		b.WriteString("\n" + lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Render(m.message) + "\n")
+	}
+	help := "Enter:決定, Esc/Ctrl+C:中止"
+	if m.step == createStepType {
+		help = "↑/k:上, ↓/j:下, " + help
+	}
+	b.WriteString("\n" + lipgloss.NewStyle().Faint(true).Render(help))
+	return b.String()
+}
+
+// splitCreateValues はウィザードで入力されたキーと値を、設定ファイルに書き込むものと認証情報ファイルに書き込むものに分けます。
+func splitCreateValues(t credentialType, values map[string]string) (configValues, credentialValues map[string]string) {
+	configValues = map[string]string{}
+	credentialValues = map[string]string{}
+	for _, field := range createFields[t] {
+		value, ok := values[field.key]
+		if !ok {
+			continue
+		}
+		if field.credentials {
+			credentialValues[field.key] = value
+		} else {
+			configValues[field.key] = value
+		}
+	}
+	return configValues, credentialValues
+}
+
+// appendProfileToCredentials は credentialsPath の認証情報ファイルに name のプロファイルのセクションを追加します。
+// キーは keys の内容を名前順に書き込み、ファイルは所有者以外は読めないようにします。
+// 同じ名前のプロファイルが既に存在する場合はエラーを返します。
+func appendProfileToCredentials(credentialsPath, name string, keys map[string]string) error {
+	cfg, err := loadConfigForUpdate(credentialsPath)
+	if err != nil {
+		return err
+	}
+	if _, err := cfg.GetSection(name); err == nil {
+		return fmt.Errorf("プロファイル '%s' は既に存在します (ファイル: %s)", name, credentialsPath)
+	}
+
+	section, err := cfg.NewSection(name)
+	if err != nil {
+		return fmt.Errorf("セクションの作成に失敗しました: %w (プロファイル: %s)", err, name)
+	}
+	keyNames := make([]string, 0, len(keys))
+	for key := range keys {
+		keyNames = append(keyNames, key)
+	}
+	sort.Strings(keyNames)
+	for _, key := range keyNames {
+		if _, err := section.NewKey(key, keys[key]); err != nil {
+			return fmt.Errorf("キーの設定に失敗しました: %w (プロファイル: %s, キー: %s)", err, name, key)
+		}
+	}
+	return saveConfig(cfg, credentialsPath, credentialsFileMode)
+}
+
+// appendProfileToConfig は configPath の設定ファイルにプロファイル p のセクションを追加します。
+// キーは p.RawKeys の内容を名前順に書き込みます。同じ名前のプロファイルが既に存在する場合はエラーを返します。
+func appendProfileToConfig(configPath string, p awsProfile) error {
+	cfg, err := loadConfigForUpdate(configPath)
+	if err != nil {
+		return err
+	}
+	if findProfileSection(cfg, p.Name) != nil {
+		return fmt.Errorf("プロファイル '%s' は既に存在します (ファイル: %s)", p.Name, configPath)
+	}
+
+	section, err := cfg.NewSection(profileSectionName(p.Name))
+	if err != nil {
+		return fmt.Errorf("セクションの作成に失敗しました: %w (プロファイル: %s)", err, p.Name)
+	}
+	keyNames := make([]string, 0, len(p.RawKeys))
+	for key := range p.RawKeys {
+		keyNames = append(keyNames, key)
+	}
+	sort.Strings(keyNames)
+	for _, key := range keyNames {
+		if _, err := section.NewKey(key, p.RawKeys[key]); err != nil {
+			return fmt.Errorf("キーの設定に失敗しました: %w (プロファイル: %s, キー: %s)", err, p.Name, key)
+		}
+	}
+	return saveConfig(cfg, configPath, configFileMode)
+}
+
+// runCreate は create サブコマンドを実行し、終了コードを返します。
+func runCreate(args []string) int {
+	fs := flag.NewFlagSet("create", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	configFile, err := resolveConfigPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+		return 1
+	}
+
+	finalModel, err := tea.NewProgram(newCreateModel(configFile), tea.WithOutput(os.Stderr)).Run()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "CLIアプリケーションの実行に失敗しました: %v\n", err)
+		return 1
+	}
+	m, ok := finalModel.(createModel)
+	if !ok {
+		fmt.Fprintln(os.Stderr, "モデルの型変換中に予期せぬエラーが発生しました。")
+		return 1
+	}
+	if !m.done {
+		fmt.Fprintln(os.Stderr, "プロファイルの作成がキャンセルされました。")
+		return 1
+	}
+
+	// アクセスキーは認証情報ファイルに、リージョンなどの設定は設定ファイルに書き込む
+	configValues, credentialValues := splitCreateValues(m.selectedType(), m.values)
+	if len(credentialValues) > 0 {
+		credentialsFile, err := resolveCredentialsPath()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+			return 1
+		}
+		if err := appendProfileToCredentials(credentialsFile, m.name, credentialValues); err != nil {
+			fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, "プロファイル '%s' の認証情報を %s に追加しました。\n", m.name, credentialsFile)
+		if len(configValues) == 0 {
+			return 0
+		}
+	}
+
+	p := newAWSProfile(m.name, configValues, sourceConfig)
+	if err := appendProfileToConfig(configFile, p); err != nil {
+		fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "プロファイル '%s' を %s に追加しました。\n", m.name, configFile)
+	return 0
+}
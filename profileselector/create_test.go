@@ -0,0 +1,85 @@
+package profileselector
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitCreateValues(t *testing.T) {
+	tests := []struct {
+		name            string
+		credentialType  credentialType
+		values          map[string]string
+		wantConfig      map[string]string
+		wantCredentials map[string]string
+	}{
+		{
+			name:           "IAM のアクセスキーは認証情報ファイルに書き込む",
+			credentialType: credentialIAM,
+			values: map[string]string{
+				"aws_access_key_id":     "AKIAEXAMPLE",
+				"aws_secret_access_key": "secret",
+				"region":                "ap-northeast-1",
+			},
+			wantConfig: map[string]string{"region": "ap-northeast-1"},
+			wantCredentials: map[string]string{
+				"aws_access_key_id":     "AKIAEXAMPLE",
+				"aws_secret_access_key": "secret",
+			},
+		},
+		{
+			name:           "AssumeRole は全て設定ファイルに書き込む",
+			credentialType: credentialRole,
+			values: map[string]string{
+				"role_arn":       "arn:aws:iam::123456789012:role/Dev",
+				"source_profile": "default",
+			},
+			wantConfig: map[string]string{
+				"role_arn":       "arn:aws:iam::123456789012:role/Dev",
+				"source_profile": "default",
+			},
+			wantCredentials: map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotConfig, gotCredentials := splitCreateValues(tt.credentialType, tt.values)
+			if !reflect.DeepEqual(gotConfig, tt.wantConfig) {
+				t.Errorf("config = %v, want %v", gotConfig, tt.wantConfig)
+			}
+			if !reflect.DeepEqual(gotCredentials, tt.wantCredentials) {
+				t.Errorf("credentials = %v, want %v", gotCredentials, tt.wantCredentials)
+			}
+		})
+	}
+}
+
+func TestAppendProfileToCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials")
+	keys := map[string]string{"aws_access_key_id": "AKIAEXAMPLE", "aws_secret_access_key": "secret"}
+	if err := appendProfileToCredentials(path, "dev", keys); err != nil {
+		t.Fatalf("appendProfileToCredentials() error = %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("権限 = %o, want 600", perm)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "[dev]\naws_access_key_id = AKIAEXAMPLE\naws_secret_access_key = secret\n"; !strings.Contains(string(data), want) {
+		t.Errorf("認証情報ファイル = %q, want %q を含む", data, want)
+	}
+
+	if err := appendProfileToCredentials(path, "dev", keys); err == nil {
+		t.Error("同じ名前のプロファイルを追加してもエラーになりません")
+	}
+}
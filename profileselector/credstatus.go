@@ -0,0 +1,142 @@
+package profileselector
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// credentialStatus はキャッシュされた認証情報の状態です。
+type credentialStatus int
+
+const (
+	credentialUnknown credentialStatus = iota // キャッシュが見つからない、または判定できない
+	credentialValid                           // 有効期限内
+	credentialExpired                         // 有効期限切れ
+)
+
+// credentialExpiredTag はキャッシュされた認証情報の有効期限が切れたプロファイルに付けるタグです。
+const credentialExpiredTag = "[expired]"
+
+// credentialValidTag はキャッシュされた認証情報が有効なプロファイルに付ける印です。
+const credentialValidTag = "●"
+
+// roleCachedCredentials は AWS CLI が ~/.aws/cli/cache に保存する AssumeRole の認証情報のファイルの内容です。
+type roleCachedCredentials struct {
+	Credentials struct {
+		Expiration string `json:"Expiration"`
+	} `json:"Credentials"`
+}
+
+// cliCacheDir は AWS CLI が AssumeRole の認証情報を保存するディレクトリ (~/.aws/cli/cache) を返します。
+func cliCacheDir() (string, error) {
+	home, err := userHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".aws", "cli", "cache"), nil
+}
+
+// roleCacheKey は AWS CLI が AssumeRole の認証情報のキャッシュファイル名に使うキーを返します。
+// AWS CLI と同じく、AssumeRole の引数 (RoleSessionName を除く) をキーの順に並べた JSON の SHA-1 です。
+func roleCacheKey(p awsProfile) string {
+	args := map[string]any{"RoleArn": p.RoleArn}
+	if v := p.RawKeys["external_id"]; v != "" {
+		args["ExternalId"] = v
+	}
+	if v := p.RawKeys["mfa_serial"]; v != "" {
+		args["SerialNumber"] = v
+	}
+	if v := p.RawKeys["duration_seconds"]; v != "" {
+		args["DurationSeconds"] = json.Number(v)
+	}
+	// Python の json.dumps(sort_keys=True) と同じ区切り文字で並べる
+	data, _ := json.Marshal(args)
+	sum := sha1.Sum(pythonJSONSeparators(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// pythonJSONSeparators は Go の JSON の区切り文字 ("," と ":") を Python の json.dumps の既定 (", " と ": ") に置き換えます。
+// 文字列の中の区切り文字は置き換えません。
+func pythonJSONSeparators(data []byte) []byte {
+	var out []byte
+	inString, escaped := false, false
+	for _, c := range data {
+		out = append(out, c)
+		switch {
+		case escaped:
+			escaped = false
+		case inString && c == '\\':
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case !inString && (c == ',' || c == ':'):
+			out = append(out, ' ')
+		}
+	}
+	return out
+}
+
+// roleCredentialsExpiry は cacheDir に保存されたプロファイルの AssumeRole の認証情報の有効期限を返します。
+// role_arn のないプロファイルや、キャッシュが見つからない場合は false を返します。
+func roleCredentialsExpiry(p awsProfile, cacheDir string) (time.Time, bool) {
+	if p.RoleArn == "" {
+		return time.Time{}, false
+	}
+	data, err := os.ReadFile(filepath.Join(cacheDir, roleCacheKey(p)+".json"))
+	if err != nil {
+		return time.Time{}, false
+	}
+	var cached roleCachedCredentials
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return time.Time{}, false
+	}
+	expiresAt, err := time.Parse(time.RFC3339, cached.Credentials.Expiration)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return expiresAt, true
+}
+
+// resolveCredentialStatus はプロファイルのキャッシュされた認証情報の状態を返します。
+// ロールのプロファイルは AssumeRole のキャッシュ、SSO のプロファイルは SSO のトークンの有効期限で判定します。
+func resolveCredentialStatus(p awsProfile, ssoDir, cliDir string, now time.Time) credentialStatus {
+	var expiresAt time.Time
+	var ok bool
+	switch p.CredentialType() {
+	case credentialRole:
+		expiresAt, ok = roleCredentialsExpiry(p, cliDir)
+	case credentialSSO:
+		expiresAt, ok = ssoTokenExpiry(p, ssoDir)
+	}
+	switch {
+	case !ok:
+		return credentialUnknown
+	case expiresAt.Before(now):
+		return credentialExpired
+	default:
+		return credentialValid
+	}
+}
+
+// markCredentialStatus は全てのプロファイルにキャッシュされた認証情報の状態を設定します。
+// SSO のトークンの有効期限が切れたプロファイルには SSOExpired も設定します。
+// キャッシュディレクトリを特定できない場合は何もしません。
+func markCredentialStatus(profiles []awsProfile) {
+	ssoDir, err := ssoCacheDir()
+	if err != nil {
+		return
+	}
+	cliDir, err := cliCacheDir()
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	for i, p := range profiles {
+		profiles[i].CredentialStatus = resolveCredentialStatus(p, ssoDir, cliDir, now)
+		profiles[i].SSOExpired = checkSSOExpiry(p, ssoDir)
+	}
+}
@@ -0,0 +1,85 @@
+package profileselector
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// currentProfilePath は最後に選択したプロファイル名を書き込むファイルのパスを返します。
+// シェルのプロンプト (starship など) は環境変数の代わりにこのファイルを読んで現在のプロファイルを表示できます。
+func currentProfilePath() (string, error) {
+	dir, err := toolConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "current"), nil
+}
+
+// writeCurrentProfile は path にプロファイル名を改行なしで書き込みます。
+func writeCurrentProfile(path, profileName string) error {
+	if err := writeFileAtomic(path, []byte(profileName)); err != nil {
+		return fmt.Errorf("現在のプロファイルの書き込みに失敗しました: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic は path に data を書き込みます。
+// 同じディレクトリの一時ファイルに書き込んでから名前を変更するため、読み込む側が書きかけの内容を読むことはありません。
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("ディレクトリの作成に失敗しました: %w (ディレクトリ: %s)", err, dir)
+	}
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return fmt.Errorf("一時ファイルの作成に失敗しました: %w (ディレクトリ: %s)", err, dir)
+	}
+	// 名前の変更に成功した後は一時ファイルが存在しないため、削除の失敗は無視する
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("一時ファイルへの書き込みに失敗しました: %w (ファイル: %s)", err, tmp.Name())
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("一時ファイルへの書き込みに失敗しました: %w (ファイル: %s)", err, tmp.Name())
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("ファイルの権限の変更に失敗しました: %w (ファイル: %s)", err, tmp.Name())
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("ファイルの置き換えに失敗しました: %w (ファイル: %s)", err, path)
+	}
+	return nil
+}
+
+// readCurrentProfile は path に書き込まれたプロファイル名を返します。ファイルが存在しない場合は空文字列を返します。
+func readCurrentProfile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", nil
+		}
+		return "", fmt.Errorf("現在のプロファイルの読み込みに失敗しました: %w (ファイル: %s)", err, path)
+	}
+	return string(data), nil
+}
+
+// saveCurrentProfile は選択したプロファイル p の名前を現在のプロファイルのファイルに書き込みます。
+// プロファイルの設定を解除する項目の場合は空にします。書き込めなかった場合は、quiet が false なら警告を表示します。
+func saveCurrentProfile(p awsProfile, quiet bool) {
+	name := p.Name
+	if p.Unset {
+		name = ""
+	}
+	path, err := currentProfilePath()
+	if err == nil {
+		err = writeCurrentProfile(path, name)
+	}
+	if err != nil {
+		logErr(quiet, "警告: %v\n", err)
+	}
+}
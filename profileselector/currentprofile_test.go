@@ -0,0 +1,98 @@
+package profileselector
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestWriteAndReadCurrentProfile(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string // 空の場合はファイルを作成しない
+		profile  string
+		subdir   string
+	}{
+		{name: "新しく作成", profile: "dev"},
+		{name: "短い名前で上書き", existing: "production-admin", profile: "dev"},
+		{name: "設定の解除は空", existing: "dev", profile: ""},
+		{name: "ディレクトリも作成", profile: "dev", subdir: "nested/dir"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), tt.subdir)
+			path := filepath.Join(dir, "current")
+			if tt.existing != "" {
+				if err := os.WriteFile(path, []byte(tt.existing), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := writeCurrentProfile(path, tt.profile); err != nil {
+				t.Fatalf("writeCurrentProfile() error = %v", err)
+			}
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(raw) != tt.profile {
+				t.Errorf("ファイルの内容 = %q, want %q (改行なし)", raw, tt.profile)
+			}
+			got, err := readCurrentProfile(path)
+			if err != nil {
+				t.Fatalf("readCurrentProfile() error = %v", err)
+			}
+			if got != tt.profile {
+				t.Errorf("readCurrentProfile() = %q, want %q", got, tt.profile)
+			}
+			assertNoTempFiles(t, dir)
+		})
+	}
+
+	if got, err := readCurrentProfile(filepath.Join(t.TempDir(), "missing")); err != nil || got != "" {
+		t.Errorf("存在しないファイルの readCurrentProfile() = %q, %v, want 空, nil", got, err)
+	}
+}
+
+func TestWriteCurrentProfileIsAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "current")
+	names := []string{"dev", strings.Repeat("production-admin-", 200)}
+	if err := writeCurrentProfile(path, names[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	// 長さの異なる名前を交互に書き込みながら読み込み、書きかけの内容を読まないことを確かめる
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(done)
+		for i := range 200 {
+			if err := writeCurrentProfile(path, names[i%2]); err != nil {
+				t.Errorf("writeCurrentProfile() error = %v", err)
+				return
+			}
+		}
+	}()
+	for reading := true; reading; {
+		select {
+		case <-done:
+			reading = false
+		default:
+		}
+		got, err := readCurrentProfile(path)
+		if err != nil {
+			t.Errorf("readCurrentProfile() error = %v", err)
+			break
+		}
+		if got != names[0] && got != names[1] {
+			t.Errorf("書きかけの内容を読み込みました (%d バイト)", len(got))
+			break
+		}
+	}
+	wg.Wait()
+	assertNoTempFiles(t, dir)
+}
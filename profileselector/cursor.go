@@ -0,0 +1,43 @@
+package profileselector
+
+import (
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// cursorEnv はカーソル位置の行の先頭に表示する文字列を変更する環境変数です。
+const cursorEnv = "AWS_PROFILE_SELECTOR_CURSOR"
+
+// colorEnv はカーソルの色 (ANSI 256 色の番号または #RRGGBB) を変更する環境変数です。
+const colorEnv = "AWS_PROFILE_SELECTOR_COLOR"
+
+// defaultCursor と defaultCursorColor は環境変数で変更されていない場合のカーソルの文字列と色です。
+const (
+	defaultCursor      = "> "
+	defaultCursorColor = "208"
+)
+
+// cursorIndicatorFromEnv は環境変数 AWS_PROFILE_SELECTOR_CURSOR で指定されたカーソルの文字列を返します。
+// 未設定の場合は "> " を返します。
+func cursorIndicatorFromEnv() string {
+	if cursor := os.Getenv(cursorEnv); cursor != "" {
+		return cursor
+	}
+	return defaultCursor
+}
+
+// cursorColorFromEnv は環境変数 AWS_PROFILE_SELECTOR_COLOR で指定されたカーソルの色を返します。
+// 未設定の場合はテーマのカーソルの色 fallback を返します。
+func cursorColorFromEnv(fallback lipgloss.Color) lipgloss.Color {
+	if color := strings.TrimSpace(os.Getenv(colorEnv)); color != "" {
+		return lipgloss.Color(color)
+	}
+	return fallback
+}
+
+// cursorBlank はカーソル位置以外の行の先頭に置く、カーソルと同じ幅の空白を返します。
+func (m model) cursorBlank() string {
+	return strings.Repeat(" ", lipgloss.Width(m.cursorIndicator))
+}
@@ -0,0 +1,63 @@
+package profileselector
+
+import (
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// deleteProfileFromConfig は configPath の設定ファイルからプロファイルのセクションを削除します。
+// "profile <名前>" 形式とプレフィックスなしの "<名前>" 形式のどちらのセクションも対象にします。
+func deleteProfileFromConfig(configPath, profileName string) error {
+	cfg, err := loadConfigForUpdate(configPath)
+	if err != nil {
+		return err
+	}
+	section := findProfileSection(cfg, profileName)
+	if section == nil {
+		return fmt.Errorf("プロファイル '%s' が見つかりません (ファイル: %s)", profileName, configPath)
+	}
+	cfg.DeleteSection(section.Name())
+	return saveConfig(cfg, configPath, configFileMode)
+}
+
+// updateDeleteConfirm は削除の確認中のキー入力を処理します。
+// y でカーソル位置のプロファイルを読み込み元のファイルから削除して終了し、それ以外のキーで確認を取り消します。
+func (m model) updateDeleteConfirm(key string) (tea.Model, tea.Cmd) {
+	m.confirmingDelete = false
+	if key != "y" && key != "Y" {
+		return m, nil
+	}
+
+	p := m.profiles[m.cursor]
+	path, err := m.sources.sourcePath(p.Source)
+	if err == nil {
+		err = deleteProfileFromConfig(path, p.Name)
+	}
+	if err != nil {
+		m.toast = err.Error()
+		m.toastID++
+		return m, clearToastCmd(m.toastID)
+	}
+	m.deletedProfile = p.Name
+	return m, tea.Quit
+}
+
+// runDelete は delete サブコマンドを実行し、終了コードを返します。
+// プロファイルの一覧を削除モードで表示し、選択して確認したプロファイルを削除します。
+func runDelete(args []string) int {
+	m, code, ok := runModeTUI(args, func(m model) model {
+		m.deleteMode = true
+		return m
+	})
+	if !ok {
+		return code
+	}
+	if m.deletedProfile == "" {
+		fmt.Fprintln(os.Stderr, "プロファイルの削除がキャンセルされました。")
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "プロファイル '%s' を削除しました。\n", m.deletedProfile)
+	return 0
+}
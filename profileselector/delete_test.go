@@ -0,0 +1,105 @@
+package profileselector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDeleteProfileFromConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		config      string
+		profileName string
+		want        string
+		wantErr     bool
+	}{
+		{
+			name:        "profile プレフィックス付きのセクション",
+			config:      "[default]\nregion = us-east-1\n\n[profile dev]\nregion = ap-northeast-1\n\n[profile prod]\nregion = us-west-2\n",
+			profileName: "dev",
+			want:        "[default]\nregion = us-east-1\n\n[profile prod]\nregion = us-west-2\n",
+		},
+		{
+			name:        "default",
+			config:      "[default]\nregion = us-east-1\n\n[profile dev]\nregion = ap-northeast-1\n",
+			profileName: "default",
+			want:        "[profile dev]\nregion = ap-northeast-1\n",
+		},
+		{
+			name:        "プレフィックスのないセクション",
+			config:      "[dev]\nregion = ap-northeast-1\n\n[profile prod]\nregion = us-west-2\n",
+			profileName: "dev",
+			want:        "[profile prod]\nregion = us-west-2\n",
+		},
+		{
+			name:        "存在しないプロファイル",
+			config:      "[profile dev]\n",
+			profileName: "staging",
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config")
+			if err := os.WriteFile(path, []byte(tt.config), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			err := deleteProfileFromConfig(path, tt.profileName)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("deleteProfileFromConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := tt.want
+			if tt.wantErr {
+				want = tt.config
+			}
+			if string(data) != want {
+				t.Errorf("設定ファイル = %q, want %q", data, want)
+			}
+		})
+	}
+}
+
+func TestDeleteModeConfirmation(t *testing.T) {
+	tests := []struct {
+		name        string
+		keys        []string
+		wantDeleted string
+		wantConfig  string
+	}{
+		{name: "y で削除", keys: []string{"j", "enter", "y"}, wantDeleted: "prod", wantConfig: "[profile dev]\n"},
+		{name: "y 以外で取り消し", keys: []string{"j", "enter", "n"}, wantConfig: "[profile dev]\n\n[profile prod]\n"},
+		{name: "Enter だけでは削除しない", keys: []string{"j", "enter"}, wantConfig: "[profile dev]\n\n[profile prod]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := filepath.Join(t.TempDir(), "config")
+			if err := os.WriteFile(config, []byte("[profile dev]\n\n[profile prod]\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			opts := options{configPaths: []string{config}}
+			profiles, err := loadAWSProfiles(opts.sources())
+			if err != nil {
+				t.Fatal(err)
+			}
+			m := newTestModel(t, opts, profiles)
+			m.deleteMode = true
+			m = pressKeys(t, m, tt.keys...)
+
+			if m.deletedProfile != tt.wantDeleted {
+				t.Errorf("deletedProfile = %q, want %q", m.deletedProfile, tt.wantDeleted)
+			}
+			data, err := os.ReadFile(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.wantConfig {
+				t.Errorf("設定ファイル = %q, want %q", data, tt.wantConfig)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package profileselector
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// diffState は 2 つのプロファイルを比較する差分モードの状態です。
+type diffState int
+
+const (
+	diffOff       diffState = iota // 差分モードではない
+	diffPickLeft                   // 比較元 (左側) のプロファイルを選択中
+	diffPickRight                  // 比較先 (右側) のプロファイルを選択中
+	diffShowing                    // 差分を表示中
+)
+
+// diffKind は差分の行の種類です。
+type diffKind int
+
+const (
+	diffSame      diffKind = iota // 両方に同じ値で存在する
+	diffChanged                   // 両方に存在するが値が異なる
+	diffOnlyLeft                  // 左側のプロファイルにだけ存在する
+	diffOnlyRight                 // 右側のプロファイルにだけ存在する
+)
+
+// diffLine は 2 つのプロファイルの 1 つのキーについての差分です。
+type diffLine struct {
+	Key   string   // キー名
+	Left  string   // 左側のプロファイルの値
+	Right string   // 右側のプロファイルの値
+	Kind  diffKind // 差分の種類
+}
+
+// diffProfiles は 2 つのプロファイルの RawKeys を比較し、キー名順に並べた差分を返します。
+func diffProfiles(a, b awsProfile) []diffLine {
+	keys := make(map[string]struct{}, len(a.RawKeys)+len(b.RawKeys))
+	for key := range a.RawKeys {
+		keys[key] = struct{}{}
+	}
+	for key := range b.RawKeys {
+		keys[key] = struct{}{}
+	}
+
+	lines := make([]diffLine, 0, len(keys))
+	for key := range keys {
+		left, inLeft := a.RawKeys[key]
+		right, inRight := b.RawKeys[key]
+		line := diffLine{Key: key, Left: left, Right: right}
+		switch {
+		case !inRight:
+			line.Kind = diffOnlyLeft
+		case !inLeft:
+			line.Kind = diffOnlyRight
+		case left != right:
+			line.Kind = diffChanged
+		default:
+			line.Kind = diffSame
+		}
+		lines = append(lines, line)
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		return lines[i].Key < lines[j].Key
+	})
+	return lines
+}
+
+// updateDiff は差分モード中のキー入力を処理します。処理したキーであれば true を返します。
+// Enter で比較するプロファイルを 1 つずつ選び、Esc または差分キーで何も出力せずに通常の表示に戻ります。
+func (m model) updateDiff(key string) (model, tea.Cmd, bool) {
+	if key == "esc" || m.keys.Matches(actionDiff, key) {
+		m.diffState = diffOff
+		return m, nil, true
+	}
+
+	switch m.diffState {
+	case diffPickLeft, diffPickRight:
+		if !m.keys.Matches(actionSelect, key) || len(m.profiles) == 0 {
+			return m, nil, false
+		}
+		if m.diffState == diffPickLeft {
+			m.diffLeft = m.profiles[m.cursor]
+			m.diffState = diffPickRight
+		} else {
+			m.diffRight = m.profiles[m.cursor]
+			m.diffState = diffShowing
+		}
+		return m, nil, true
+
+	case diffShowing:
+		// 差分の表示中は終了キー以外を無視する
+		if m.keys.Matches(actionQuit, key) {
+			m.quitting = true
+			return m, tea.Quit, true
+		}
+		return m, nil, true
+	}
+	return m, nil, false
+}
+
+// renderDiff は比較元と比較先のプロファイルのキーを左右に並べて描画します。
+// 値が異なるキーは黄色、片方にだけ存在するキーは赤 (左側のみ) と緑 (右側のみ) で表示します。
+func (m model) renderDiff() string {
+	colWidth := m.innerWidth() / 2
+	if colWidth < 2 {
+		colWidth = 2
+	}
+	cellStyle := m.theme.style().Width(colWidth)
+	kindStyles := map[diffKind]lipgloss.Style{
+		diffSame:      m.theme.style(),
+		diffChanged:   m.theme.style().Foreground(m.theme.warning),
+		diffOnlyLeft:  m.theme.style().Foreground(m.theme.danger),
+		diffOnlyRight: m.theme.style().Foreground(m.theme.success),
+	}
+
+	cell := func(text string, style lipgloss.Style) string {
+		return cellStyle.Render(style.Render(ansi.Truncate(text, colWidth-1, "…")))
+	}
+
+	titleStyle := m.theme.style().Bold(true)
+	rows := []string{lipgloss.JoinHorizontal(lipgloss.Top,
+		cell(fmt.Sprintf("[%s] (%s)", m.diffLeft.Name, m.diffLeft.Source), titleStyle),
+		cell(fmt.Sprintf("[%s] (%s)", m.diffRight.Name, m.diffRight.Source), titleStyle))}
+
+	for _, line := range diffProfiles(m.diffLeft, m.diffRight) {
+		style := kindStyles[line.Kind]
+		left, right := "", ""
+		if line.Kind != diffOnlyRight {
+			left = fmt.Sprintf("%s = %s", line.Key, line.Left)
+		}
+		if line.Kind != diffOnlyLeft {
+			right = fmt.Sprintf("%s = %s", line.Key, line.Right)
+		}
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, cell(left, style), cell(right, style)))
+	}
+
+	if len(rows) > m.listVisibleHeight {
+		rows = rows[:m.listVisibleHeight]
+	}
+	return strings.Join(rows, "\n") + "\n"
+}
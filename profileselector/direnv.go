@@ -0,0 +1,79 @@
+package profileselector
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// direnvShell は --shell に指定すると direnv の .envrc に書ける形式で出力するシェル名です。
+const direnvShell = "direnv"
+
+// parseOutputShell は --shell に指定されたシェル名を検証します。--print-init のシェルに加えて direnv も指定できます。
+func parseOutputShell(s string) (string, error) {
+	if s == direnvShell {
+		return s, nil
+	}
+	shell, err := parseShell(s)
+	if err != nil {
+		return "", fmt.Errorf("シェルには %s, %s のいずれかを指定してください: %s", strings.Join(supportedShells, ", "), direnvShell, s)
+	}
+	return shell, nil
+}
+
+// profileExports はプロファイルを使うために設定する環境変数の名前と値を返します。
+// AWS_DEFAULT_PROFILE に加え、プロファイルに region があれば AWS_DEFAULT_REGION も含めます。
+// envPrefix が指定されている場合は、環境変数の名前の AWS_DEFAULT をその接頭辞に置き換えます。
+// env_overrides.json でプロファイルに追加の環境変数が指定されていれば、それも含めます (同じ名前の場合はそちらを優先します)。
+// 環境変数の名前として使えない名前は含めません。
+func profileExports(p awsProfile, envPrefix string) map[string]string {
+	exports := map[string]string{envVarName(envPrefix, "PROFILE"): p.Name}
+	if p.Region != "" {
+		exports[envVarName(envPrefix, "REGION")] = p.Region
+	}
+	for _, kv := range p.EnvOverrides {
+		name, value, _ := strings.Cut(kv, "=")
+		if !envNamePattern.MatchString(name) {
+			continue
+		}
+		exports[name] = value
+	}
+	return exports
+}
+
+// safeShellValuePattern は引用しなくてもシェルの 1 つの単語として扱われる値に一致する正規表現です。
+var safeShellValuePattern = regexp.MustCompile(`^[A-Za-z0-9_./:@%+,=-]*$`)
+
+// shellValue は値を shell の export 文や set 文の右辺に書ける形式で返します。空白などを含む場合だけ quoteShellWord で引用します。
+func shellValue(shell, s string) string {
+	if safeShellValuePattern.MatchString(s) {
+		return s
+	}
+	return quoteShellWord(shell, s)
+}
+
+// writeEnvrc は exports の環境変数を設定する export 文を、名前順に path の .envrc ファイルに書き込みます。
+// overwrite が true の場合はファイルを上書きし、false の場合は末尾に追記します。
+func writeEnvrc(path string, exports map[string]string, overwrite bool) error {
+	var b strings.Builder
+	for _, name := range slices.Sorted(maps.Keys(exports)) {
+		fmt.Fprintf(&b, "export %s=%s\n", name, shellValue("", exports[name]))
+	}
+
+	flag := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	if overwrite {
+		flag = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	f, err := os.OpenFile(path, flag, 0o644)
+	if err != nil {
+		return fmt.Errorf(".envrc ファイルを開けませんでした: %w (ファイル: %s)", err, path)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(b.String()); err != nil {
+		return fmt.Errorf(".envrc ファイルの書き込みに失敗しました: %w (ファイル: %s)", err, path)
+	}
+	return nil
+}
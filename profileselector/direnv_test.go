@@ -0,0 +1,125 @@
+package profileselector
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestWriteEnvrc(t *testing.T) {
+	exports := map[string]string{"AWS_DEFAULT_REGION": "ap-northeast-1", "AWS_DEFAULT_PROFILE": "dev"}
+	tests := []struct {
+		name      string
+		existing  string // 空の場合はファイルを作成しない
+		exports   map[string]string
+		overwrite bool
+		want      string
+	}{
+		{
+			name:    "新しく作成",
+			exports: exports,
+			want:    "export AWS_DEFAULT_PROFILE=dev\nexport AWS_DEFAULT_REGION=ap-northeast-1\n",
+		},
+		{
+			name:     "末尾に追記",
+			existing: "use nix\n",
+			exports:  exports,
+			want:     "use nix\nexport AWS_DEFAULT_PROFILE=dev\nexport AWS_DEFAULT_REGION=ap-northeast-1\n",
+		},
+		{
+			name:      "上書き",
+			existing:  "export AWS_DEFAULT_PROFILE=old\n",
+			exports:   exports,
+			overwrite: true,
+			want:      "export AWS_DEFAULT_PROFILE=dev\nexport AWS_DEFAULT_REGION=ap-northeast-1\n",
+		},
+		{
+			name:    "空白を含む値は引用する",
+			exports: map[string]string{"NOTE": "a b"},
+			want:    "export NOTE='a b'\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".envrc")
+			if tt.existing != "" {
+				if err := os.WriteFile(path, []byte(tt.existing), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := writeEnvrc(path, tt.exports, tt.overwrite); err != nil {
+				t.Fatalf("writeEnvrc() error = %v", err)
+			}
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf(".envrc = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if err := writeEnvrc(filepath.Join(t.TempDir(), "missing", ".envrc"), exports, false); err == nil {
+		t.Error("存在しないディレクトリで writeEnvrc() がエラーを返しませんでした")
+	}
+}
+
+func TestProfileExports(t *testing.T) {
+	tests := []struct {
+		name      string
+		profile   awsProfile
+		envPrefix string
+		want      map[string]string
+	}{
+		{name: "region なし", profile: awsProfile{Name: "dev"}, want: map[string]string{"AWS_DEFAULT_PROFILE": "dev"}},
+		{
+			name:    "region あり",
+			profile: awsProfile{Name: "dev", Region: "us-east-1"},
+			want:    map[string]string{"AWS_DEFAULT_PROFILE": "dev", "AWS_DEFAULT_REGION": "us-east-1"},
+		},
+		{
+			name:      "接頭辞を指定",
+			profile:   awsProfile{Name: "dev", Region: "us-east-1"},
+			envPrefix: "MY",
+			want:      map[string]string{"MY_PROFILE": "dev", "MY_REGION": "us-east-1"},
+		},
+		{
+			name:    "追加の環境変数は同じ名前なら優先し、不正な名前は含めない",
+			profile: awsProfile{Name: "dev", EnvOverrides: []string{"AWS_DEFAULT_PROFILE=other", "TF_VAR_env=dev", "1BAD=x"}},
+			want:    map[string]string{"AWS_DEFAULT_PROFILE": "other", "TF_VAR_env": "dev"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := profileExports(tt.profile, tt.envPrefix); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("profileExports() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseOutputShell(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{value: "direnv", want: "direnv"},
+		{value: "bash", want: "bash"},
+		{value: "fish", want: "fish"},
+		{value: "cmd", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseOutputShell(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseOutputShell(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseOutputShell(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
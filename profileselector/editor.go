@@ -0,0 +1,80 @@
+package profileselector
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// defaultEditor は $EDITOR が未設定の場合に使用するエディタです。
+const defaultEditor = "vi"
+
+// lineJumpEditors は "+行番号" 引数で指定行から開けるエディタの一覧です。
+var lineJumpEditors = map[string]bool{
+	"vi":          true,
+	"vim":         true,
+	"nvim":        true,
+	"view":        true,
+	"nano":        true,
+	"emacs":       true,
+	"emacsclient": true,
+	"micro":       true,
+}
+
+// editorFinishedMsg はエディタの終了時に送られるメッセージです。
+type editorFinishedMsg struct {
+	err error // エディタの実行時に発生したエラー
+}
+
+// openEditorCmd は設定ファイルを $EDITOR で開くコマンドを返します。
+// 対応しているエディタでは、指定したプロファイルのセクション行から開きます。
+func openEditorCmd(configFile, profileName string) tea.Cmd {
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{defaultEditor}
+	}
+
+	args := editor[1:]
+	if lineJumpEditors[filepath.Base(editor[0])] {
+		if line := findSectionLine(configFile, profileName); line > 0 {
+			args = append(args, fmt.Sprintf("+%d", line))
+		}
+	}
+	args = append(args, configFile)
+
+	c := exec.Command(editor[0], args...)
+	return tea.ExecProcess(c, func(err error) tea.Msg {
+		return editorFinishedMsg{err: err}
+	})
+}
+
+// findSectionLine は設定ファイル内でプロファイルのセクションが定義されている行番号 (1始まり) を返します。
+// 見つからない場合は 0 を返します。
+func findSectionLine(configFile, profileName string) int {
+	f, err := os.Open(configFile)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(text, "[") || !strings.HasSuffix(text, "]") {
+			continue
+		}
+		sectionName := strings.TrimSpace(text[1 : len(text)-1])
+		if strings.HasPrefix(sectionName, "profile ") {
+			sectionName = strings.TrimSpace(strings.TrimPrefix(sectionName, "profile "))
+		}
+		if sectionName == profileName {
+			return line
+		}
+	}
+	return 0
+}
@@ -0,0 +1,46 @@
+package profileselector
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// displayPath は読み込んだ設定ファイルのパスを表示用に返します。
+// 標準入力から読み込んだ場合は stdinConfigName を、パスを解決できない場合は空文字列を返します。
+func (s profileSources) displayPath() string {
+	if source, err := s.configSource(); err == nil && source != nil {
+		return stdinConfigName
+	}
+	path, err := s.configPath()
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// emptyProfilesHelp はプロファイルが 1 つも見つからなかったときに表示する、原因と対処の案内を返します。
+// configPath は読み込んだ設定ファイルのパスで、環境変数 AWS_CONFIG_FILE に存在しないパスが指定されている場合はそのことを伝えます。
+func emptyProfilesHelp(configPath string) string {
+	var s strings.Builder
+	if configPath != "" {
+		fmt.Fprintf(&s, " 読み込んだ設定ファイル: %s\n", configPath)
+	}
+	if configPath != "" && configPath != stdinConfigName {
+		if _, err := os.Stat(configPath); errors.Is(err, fs.ErrNotExist) {
+			if os.Getenv(configFileEnv) != "" {
+				fmt.Fprintf(&s, " 環境変数 %s に指定されたファイルが存在しません。パスを確認するか、環境変数を解除してください。\n", configFileEnv)
+			} else {
+				s.WriteString(" 設定ファイルが存在しません。\n")
+			}
+		}
+	}
+	s.WriteString(" プロファイルを作成するには aws configure (SSO の場合は aws configure sso) を実行するか、create サブコマンドを使ってください。\n")
+	s.WriteString("\n よくある原因:\n")
+	s.WriteString("  - config のセクション名が [profile <名前>] の形式になっていない (profile を省略できるのは [default] だけです)\n")
+	fmt.Fprintf(&s, "  - 環境変数 %s や --config が別のファイルを指している\n", configFileEnv)
+	s.WriteString("  - --profile-prefix、--account-id、--roles-only、--allow-list、--deny-list の条件に一致するプロファイルがない\n")
+	return s.String()
+}
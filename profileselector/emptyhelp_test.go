@@ -0,0 +1,70 @@
+package profileselector
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEmptyProfilesHelp(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "config")
+	if err := os.WriteFile(existing, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing")
+	tests := []struct {
+		name        string
+		configPath  string
+		env         string
+		wantContain []string
+		wantOmit    []string
+	}{
+		{
+			name:        "空の設定ファイル",
+			configPath:  existing,
+			wantContain: []string{existing, "aws configure"},
+			wantOmit:    []string{"存在しません"},
+		},
+		{
+			name:        "設定ファイルがない",
+			configPath:  missing,
+			wantContain: []string{missing, " 設定ファイルが存在しません。"},
+			wantOmit:    []string{"環境変数 " + configFileEnv + " に指定されたファイル"},
+		},
+		{
+			name:        "AWS_CONFIG_FILE のファイルがない",
+			configPath:  missing,
+			env:         missing,
+			wantContain: []string{missing, "環境変数 " + configFileEnv + " に指定されたファイルが存在しません"},
+		},
+		{
+			name:        "標準入力",
+			configPath:  stdinConfigName,
+			wantContain: []string{stdinConfigName},
+			wantOmit:    []string{"存在しません"},
+		},
+		{
+			name:        "パスが不明",
+			wantContain: []string{"aws configure", "よくある原因"},
+			wantOmit:    []string{"読み込んだ設定ファイル"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(configFileEnv, tt.env)
+			got := emptyProfilesHelp(tt.configPath)
+			for _, want := range tt.wantContain {
+				if !strings.Contains(got, want) {
+					t.Errorf("emptyProfilesHelp() に %q が含まれていません:\n%s", want, got)
+				}
+			}
+			for _, omit := range tt.wantOmit {
+				if strings.Contains(got, omit) {
+					t.Errorf("emptyProfilesHelp() に %q が含まれています:\n%s", omit, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package profileselector
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+)
+
+// envNamePattern はシェルの環境変数の名前として使える文字列に一致する正規表現です。
+var envNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// envOverridesPath はプロファイルごとに追加で設定する環境変数を記述するファイルのパスを返します。
+func envOverridesPath() (string, error) {
+	dir, err := toolConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "env_overrides.json"), nil
+}
+
+// loadEnvOverrides はファイルを読み込み、プロファイル名ごとに追加で設定する環境変数の名前と値の対応を返します。
+// ファイルが存在しない場合は空の対応を返します。
+func loadEnvOverrides(path string) (map[string]map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return map[string]map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("環境変数の設定の読み込みに失敗しました: %w (ファイル: %s)", err, path)
+	}
+
+	overrides := map[string]map[string]string{}
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return nil, fmt.Errorf("環境変数の設定の解析に失敗しました: %w (ファイル: %s)", err, path)
+	}
+	return overrides, nil
+}
+
+// loadEnvOverridesOrEmpty はプロファイルごとに追加で設定する環境変数を読み込みます。読み込めない場合は空の対応を返します。
+func loadEnvOverridesOrEmpty() map[string]map[string]string {
+	path, err := envOverridesPath()
+	if err != nil {
+		return map[string]map[string]string{}
+	}
+	overrides, err := loadEnvOverrides(path)
+	if err != nil {
+		return map[string]map[string]string{}
+	}
+	return overrides
+}
+
+// applyEnvOverrides は profileName のプロファイルに追加で設定する環境変数を、名前順に "名前=値" の形式で返します。
+// 出力をシェルで評価しても安全なように、環境変数の名前として使えない名前は無視します。
+func applyEnvOverrides(profileName string, overrides map[string]map[string]string) []string {
+	vars := overrides[profileName]
+	var env []string
+	for _, name := range slices.Sorted(maps.Keys(vars)) {
+		if envNamePattern.MatchString(name) {
+			env = append(env, name+"="+vars[name])
+		}
+	}
+	return env
+}
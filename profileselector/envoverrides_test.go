@@ -0,0 +1,32 @@
+package profileselector
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyEnvOverrides(t *testing.T) {
+	overrides := map[string]map[string]string{
+		"dev": {
+			"B_VAR":           "2",
+			"A_VAR":           "1",
+			"X;touch /tmp/pw": "1",
+			"1ABC":            "1",
+		},
+	}
+	tests := []struct {
+		name    string
+		profile string
+		want    []string
+	}{
+		{name: "名前順に返し、使えない名前は無視する", profile: "dev", want: []string{"A_VAR=1", "B_VAR=2"}},
+		{name: "指定のないプロファイル", profile: "prod", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := applyEnvOverrides(tt.profile, overrides); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("applyEnvOverrides() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
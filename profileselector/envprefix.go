@@ -0,0 +1,23 @@
+package profileselector
+
+import "fmt"
+
+// defaultEnvPrefix はプロファイルを設定する環境変数のデフォルトの接頭辞です。
+const defaultEnvPrefix = "AWS_DEFAULT"
+
+// parseEnvPrefix は --env-prefix に指定された環境変数の接頭辞を検証します。
+func parseEnvPrefix(s string) (string, error) {
+	if !envNamePattern.MatchString(s) {
+		return "", fmt.Errorf("環境変数の名前に使える文字 (英数字と _、先頭は数字以外) で指定してください: %s", s)
+	}
+	return s, nil
+}
+
+// envVarName は接頭辞 prefix と名前の残りの部分 suffix (PROFILE や REGION) をつないだ環境変数の名前を返します。
+// prefix が空の場合は defaultEnvPrefix を使います。
+func envVarName(prefix, suffix string) string {
+	if prefix == "" {
+		prefix = defaultEnvPrefix
+	}
+	return prefix + "_" + suffix
+}
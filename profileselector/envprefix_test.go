@@ -0,0 +1,80 @@
+package profileselector
+
+import (
+	"io"
+	"testing"
+)
+
+func TestParseEnvPrefix(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantErr bool
+	}{
+		{value: "AWS_DEFAULT"},
+		{value: "CUSTOM_AWS"},
+		{value: "_X1"},
+		{value: "1AWS", wantErr: true},
+		{value: "MY-AWS", wantErr: true},
+		{value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			if _, err := parseEnvPrefix(tt.value); (err != nil) != tt.wantErr {
+				t.Errorf("parseEnvPrefix(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFormatExportWithEnvPrefix(t *testing.T) {
+	p := awsProfile{Name: "dev", Region: "us-east-1"}
+	tests := []struct {
+		name   string
+		format outputFormat
+		want   string
+	}{
+		{name: "デフォルト", format: outputFormat{withExport: true}, want: "export AWS_DEFAULT_PROFILE=dev"},
+		{name: "接頭辞を指定", format: outputFormat{withExport: true, envPrefix: "CUSTOM_AWS"}, want: "export CUSTOM_AWS_PROFILE=dev"},
+		{name: "fish", format: outputFormat{withExport: true, shell: "fish", envPrefix: "CUSTOM_AWS"}, want: "set -gx CUSTOM_AWS_PROFILE dev"},
+		{name: "direnv では region も", format: outputFormat{withExport: true, shell: direnvShell, envPrefix: "CUSTOM_AWS"}, want: "export CUSTOM_AWS_PROFILE=dev\nexport CUSTOM_AWS_REGION=us-east-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatExport(p, tt.format); got != tt.want {
+				t.Errorf("formatExport() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInitialCursorWithEnvPrefix(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		customEnv  string
+		defaultEnv string
+		want       string
+	}{
+		{name: "デフォルトの接頭辞", defaultEnv: "prod", customEnv: "stg", want: "prod"},
+		{name: "指定した接頭辞の環境変数", args: []string{"--env-prefix", "CUSTOM_AWS"}, defaultEnv: "prod", customEnv: "stg", want: "stg"},
+		{name: "指定した接頭辞の環境変数がない", args: []string{"--env-prefix", "CUSTOM_AWS"}, defaultEnv: "prod", want: "dev"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseOptionsTo(io.Discard, tt.args)
+			if err != nil {
+				t.Fatalf("parseOptionsTo() error = %v", err)
+			}
+			newTestModel(t, opts, nil) // 一時的な設定ディレクトリを使う
+			t.Setenv(defaultProfileEnv, "")
+			t.Setenv("AWS_DEFAULT_PROFILE", tt.defaultEnv)
+			t.Setenv("CUSTOM_AWS_PROFILE", tt.customEnv)
+			m := initialModel(opts, newStyleRenderer(io.Discard, true))
+			m.loading = false
+			m = m.withLoadedProfiles(testProfiles("dev", "prod", "stg"), nil)
+			if got := m.profiles[m.cursor].Name; got != tt.want {
+				t.Errorf("カーソル位置のプロファイル = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package profileselector
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"slices"
+)
+
+// execWithProfile は選択したプロファイルを環境変数に設定したうえで args のコマンドを実行し、終了を待ちます。
+// 設定する環境変数は profileExports と同じです。プロファイルの設定を解除する項目の場合は、AWS_DEFAULT_PROFILE と AWS_PROFILE を除いて実行します。
+// envPrefix は設定する環境変数の接頭辞です (空の場合は AWS_DEFAULT)。標準入出力はそのままコマンドに引き継ぎます。
+// configFile は --config で指定された設定ファイルのパスで、空でなければコマンドが同じファイルを読むように環境変数 AWS_CONFIG_FILE に設定します。
+func execWithProfile(profile awsProfile, args []string, envPrefix, configFile string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("実行するコマンドを指定してください")
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	if profile.Unset {
+		cmd.Env = environWithoutProfile(envPrefix)
+	} else {
+		cmd.Env = append(os.Environ(), profileEnv(profile, envPrefix)...)
+	}
+	if configFile != "" {
+		cmd.Env = append(cmd.Env, configFileEnv+"="+configFile)
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// profileEnv はプロファイルを使うために子プロセスに設定する環境変数を "名前=値" の形式で返します。
+func profileEnv(profile awsProfile, envPrefix string) []string {
+	exports := profileExports(profile, envPrefix)
+	env := make([]string, 0, len(exports))
+	for _, name := range slices.Sorted(maps.Keys(exports)) {
+		env = append(env, name+"="+exports[name])
+	}
+	return env
+}
+
+// runExec は --exec で指定されたコマンドを選択したプロファイルで実行し、終了コードを返します。
+// コマンドが 0 以外で終了した場合は、その終了コードをそのまま返します。
+func runExec(profile awsProfile, opts options) int {
+	var configFile string
+	if len(opts.configPaths) > 0 {
+		path, err := opts.sources().configPath()
+		if err != nil {
+			logErr(opts.quiet, "エラー: %v\n", err)
+			return 1
+		}
+		configFile = path
+	}
+	err := execWithProfile(profile, opts.execCommand, opts.envPrefix, configFile)
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	logErr(opts.quiet, "エラー: コマンドの実行に失敗しました: %v\n", err)
+	return 1
+}
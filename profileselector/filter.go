@@ -0,0 +1,83 @@
+package profileselector
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// roleArnAccountPattern は role_arn (arn:aws:iam::ACCOUNT:role/...) からアカウント ID を取り出す正規表現です。
+// aws-cn や aws-us-gov などのパーティションにも対応します。
+var roleArnAccountPattern = regexp.MustCompile(`^arn:aws[a-z-]*:iam::(\d{12}):role/`)
+
+// accountIDFromRoleArn は role_arn に含まれる 12 桁のアカウント ID を返します。
+// 想定した形式でない場合は空文字列を返します。
+func accountIDFromRoleArn(roleArn string) string {
+	m := roleArnAccountPattern.FindStringSubmatch(strings.TrimSpace(roleArn))
+	if m == nil {
+		return ""
+	}
+	return m[1]
+}
+
+// shortenRoleArn は role_arn を「アカウント ID:ロール名」の短い形式で返します。
+// ロール名はパスを除いた最後の部分です。想定した形式でない場合は role_arn をそのまま返します。
+func shortenRoleArn(roleArn string) string {
+	account := accountIDFromRoleArn(roleArn)
+	if account == "" {
+		return roleArn
+	}
+	_, resource, _ := strings.Cut(strings.TrimSpace(roleArn), ":role/")
+	name := resource[strings.LastIndex(resource, "/")+1:]
+	if name == "" {
+		return roleArn
+	}
+	return account + ":" + name
+}
+
+// profileFilter はコマンドライン引数で指定された、表示するプロファイルの条件です。
+// 条件ごとに複数の値を指定した場合はいずれかに一致すれば表示し、異なる条件は全てに一致する必要があります。
+type profileFilter struct {
+	prefixes   []string // --profile-prefix で指定されたプロファイル名の接頭辞
+	accountIDs []string // --account-id で指定されたアカウント ID
+	allowList  []string // --allow-list のファイルから読み込んだパターン (nil の場合は指定なし、空の場合は何も表示しない)
+	denyList   []string // --deny-list のファイルから読み込んだパターン
+	rolesOnly  bool     // --roles-only の指定時は role_arn のあるプロファイルだけを表示する
+}
+
+// apply は条件に一致するプロファイルだけを抽出します。条件が指定されていない場合は全てのプロファイルを返します。
+func (f profileFilter) apply(profiles []awsProfile) []awsProfile {
+	if f.allowList != nil {
+		profiles = applyAllowList(profiles, f.allowList)
+	}
+	// 許可リストと除外リストの両方に一致するプロファイルは表示しない
+	if len(f.denyList) > 0 {
+		profiles = applyDenyList(profiles, f.denyList)
+	}
+	if len(f.prefixes) == 0 && len(f.accountIDs) == 0 && !f.rolesOnly {
+		return profiles
+	}
+	var filtered []awsProfile
+	for _, p := range profiles {
+		if f.matches(p) {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
+// matches はプロファイルが条件に一致するかどうかを返します。
+func (f profileFilter) matches(p awsProfile) bool {
+	if len(f.prefixes) > 0 && !slices.ContainsFunc(f.prefixes, func(prefix string) bool {
+		return strings.HasPrefix(p.Name, prefix)
+	}) {
+		return false
+	}
+	if len(f.accountIDs) > 0 && !slices.Contains(f.accountIDs, p.AccountID) {
+		return false
+	}
+	if f.rolesOnly && p.RoleArn == "" {
+		return false
+	}
+	return true
+}
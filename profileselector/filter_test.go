@@ -0,0 +1,144 @@
+package profileselector
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestProfilePrefixFilter(t *testing.T) {
+	profiles := testProfiles("dev-api", "prod-api", "staging-web", "dev-web", "sandbox")
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "指定なし", want: []string{"dev-api", "prod-api", "staging-web", "dev-web", "sandbox"}},
+		{name: "1 つの接頭辞", args: []string{"--profile-prefix", "dev-"}, want: []string{"dev-api", "dev-web"}},
+		{name: "複数の接頭辞はいずれかに一致", args: []string{"--profile-prefix", "dev-", "--profile-prefix", "prod-"}, want: []string{"dev-api", "prod-api", "dev-web"}},
+		{name: "一致なし", args: []string{"--profile-prefix", "qa-"}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseOptionsTo(io.Discard, tt.args)
+			if err != nil {
+				t.Fatalf("parseOptionsTo() error = %v", err)
+			}
+			m := newTestModel(t, opts, profiles)
+			if got := profileNames(m.allProfiles); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("プロファイル = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRolesOnlyFilter(t *testing.T) {
+	profiles := []awsProfile{
+		{Name: "ci", RawKeys: map[string]string{"aws_access_key_id": "AKIAEXAMPLE"}},
+		{Name: "dev-admin", RoleArn: "arn:aws:iam::111111111111:role/Admin"},
+		{Name: "sso", RawKeys: map[string]string{"sso_session": "corp"}},
+		{Name: "prod-admin", RoleArn: "arn:aws:iam::222222222222:role/Admin"},
+	}
+	tests := []struct {
+		name   string
+		filter profileFilter
+		want   []string
+	}{
+		{name: "指定なし", want: []string{"ci", "dev-admin", "sso", "prod-admin"}},
+		{name: "role_arn のあるプロファイルだけ", filter: profileFilter{rolesOnly: true}, want: []string{"dev-admin", "prod-admin"}},
+		{name: "接頭辞と組み合わせる", filter: profileFilter{rolesOnly: true, prefixes: []string{"prod-"}}, want: []string{"prod-admin"}},
+		{name: "接頭辞に一致するのは role_arn のないプロファイルだけ", filter: profileFilter{rolesOnly: true, prefixes: []string{"ci"}}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := profileNames(tt.filter.apply(profiles)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("apply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunListRolesOnly(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv(credentialsFileEnv, filepath.Join(dir, "credentials"))
+	config := filepath.Join(dir, "config")
+	content := "[profile dev]\nregion = us-east-1\n\n[profile admin]\nrole_arn = arn:aws:iam::123456789012:role/Admin\nsource_profile = dev\n"
+	if err := os.WriteFile(config, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{args: []string{"--list"}, want: "admin\ndev\n"},
+		{args: []string{"--list", "--roles-only"}, want: "admin\n"},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			opts, err := parseOptionsTo(io.Discard, append([]string{"--config", config}, tt.args...))
+			if err != nil {
+				t.Fatalf("parseOptionsTo() error = %v", err)
+			}
+			var code int
+			stdout, _ := captureOutput(t, func() { code = runSelect(opts) })
+			if code != 0 {
+				t.Errorf("終了コード = %d, want 0", code)
+			}
+			if stdout != tt.want {
+				t.Errorf("標準出力 = %q, want %q", stdout, tt.want)
+			}
+		})
+	}
+}
+
+func TestShortenRoleArn(t *testing.T) {
+	tests := []struct {
+		roleArn string
+		want    string
+	}{
+		{roleArn: "arn:aws:iam::123456789012:role/Admin", want: "123456789012:Admin"},
+		{roleArn: "arn:aws:iam::123456789012:role/team/ops/Deployer", want: "123456789012:Deployer"},
+		{roleArn: "arn:aws-cn:iam::123456789012:role/Admin", want: "123456789012:Admin"},
+		{roleArn: "  arn:aws:iam::123456789012:role/Admin  ", want: "123456789012:Admin"},
+		{roleArn: "arn:aws:iam::123456789012:role/", want: "arn:aws:iam::123456789012:role/"},
+		{roleArn: "arn:aws:iam::123456789012:user/alice", want: "arn:aws:iam::123456789012:user/alice"},
+		{roleArn: "not-an-arn", want: "not-an-arn"},
+		{roleArn: "", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.roleArn, func(t *testing.T) {
+			if got := shortenRoleArn(tt.roleArn); got != tt.want {
+				t.Errorf("shortenRoleArn(%q) = %q, want %q", tt.roleArn, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToggleShortRoleArn(t *testing.T) {
+	profiles := testProfiles("dev")
+	profiles[0].RoleArn = "arn:aws:iam::123456789012:role/team/Admin"
+	tests := []struct {
+		name string
+		keys []string
+		want string
+	}{
+		{name: "初期状態は完全な形式", keys: []string{"v"}, want: "(RoleARN: arn:aws:iam::123456789012:role/team/Admin)"},
+		{name: "a で短い形式", keys: []string{"v", "a"}, want: "(RoleARN: 123456789012:Admin)"},
+		{name: "もう一度 a で完全な形式に戻る", keys: []string{"v", "a", "a"}, want: "(RoleARN: arn:aws:iam::123456789012:role/team/Admin)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, _ := newTestModel(t, options{}, profiles).Update(tea.WindowSizeMsg{Width: 200, Height: 30})
+			m := pressKeys(t, next.(model), tt.keys...)
+			if got := m.renderProfileList(); !strings.Contains(got, tt.want) {
+				t.Errorf("一覧に %q が含まれていません:\n%s", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package profileselector
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// maxHistoryEntries は選択履歴ファイルに保持する最大件数です。
+const maxHistoryEntries = 100
+
+// historyEntry はプロファイルの選択履歴の 1 件分です。
+type historyEntry struct {
+	Profile    string    `json:"profile"`    // 選択したプロファイル名
+	SelectedAt time.Time `json:"selectedAt"` // 選択した日時
+}
+
+// historyPath は選択履歴ファイルのパスを返します。
+func historyPath() (string, error) {
+	dir, err := toolConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "history.json"), nil
+}
+
+// loadHistory は選択履歴ファイルを読み込みます。古い順に並んだ履歴を返します。
+// ファイルが存在しない場合は空の履歴を返します。
+func loadHistory(path string) ([]historyEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("選択履歴の読み込みに失敗しました: %w (ファイル: %s)", err, path)
+	}
+
+	var history []historyEntry
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("選択履歴の解析に失敗しました: %w (ファイル: %s)", err, path)
+	}
+	return history, nil
+}
+
+// recordHistory は選択履歴ファイルにプロファイルの選択を追記します。
+// 履歴が maxHistoryEntries 件を超えた場合は古いものから削除します。
+func recordHistory(path, profileName string, now time.Time) error {
+	history, err := loadHistory(path)
+	if err != nil {
+		return err
+	}
+
+	history = append(history, historyEntry{Profile: profileName, SelectedAt: now})
+	if len(history) > maxHistoryEntries {
+		history = history[len(history)-maxHistoryEntries:]
+	}
+
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return fmt.Errorf("選択履歴の変換に失敗しました: %w", err)
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("選択履歴の書き込みに失敗しました: %w", err)
+	}
+	return nil
+}
+
+// lastUsedTimes はプロファイル名ごとの最後に選択された日時を返します。
+func lastUsedTimes(history []historyEntry) map[string]time.Time {
+	lastUsed := make(map[string]time.Time, len(history))
+	for _, e := range history {
+		if e.SelectedAt.After(lastUsed[e.Profile]) {
+			lastUsed[e.Profile] = e.SelectedAt
+		}
+	}
+	return lastUsed
+}
@@ -0,0 +1,71 @@
+package profileselector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecordHistory(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sub", "history.json")
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := range maxHistoryEntries + 5 {
+		if err := recordHistory(path, "dev", base.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatalf("recordHistory() error = %v", err)
+		}
+	}
+
+	history, err := loadHistory(path)
+	if err != nil {
+		t.Fatalf("loadHistory() error = %v", err)
+	}
+	if len(history) != maxHistoryEntries {
+		t.Errorf("履歴の件数 = %d, want %d", len(history), maxHistoryEntries)
+	}
+	if want := base.Add(5 * time.Minute); !history[0].SelectedAt.Equal(want) {
+		t.Errorf("最も古い履歴 = %v, want %v", history[0].SelectedAt, want)
+	}
+	assertNoTempFiles(t, filepath.Dir(path))
+}
+
+func TestLastUsedTimes(t *testing.T) {
+	t1 := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+	history := []historyEntry{
+		{Profile: "dev", SelectedAt: t2},
+		{Profile: "prod", SelectedAt: t1},
+		{Profile: "dev", SelectedAt: t1},
+	}
+	got := lastUsedTimes(history)
+	tests := []struct {
+		profile string
+		want    time.Time
+	}{
+		{profile: "dev", want: t2},
+		{profile: "prod", want: t1},
+		{profile: "staging", want: time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.profile, func(t *testing.T) {
+			if !got[tt.profile].Equal(tt.want) {
+				t.Errorf("lastUsedTimes()[%q] = %v, want %v", tt.profile, got[tt.profile], tt.want)
+			}
+		})
+	}
+}
+
+// assertNoTempFiles は writeFileAtomic の一時ファイルが dir に残っていないことを確認します。
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range entries {
+		if name := e.Name(); len(name) > 0 && name[0] == '.' {
+			t.Errorf("一時ファイル %s が残っています", name)
+		}
+	}
+}
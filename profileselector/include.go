@@ -0,0 +1,137 @@
+package profileselector
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// includeSectionName は他の設定ファイルを読み込むためのセクション名です。
+// セクション内の各キーの値に、読み込む設定ファイルのパスを記述します。
+//
+//	[include]
+//	team = ~/.aws/team-config
+const includeSectionName = "include"
+
+// expandHome はパス先頭の "~" をユーザーのホームディレクトリに展開します。
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := userHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~")), nil
+}
+
+// loadConfigWithIncludes は root の設定ファイルを読み込み、[include] セクションで指定された設定ファイルを再帰的に取り込みます。
+// 同じ名前のセクションは先に定義されたものが優先されます。
+// loadOpts は root と include される全ての設定ファイルの読み込みに使用します。
+// visited には読み込み中の設定ファイルの絶対パスが入り、循環した include を検出するために使用します。
+func loadConfigWithIncludes(root string, loadOpts ini.LoadOptions, visited map[string]bool) (*ini.File, error) {
+	return loadConfigFileWithIncludes(root, loadOpts, visited, false)
+}
+
+// loadConfigFileWithIncludes は path の設定ファイルを読み込み、[include] セクションで指定された設定ファイルを再帰的に取り込みます。
+// included が true の場合は include された設定ファイルとして、読み込みのエラーにファイルのパスを付けます。
+// 最上位の設定ファイルのエラーは呼び出し元でファイル名を付けて報告するため、そのまま返します。
+func loadConfigFileWithIncludes(path string, loadOpts ini.LoadOptions, visited map[string]bool, included bool) (*ini.File, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("設定ファイルのパスの解決に失敗しました: %w (ファイル: %s)", err, path)
+	}
+	if visited[absPath] {
+		return nil, fmt.Errorf("設定ファイルの include が循環しています: %s", absPath)
+	}
+	visited[absPath] = true
+	defer delete(visited, absPath)
+
+	cfg, err := ini.LoadSources(loadOpts, absPath)
+	if err != nil {
+		if included {
+			return nil, fmt.Errorf("include された設定ファイルの読み込みに失敗しました: %w (ファイル: %s)", err, absPath)
+		}
+		return nil, err
+	}
+	if err := includeConfigs(cfg, filepath.Dir(absPath), loadOpts, visited); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// loadConfigDataWithIncludes は読み込み済みの設定 data を解析し、[include] セクションで指定された設定ファイルを再帰的に取り込みます。
+// 相対パスの include は baseDir を基準に解決します。
+func loadConfigDataWithIncludes(data []byte, baseDir string, loadOpts ini.LoadOptions) (*ini.File, error) {
+	cfg, err := ini.LoadSources(loadOpts, data)
+	if err != nil {
+		return nil, err
+	}
+	if err := includeConfigs(cfg, baseDir, loadOpts, map[string]bool{}); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// includeConfigs は cfg の [include] セクションで指定された設定ファイルを読み込み、cfg に取り込みます。
+func includeConfigs(cfg *ini.File, baseDir string, loadOpts ini.LoadOptions, visited map[string]bool) error {
+	includeSection, err := cfg.GetSection(includeSectionName)
+	if err != nil {
+		return nil
+	}
+
+	for _, key := range includeSection.Keys() {
+		includePath, err := expandHome(strings.TrimSpace(key.Value()))
+		if err != nil {
+			return err
+		}
+		if !filepath.IsAbs(includePath) {
+			includePath = filepath.Join(baseDir, includePath)
+		}
+
+		included, err := loadConfigFileWithIncludes(includePath, loadOpts, visited, true)
+		if err != nil {
+			return err
+		}
+		if err := mergeSections(cfg, included); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mergeSections は src のセクションのうち、dst に存在しないものを dst に追加します。
+// DEFAULT セクションは dst に存在しないキーだけを追加します。
+func mergeSections(dst, src *ini.File) error {
+	for _, srcSection := range src.Sections() {
+		name := srcSection.Name()
+		if name == includeSectionName {
+			continue
+		}
+
+		var dstSection *ini.Section
+		if name == ini.DefaultSection {
+			dstSection = dst.Section(ini.DefaultSection)
+		} else {
+			if dst.HasSection(name) {
+				continue
+			}
+			var err error
+			if dstSection, err = dst.NewSection(name); err != nil {
+				return fmt.Errorf("セクションの作成に失敗しました: %w (セクション: %s)", err, name)
+			}
+		}
+
+		for _, key := range srcSection.Keys() {
+			if dstSection.HasKey(key.Name()) {
+				continue
+			}
+			if _, err := dstSection.NewKey(key.Name(), key.Value()); err != nil {
+				return fmt.Errorf("キーの設定に失敗しました: %w (セクション: %s, キー: %s)", err, name, key.Name())
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package profileselector
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/ini.v1"
+)
+
+func TestLoadConfigWithIncludes(t *testing.T) {
+	tests := []struct {
+		name        string
+		files       map[string]string // ディレクトリからの相対パスと内容 ("config" が最上位の設定ファイル)
+		wantRegions map[string]string // セクション名と region の値
+		wantErr     bool
+	}{
+		{
+			name: "相対パスの include",
+			files: map[string]string{
+				"config":      "[profile dev]\nregion = ap-northeast-1\n\n[include]\nteam = team/config\n",
+				"team/config": "[profile team]\nregion = us-east-1\n",
+			},
+			wantRegions: map[string]string{"profile dev": "ap-northeast-1", "profile team": "us-east-1"},
+		},
+		{
+			name: "入れ子の include は include したファイルのディレクトリを基準にする",
+			files: map[string]string{
+				"config":     "[include]\na = a/config\n",
+				"a/config":   "[profile a]\nregion = eu-west-1\n\n[include]\nb = b/config\n",
+				"a/b/config": "[profile b]\nregion = us-west-2\n",
+				"b/config":   "[profile wrong]\nregion = us-east-1\n",
+			},
+			wantRegions: map[string]string{"profile a": "eu-west-1", "profile b": "us-west-2"},
+		},
+		{
+			name: "同じ名前のプロファイルは先に定義されたものを使う",
+			files: map[string]string{
+				"config": "[profile dev]\nregion = ap-northeast-1\n\n[include]\nother = other\n",
+				"other":  "[profile dev]\nregion = us-east-1\n\n[profile prod]\nregion = us-east-1\n",
+			},
+			wantRegions: map[string]string{"profile dev": "ap-northeast-1", "profile prod": "us-east-1"},
+		},
+		{
+			name: "循環した include",
+			files: map[string]string{
+				"config": "[include]\na = a\n",
+				"a":      "[include]\nb = b\n",
+				"b":      "[include]\nconfig = config\n",
+			},
+			wantErr: true,
+		},
+		{
+			name:    "自分自身の include",
+			files:   map[string]string{"config": "[include]\nself = config\n"},
+			wantErr: true,
+		},
+		{
+			name:    "存在しないファイルの include",
+			files:   map[string]string{"config": "[include]\nmissing = missing\n"},
+			wantErr: true,
+		},
+		{
+			name: "同じファイルを 2 回 include しても循環ではない",
+			files: map[string]string{
+				"config": "[include]\na = shared\nb = shared\n",
+				"shared": "[profile shared]\nregion = us-east-1\n",
+			},
+			wantRegions: map[string]string{"profile shared": "us-east-1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				path := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cfg, err := loadConfigWithIncludes(filepath.Join(dir, "config"), ini.LoadOptions{}, map[string]bool{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadConfigWithIncludes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			regions := map[string]string{}
+			for _, section := range cfg.Sections() {
+				if section.HasKey("region") {
+					regions[section.Name()] = section.Key("region").String()
+				}
+			}
+			if !reflect.DeepEqual(regions, tt.wantRegions) {
+				t.Errorf("region = %v, want %v", regions, tt.wantRegions)
+			}
+		})
+	}
+}
+
+func TestIncludeErrorNamesIncludedFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string // ディレクトリからの相対パスと内容 ("config" が最上位の設定ファイル)
+		wantPath string            // エラーに含まれるべき include されたファイル
+	}{
+		{
+			name:     "1 段目の include が存在しない",
+			files:    map[string]string{"config": "[include]\nmissing = missing\n"},
+			wantPath: "missing",
+		},
+		{
+			name: "1 段目の include が不正",
+			files: map[string]string{
+				"config": "[include]\nbroken = broken\n",
+				"broken": "[profile broken\n",
+			},
+			wantPath: "broken",
+		},
+		{
+			name: "2 段目の include が不正",
+			files: map[string]string{
+				"config":   "[include]\na = a/config\n",
+				"a/config": "[include]\nbroken = broken\n",
+				"a/broken": "[profile broken\n",
+			},
+			wantPath: "a/broken",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				path := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			data, err := os.ReadFile(filepath.Join(dir, "config"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = loadConfigDataWithIncludes(data, dir, configLoadOptions)
+			if err == nil {
+				t.Fatal("loadConfigDataWithIncludes() error = nil, want error")
+			}
+			if want := filepath.Join(dir, tt.wantPath); !strings.Contains(err.Error(), want) {
+				t.Errorf("loadConfigDataWithIncludes() error = %v, want it to name %s", err, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package profileselector
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// keyAction はキーバインドで実行できる操作の種類です。
+type keyAction string
+
+const (
+	actionUp            keyAction = "up"
+	actionDown          keyAction = "down"
+	actionSelect        keyAction = "select"
+	actionQuit          keyAction = "quit"
+	actionToggleDetail  keyAction = "toggleDetail"
+	actionEdit          keyAction = "edit"
+	actionReload        keyAction = "reload"
+	actionSwitchSource  keyAction = "switchSource"
+	actionLeft          keyAction = "left"
+	actionRight         keyAction = "right"
+	actionToggleColumns keyAction = "toggleColumns"
+	actionCopy          keyAction = "copy"
+	actionNote          keyAction = "note"
+	actionNextTab       keyAction = "nextTab"
+	actionPrevTab       keyAction = "prevTab"
+	actionTop           keyAction = "top"
+	actionBottom        keyAction = "bottom"
+	actionDiff          keyAction = "diff"
+	actionCycleRecent   keyAction = "cycleRecent"
+	actionSearch        keyAction = "search"
+	actionHelp          keyAction = "help"
+	actionShortRoleArn  keyAction = "shortRoleArn"
+)
+
+// keyActions は全ての操作の一覧です。キーの重複を調べるときにこの順に操作を見ます。
+var keyActions = []keyAction{
+	actionUp, actionDown, actionSelect, actionQuit, actionToggleDetail, actionEdit, actionReload, actionSwitchSource,
+	actionLeft, actionRight, actionToggleColumns, actionCopy, actionNote, actionNextTab, actionPrevTab, actionTop,
+	actionBottom, actionDiff, actionCycleRecent, actionSearch, actionHelp, actionShortRoleArn,
+}
+
+// KeyMap は操作ごとに割り当てられたキー名の一覧を保持します。
+// キー名は tea.KeyMsg.String() の戻り値 ("up", "ctrl+c" など) で指定します。
+type KeyMap struct {
+	Up            []string `json:"up"`
+	Down          []string `json:"down"`
+	Select        []string `json:"select"`
+	Quit          []string `json:"quit"`
+	ToggleDetail  []string `json:"toggleDetail"`
+	Edit          []string `json:"edit"`
+	Reload        []string `json:"reload"`
+	SwitchSource  []string `json:"switchSource"`
+	Left          []string `json:"left"`
+	Right         []string `json:"right"`
+	ToggleColumns []string `json:"toggleColumns"`
+	Copy          []string `json:"copy"`
+	Note          []string `json:"note"`
+	NextTab       []string `json:"nextTab"`
+	PrevTab       []string `json:"prevTab"`
+	Top           []string `json:"top"` // 2 回続けて押すと先頭に移動する
+	Bottom        []string `json:"bottom"`
+	Diff          []string `json:"diff"`
+	CycleRecent   []string `json:"cycleRecent"` // 続けて押すと最近選択したプロファイルを順にたどる
+	Search        []string `json:"search"`
+	Help          []string `json:"help"` // --compact の指定時にフッターの表示を切り替える
+	ShortRoleArn  []string `json:"shortRoleArn"`
+}
+
+// defaultKeyMap はデフォルトのキーバインドを返します。
+func defaultKeyMap() KeyMap {
+	return KeyMap{
+		Up:            []string{"up", "k"},
+		Down:          []string{"down", "j"},
+		Select:        []string{"enter"},
+		Quit:          []string{"q", "ctrl+c"},
+		ToggleDetail:  []string{"v"},
+		Edit:          []string{"e"},
+		Reload:        []string{"r"},
+		SwitchSource:  []string{"s"},
+		Left:          []string{"left", "h"},
+		Right:         []string{"right", "l"},
+		ToggleColumns: []string{"c"},
+		Copy:          []string{"ctrl+y"},
+		Note:          []string{"n"},
+		NextTab:       []string{"tab"},
+		PrevTab:       []string{"shift+tab"},
+		Top:           []string{"g"},
+		Bottom:        []string{"G"},
+		Diff:          []string{"D"},
+		CycleRecent:   []string{"ctrl+r"},
+		Search:        []string{"/"},
+		Help:          []string{"?"},
+		ShortRoleArn:  []string{"a"},
+	}
+}
+
+// keyMapPath はキーバインド設定ファイルのパスを返します。
+func keyMapPath() (string, error) {
+	dir, err := toolConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "keys.json"), nil
+}
+
+// loadKeyMap はキーバインド設定ファイルを読み込みます。
+// ファイルに記述されていない操作はデフォルトのキーのままになります。
+// ファイルが存在しない場合はデフォルトを、読み込みや解析に失敗した場合はデフォルトとエラーを返します。
+// ファイルで割り当てたキーがデフォルトで他の操作に割り当てられている場合は、ファイルの割り当てを優先してデフォルトの割り当てから外し、
+// キーの重複を知らせるエラーをキーバインドと共に返します (switchSource のデフォルトが Tab から s に変わったときの設定ファイルなど)。
+func loadKeyMap(path string) (KeyMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return defaultKeyMap(), nil
+		}
+		return defaultKeyMap(), fmt.Errorf("キーバインド設定の読み込みに失敗しました: %w (ファイル: %s)", err, path)
+	}
+
+	km := defaultKeyMap()
+	var configured map[keyAction]json.RawMessage
+	if err := json.Unmarshal(data, &km); err != nil {
+		return defaultKeyMap(), fmt.Errorf("キーバインド設定の解析に失敗しました: %w (ファイル: %s)", err, path)
+	}
+	if err := json.Unmarshal(data, &configured); err != nil {
+		return defaultKeyMap(), fmt.Errorf("キーバインド設定の解析に失敗しました: %w (ファイル: %s)", err, path)
+	}
+	if conflicts := km.resolveConflicts(configured); len(conflicts) > 0 {
+		return km, fmt.Errorf("キーバインド設定のキーが重複しています: %s (ファイル: %s)", strings.Join(conflicts, ", "), path)
+	}
+	return km, nil
+}
+
+// resolveConflicts は複数の操作に割り当てられたキーを探し、重複の説明を返します。
+// configured に含まれる (設定ファイルで割り当てた) 操作のキーは、デフォルトのままの操作の割り当てから外します。
+func (km *KeyMap) resolveConflicts(configured map[keyAction]json.RawMessage) []string {
+	var conflicts []string
+	owners := make(map[string]keyAction)
+	// 設定ファイルで割り当てた操作のキーを先に登録し、デフォルトの割り当てより優先する
+	for _, pass := range []bool{true, false} {
+		for _, action := range keyActions {
+			if _, ok := configured[action]; ok != pass {
+				continue
+			}
+			binding := km.binding(action)
+			kept := make([]string, 0, len(*binding))
+			for _, key := range *binding {
+				owner, taken := owners[key]
+				switch {
+				case !taken:
+					owners[key] = action
+				case owner == action:
+				case !pass:
+					conflicts = append(conflicts, fmt.Sprintf("%s は %s に割り当てたため %s のデフォルトから外しました", displayKeyName(key), owner, action))
+					continue
+				default:
+					conflicts = append(conflicts, fmt.Sprintf("%s が %s と %s に割り当てられています", displayKeyName(key), owner, action))
+				}
+				kept = append(kept, key)
+			}
+			*binding = kept
+		}
+	}
+	return conflicts
+}
+
+// binding は操作に割り当てられたキー名の一覧を指すポインタを返します。未知の操作の場合は nil を返します。
+func (km *KeyMap) binding(action keyAction) *[]string {
+	switch action {
+	case actionUp:
+		return &km.Up
+	case actionDown:
+		return &km.Down
+	case actionSelect:
+		return &km.Select
+	case actionQuit:
+		return &km.Quit
+	case actionToggleDetail:
+		return &km.ToggleDetail
+	case actionEdit:
+		return &km.Edit
+	case actionReload:
+		return &km.Reload
+	case actionSwitchSource:
+		return &km.SwitchSource
+	case actionLeft:
+		return &km.Left
+	case actionRight:
+		return &km.Right
+	case actionToggleColumns:
+		return &km.ToggleColumns
+	case actionCopy:
+		return &km.Copy
+	case actionNote:
+		return &km.Note
+	case actionNextTab:
+		return &km.NextTab
+	case actionPrevTab:
+		return &km.PrevTab
+	case actionTop:
+		return &km.Top
+	case actionBottom:
+		return &km.Bottom
+	case actionDiff:
+		return &km.Diff
+	case actionCycleRecent:
+		return &km.CycleRecent
+	case actionSearch:
+		return &km.Search
+	case actionHelp:
+		return &km.Help
+	case actionShortRoleArn:
+		return &km.ShortRoleArn
+	}
+	return nil
+}
+
+// keys は操作に割り当てられたキー名の一覧を返します。
+func (km KeyMap) keys(action keyAction) []string {
+	if binding := km.binding(action); binding != nil {
+		return *binding
+	}
+	return nil
+}
+
+// Matches は key が action に割り当てられているかどうかを返します。
+func (km KeyMap) Matches(action keyAction, key string) bool {
+	for _, k := range km.keys(action) {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// help はヘルプテキスト用に、操作に割り当てられたキーを "↑/k" のような表示形式で返します。
+func (km KeyMap) help(action keyAction) string {
+	keys := km.keys(action)
+	names := make([]string, 0, len(keys))
+	for _, k := range keys {
+		names = append(names, displayKeyName(k))
+	}
+	return strings.Join(names, "/")
+}
+
+// displayKeyName はキー名をヘルプテキスト用の表示形式に変換します。
+func displayKeyName(key string) string {
+	switch key {
+	case "up":
+		return "↑"
+	case "down":
+		return "↓"
+	case "left":
+		return "←"
+	case "right":
+		return "→"
+	case "enter":
+		return "Enter"
+	case "tab":
+		return "Tab"
+	case "shift+tab":
+		return "Shift+Tab"
+	case "esc":
+		return "Esc"
+	case " ":
+		return "Space"
+	}
+	if rest, ok := strings.CutPrefix(key, "ctrl+"); ok {
+		return "Ctrl+" + strings.ToUpper(rest)
+	}
+	return key
+}
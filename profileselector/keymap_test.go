@@ -0,0 +1,155 @@
+package profileselector
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadKeyMap(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string // 空の場合はファイルを作成しない
+		wantErr  bool
+		wantDown []string
+		wantUp   []string
+	}{
+		{name: "ファイルなし", wantDown: []string{"down", "j"}, wantUp: []string{"up", "k"}},
+		{name: "1 つの操作だけ上書き", content: `{"down": ["x"]}`, wantDown: []string{"x"}, wantUp: []string{"up", "k"}},
+		{name: "JSON として不正", content: `{"down": `, wantErr: true, wantDown: []string{"down", "j"}, wantUp: []string{"up", "k"}},
+		{name: "型が違う", content: `{"down": "x"}`, wantErr: true, wantDown: []string{"down", "j"}, wantUp: []string{"up", "k"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "keys.json")
+			if tt.content != "" {
+				if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			km, err := loadKeyMap(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadKeyMap() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(km.Down, tt.wantDown) {
+				t.Errorf("Down = %v, want %v", km.Down, tt.wantDown)
+			}
+			if !reflect.DeepEqual(km.Up, tt.wantUp) {
+				t.Errorf("Up = %v, want %v", km.Up, tt.wantUp)
+			}
+		})
+	}
+}
+
+func TestLoadKeyMapConflicts(t *testing.T) {
+	tests := []struct {
+		name             string
+		content          string
+		wantErr          bool
+		wantSwitchSource []string
+		wantNextTab      []string
+	}{
+		{name: "重複なし", content: `{"switchSource": ["S"]}`, wantSwitchSource: []string{"S"}, wantNextTab: []string{"tab"}},
+		{name: "デフォルトの割り当てと重複", content: `{"switchSource": ["tab"]}`, wantErr: true, wantSwitchSource: []string{"tab"}, wantNextTab: []string{}},
+		{name: "設定した操作どうしの重複", content: `{"switchSource": ["tab"], "nextTab": ["tab"]}`, wantErr: true, wantSwitchSource: []string{"tab"}, wantNextTab: []string{"tab"}},
+		{name: "重複を解消した設定", content: `{"switchSource": ["tab"], "nextTab": ["ctrl+t"]}`, wantSwitchSource: []string{"tab"}, wantNextTab: []string{"ctrl+t"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "keys.json")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			km, err := loadKeyMap(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadKeyMap() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(km.SwitchSource, tt.wantSwitchSource) {
+				t.Errorf("SwitchSource = %v, want %v", km.SwitchSource, tt.wantSwitchSource)
+			}
+			if !reflect.DeepEqual(km.NextTab, tt.wantNextTab) {
+				t.Errorf("NextTab = %v, want %v", km.NextTab, tt.wantNextTab)
+			}
+		})
+	}
+}
+
+func TestDefaultKeyMapHasNoConflicts(t *testing.T) {
+	km := defaultKeyMap()
+	if conflicts := km.resolveConflicts(nil); len(conflicts) > 0 {
+		t.Errorf("デフォルトのキーバインドが重複しています: %v", conflicts)
+	}
+}
+
+func TestKeyMapWarningShownAfterLoading(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	path := filepath.Join(dir, toolDirName, "keys.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"switchSource": ["tab"]}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m := initialModel(options{}, newStyleRenderer(io.Discard, true))
+	next, cmd := m.Update(profilesLoadedMsg{profiles: testProfiles("dev")})
+	m = next.(model)
+	if !strings.Contains(m.toast, "nextTab") {
+		t.Errorf("toast = %q, want a warning about nextTab", m.toast)
+	}
+	if cmd == nil {
+		t.Error("トーストを消すコマンドが返されませんでした")
+	}
+	if !m.keys.Matches(actionSwitchSource, "tab") || m.keys.Matches(actionNextTab, "tab") {
+		t.Errorf("Tab の割り当て: switchSource = %v, nextTab = %v", m.keys.SwitchSource, m.keys.NextTab)
+	}
+}
+
+func TestKeyMapMatches(t *testing.T) {
+	km := defaultKeyMap()
+	km.Down = []string{"x"}
+	tests := []struct {
+		action keyAction
+		key    string
+		want   bool
+	}{
+		{action: actionDown, key: "x", want: true},
+		{action: actionDown, key: "j", want: false},
+		{action: actionUp, key: "k", want: true},
+		{action: actionQuit, key: "ctrl+c", want: true},
+		{action: actionSelect, key: "x", want: false},
+		{action: keyAction("unknown"), key: "x", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.action)+"/"+tt.key, func(t *testing.T) {
+			if got := km.Matches(tt.action, tt.key); got != tt.want {
+				t.Errorf("Matches(%q, %q) = %v, want %v", tt.action, tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOverriddenKeyTriggersAction(t *testing.T) {
+	tests := []struct {
+		name       string
+		keys       []string
+		wantCursor int
+	}{
+		{name: "上書きしたキーで下に移動", keys: []string{"x"}, wantCursor: 1},
+		{name: "上書き前のキーは何もしない", keys: []string{"j"}, wantCursor: 0},
+		{name: "上書きしていない操作はデフォルトのまま", keys: []string{"x", "x", "k"}, wantCursor: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t, options{}, testProfiles("a", "b", "c"))
+			m.keys.Down = []string{"x"}
+			m = pressKeys(t, m, tt.keys...)
+			if m.cursor != tt.wantCursor {
+				t.Errorf("cursor = %d, want %d", m.cursor, tt.wantCursor)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package profileselector
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// lastUsedPath はプロファイル名ごとの最後に選択した日時を保存するファイルのパスを返します。
+// 選択履歴 (history.json) は件数に上限があるため、長く使っていないプロファイルの日時はこちらに残します。
+func lastUsedPath() (string, error) {
+	dir, err := toolConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "last_used.json"), nil
+}
+
+// loadLastUsed は path からプロファイル名ごとの最後に選択した日時を読み込みます。
+// ファイルが存在しない場合は空のマップを返します。
+func loadLastUsed(path string) (map[string]time.Time, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return map[string]time.Time{}, nil
+		}
+		return nil, fmt.Errorf("最終選択日時の読み込みに失敗しました: %w (ファイル: %s)", err, path)
+	}
+
+	lastUsed := map[string]time.Time{}
+	if err := json.Unmarshal(data, &lastUsed); err != nil {
+		return nil, fmt.Errorf("最終選択日時の解析に失敗しました: %w (ファイル: %s)", err, path)
+	}
+	return lastUsed, nil
+}
+
+// loadLastUsedOrEmpty は最終選択日時を読み込みます。読み込めなかった場合は空のマップを返します。
+func loadLastUsedOrEmpty() map[string]time.Time {
+	path, err := lastUsedPath()
+	if err != nil {
+		return map[string]time.Time{}
+	}
+	lastUsed, err := loadLastUsed(path)
+	if err != nil {
+		return map[string]time.Time{}
+	}
+	return lastUsed
+}
+
+// recordLastUsed は path のファイルで profileName の最終選択日時を now に更新します。
+// ファイルが壊れていて読み込めない場合は、他のプロファイルの日時を失わないよう書き込まずにエラーを返します。
+func recordLastUsed(path, profileName string, now time.Time) error {
+	lastUsed, err := loadLastUsed(path)
+	if err != nil {
+		return err
+	}
+	lastUsed[profileName] = now
+
+	data, err := json.MarshalIndent(lastUsed, "", "  ")
+	if err != nil {
+		return fmt.Errorf("最終選択日時の変換に失敗しました: %w", err)
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("最終選択日時の書き込みに失敗しました: %w", err)
+	}
+	return nil
+}
+
+// humanizeSince は t から now までの経過時間を「3時間前」「昨日」のような短い表現で返します。
+func humanizeSince(t, now time.Time) string {
+	d := now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return "たった今"
+	case d < time.Hour:
+		return fmt.Sprintf("%d分前", int(d/time.Minute))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%d時間前", int(d/time.Hour))
+	}
+
+	days := int(d / (24 * time.Hour))
+	switch {
+	case days == 1:
+		return "昨日"
+	case days < 30:
+		return fmt.Sprintf("%d日前", days)
+	case days < 365:
+		return fmt.Sprintf("%dか月前", days/30)
+	}
+	return fmt.Sprintf("%d年前", days/365)
+}
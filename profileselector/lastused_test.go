@@ -0,0 +1,114 @@
+package profileselector
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHumanizeSince(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		ago  time.Duration
+		want string
+	}{
+		{ago: 0, want: "たった今"},
+		{ago: 59 * time.Second, want: "たった今"},
+		{ago: 5 * time.Minute, want: "5分前"},
+		{ago: 2 * time.Hour, want: "2時間前"},
+		{ago: 23*time.Hour + 59*time.Minute, want: "23時間前"},
+		{ago: 30 * time.Hour, want: "昨日"},
+		{ago: 3 * 24 * time.Hour, want: "3日前"},
+		{ago: 65 * 24 * time.Hour, want: "2か月前"},
+		{ago: 800 * 24 * time.Hour, want: "2年前"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ago.String(), func(t *testing.T) {
+			if got := humanizeSince(now.Add(-tt.ago), now); got != tt.want {
+				t.Errorf("humanizeSince(%v 前) = %q, want %q", tt.ago, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordLastUsed(t *testing.T) {
+	first := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	second := first.Add(time.Hour)
+	tests := []struct {
+		name     string
+		existing string // 空の場合はファイルを作成しない
+		records  []string
+		want     map[string]time.Time
+		wantErr  bool
+	}{
+		{name: "新しく作成", records: []string{"dev"}, want: map[string]time.Time{"dev": first}},
+		{name: "同じプロファイルは更新", records: []string{"dev", "dev"}, want: map[string]time.Time{"dev": second}},
+		{name: "他のプロファイルの日時は残す", existing: `{"prod": "2023-01-01T00:00:00Z"}`, records: []string{"dev"}, want: map[string]time.Time{"dev": first, "prod": time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}},
+		{name: "壊れたファイルは上書きしない", existing: `{"prod": `, records: []string{"dev"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "last_used.json")
+			if tt.existing != "" {
+				if err := os.WriteFile(path, []byte(tt.existing), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for i, name := range tt.records {
+				err := recordLastUsed(path, name, first.Add(time.Duration(i)*time.Hour))
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("recordLastUsed() error = %v, wantErr %v", err, tt.wantErr)
+				}
+			}
+			if tt.wantErr {
+				if data, _ := os.ReadFile(path); string(data) != tt.existing {
+					t.Errorf("壊れたファイルが書き換えられました: %q", data)
+				}
+				return
+			}
+			got, err := loadLastUsed(path)
+			if err != nil {
+				t.Fatalf("loadLastUsed() error = %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("loadLastUsed() = %v, want %v", got, tt.want)
+			}
+			for name, want := range tt.want {
+				if !got[name].Equal(want) {
+					t.Errorf("%s の最終選択日時 = %v, want %v", name, got[name], want)
+				}
+			}
+			assertNoTempFiles(t, dir)
+		})
+	}
+}
+
+func TestRenderLastUsed(t *testing.T) {
+	tests := []struct {
+		name     string
+		lastUsed map[string]time.Time
+		want     string
+	}{
+		{name: "選択したことがない", lastUsed: map[string]time.Time{}},
+		{name: "2 時間前", lastUsed: map[string]time.Time{"dev": time.Now().Add(-2*time.Hour - time.Minute)}, want: "2時間前"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t, options{}, testProfiles("dev"))
+			m.lastUsed = tt.lastUsed
+			got := m.renderProfileList()
+			if tt.want == "" {
+				if strings.Contains(got, "前") || strings.Contains(got, "たった今") {
+					t.Errorf("選択したことのないプロファイルに経過時間が表示されています:\n%s", got)
+				}
+				return
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("一覧に %q が含まれていません:\n%s", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package profileselector
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+
+	"gopkg.in/ini.v1"
+)
+
+// defaultLoaderConcurrency は loadAWSProfilesConcurrent がセクションを処理するデフォルトのワーカーの数を返します。
+// 同時に実行できる CPU の数 (GOMAXPROCS) だけワーカーを使います。
+func defaultLoaderConcurrency() int {
+	return runtime.GOMAXPROCS(0)
+}
+
+// loaderConfig は loadAWSProfilesConcurrent の設定です。
+type loaderConfig struct {
+	concurrency int       // セクションを処理するワーカーの数
+	source      io.Reader // path のファイルの代わりに読み込む設定の内容 (nil の場合は path のファイルを読み込む)
+}
+
+// LoaderOption は loadAWSProfilesConcurrent の動作を変更するオプションです。
+type LoaderOption func(*loaderConfig)
+
+// WithConcurrency はセクションを処理するワーカーの数を指定します (1 未満の場合は 1)。
+func WithConcurrency(n int) LoaderOption {
+	return func(c *loaderConfig) {
+		c.concurrency = n
+	}
+}
+
+// withConfigSource は path のファイルの代わりに r から設定の内容を読み込みます。標準入力から渡された設定を読み込むときに使います。
+// path はエラーメッセージと、相対パスの include の基準にだけ使います。
+func withConfigSource(r io.Reader) LoaderOption {
+	return func(c *loaderConfig) {
+		c.source = r
+	}
+}
+
+// configSectionPriority は同じ名前のプロファイルを表すセクションが複数ある場合の優先度を返します。値が大きいほど優先します。
+// AWS CLI と同じく [profile default] を [default] より優先し、どのセクションにも属さない先頭のキーは最も低くします。
+func configSectionPriority(sectionName string) int {
+	switch {
+	case strings.HasPrefix(sectionName, "profile "):
+		return 2
+	case sectionName == "default":
+		return 1
+	default:
+		return 0
+	}
+}
+
+// configSectionProfile は設定ファイルのセクションからプロファイルを生成します。
+// プロファイルとして扱うのは [default]、認証情報のキーを持つセクション外のキー、"profile " で始まるセクションだけで、
+// [sso-session ...] や [services ...] などそれ以外のセクションの場合は false を返します。
+func configSectionProfile(section *ini.Section) (awsProfile, bool) {
+	sectionName := section.Name()
+	var profileName string
+
+	switch {
+	case sectionName == "default":
+		profileName = "default"
+	case sectionName == ini.DefaultSection:
+		if !section.HasKey("aws_access_key_id") && !section.HasKey("sso_session") && !section.HasKey("role_arn") {
+			return awsProfile{}, false
+		}
+		profileName = "default"
+	case strings.HasPrefix(sectionName, "profile "):
+		profileName = strings.TrimSpace(strings.TrimPrefix(sectionName, "profile "))
+	default:
+		return awsProfile{}, false
+	}
+
+	if strings.TrimSpace(profileName) == "" {
+		return awsProfile{}, false
+	}
+	return newAWSProfile(profileName, section.KeysHash(), sourceConfig), true
+}
+
+// loadAWSProfilesConcurrent は path の設定ファイルを 1 度だけ解析し、セクションごとのプロファイルの生成を
+// WithConcurrency で指定した数のワーカーで行います。結果は設定ファイルに記述された順に返します。
+// 相対パスの include は path のディレクトリを基準に解決します。
+// ファイルが存在しない場合は *ConfigNotFoundError を、解析できない場合は *ConfigParseError を返します。
+func loadAWSProfilesConcurrent(path string, opts ...LoaderOption) ([]awsProfile, error) {
+	conf := loaderConfig{concurrency: defaultLoaderConcurrency()}
+	for _, opt := range opts {
+		opt(&conf)
+	}
+	if conf.concurrency < 1 {
+		conf.concurrency = 1
+	}
+
+	data, err := readConfigSource(path, conf.source)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := loadConfigDataWithIncludes(data, configBaseDir(path), configLoadOptions)
+	if err != nil {
+		return nil, &ConfigParseError{Path: path, Cause: err}
+	}
+
+	sections := cfg.Sections()
+	results := buildSectionProfiles(sections, conf.concurrency)
+
+	var profiles []awsProfile
+	seen := make(map[string]int)       // プロファイル名ごとの profiles 内の位置
+	priorities := make(map[string]int) // プロファイル名ごとの採用したセクションの優先度
+	for i, p := range results {
+		if p == nil {
+			continue
+		}
+		// 同じ名前のプロファイルは、[profile default] と [default] のようにセクションの優先度が高いもの、
+		// 同じ優先度なら先に定義されたものを採用し、一覧の位置は最初に定義された位置のままにする
+		priority := configSectionPriority(sections[i].Name())
+		if j, ok := seen[p.Name]; ok {
+			if priority > priorities[p.Name] {
+				profiles[j] = *p
+				priorities[p.Name] = priority
+			}
+			continue
+		}
+		seen[p.Name] = len(profiles)
+		priorities[p.Name] = priority
+		profiles = append(profiles, *p)
+	}
+
+	markCustomEndpoints(profiles, serviceEndpoints(cfg))
+	return profiles, nil
+}
+
+// readConfigSource は設定ファイルの内容を読み込みます。source が指定されていればそこから、なければ path のファイルから読み込みます。
+func readConfigSource(path string, source io.Reader) ([]byte, error) {
+	if source != nil {
+		data, err := io.ReadAll(source)
+		if err != nil {
+			return nil, fmt.Errorf("設定の読み込みに失敗しました: %w (読み込み元: %s)", err, path)
+		}
+		return data, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, &ConfigNotFoundError{Path: path}
+	}
+	if err != nil {
+		return nil, readFileError("設定ファイル", path, err)
+	}
+	return data, nil
+}
+
+// buildSectionProfiles は sections のそれぞれから configSectionProfile でプロファイルを生成し、セクションと同じ順に返します。
+// プロファイルにならないセクションの位置は nil です。
+// workers が 2 以上の場合は、その数のゴルーチンでセクションを分担して処理します。
+func buildSectionProfiles(sections []*ini.Section, workers int) []*awsProfile {
+	results := make([]*awsProfile, len(sections))
+	build := func(i int) {
+		if p, ok := configSectionProfile(sections[i]); ok {
+			results[i] = &p
+		}
+	}
+	if workers <= 1 {
+		for i := range sections {
+			build(i)
+		}
+		return results
+	}
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, len(sections)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				build(i)
+			}
+		}()
+	}
+	for i := range sections {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,255 @@
+package profileselector
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"gopkg.in/ini.v1"
+)
+
+// writeSyntheticConfig は n 個のプロファイルと services セクションを持つ設定ファイルを dir に書き込み、そのパスを返します。
+func writeSyntheticConfig(t testing.TB, dir string, n int) string {
+	t.Helper()
+	var b strings.Builder
+	b.WriteString("[default]\nregion = us-east-1\n\n")
+	b.WriteString("[services local]\ns3 =\n  endpoint_url = http://localhost:4566\n\n")
+	for i := range n {
+		fmt.Fprintf(&b, "[profile p%04d]\n", i)
+		fmt.Fprintf(&b, "role_arn = arn:aws:iam::%012d:role/Role%d\n", i, i)
+		b.WriteString("source_profile = default\nregion = ap-northeast-1\n")
+		if i%10 == 0 {
+			b.WriteString("services = local\n")
+		}
+		b.WriteString("\n")
+	}
+	path := filepath.Join(dir, "config")
+	if err := os.WriteFile(path, []byte(b.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadAWSProfilesConcurrentMatchesSequential(t *testing.T) {
+	path := writeSyntheticConfig(t, t.TempDir(), 1000)
+	sequential, err := loadAWSProfilesConcurrent(path, WithConcurrency(1))
+	if err != nil {
+		t.Fatalf("loadAWSProfilesConcurrent() error = %v", err)
+	}
+	if len(sequential) != 1001 {
+		t.Fatalf("プロファイル数 = %d, want 1001", len(sequential))
+	}
+	tests := []struct {
+		name string
+		opts []LoaderOption
+	}{
+		{name: "デフォルトのワーカー数", opts: nil},
+		{name: "workers=0", opts: []LoaderOption{WithConcurrency(0)}},
+		{name: "workers=2", opts: []LoaderOption{WithConcurrency(2)}},
+		{name: "workers=8", opts: []LoaderOption{WithConcurrency(8)}},
+		{name: "workers=64", opts: []LoaderOption{WithConcurrency(64)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			concurrent, err := loadAWSProfilesConcurrent(path, tt.opts...)
+			if err != nil {
+				t.Fatalf("loadAWSProfilesConcurrent() error = %v", err)
+			}
+			if !reflect.DeepEqual(concurrent, sequential) {
+				t.Error("並行して読み込んだ結果が順に読み込んだ結果と一致しません")
+			}
+		})
+	}
+}
+
+func TestDefaultLoaderConcurrency(t *testing.T) {
+	for _, procs := range []int{1, 4} {
+		t.Run(fmt.Sprintf("GOMAXPROCS=%d", procs), func(t *testing.T) {
+			defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(procs))
+			if got := defaultLoaderConcurrency(); got != procs {
+				t.Errorf("defaultLoaderConcurrency() = %d, want %d", got, procs)
+			}
+		})
+	}
+}
+
+func TestLoadAWSProfilesConcurrent(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     string
+		wantNames  []string
+		wantRegion map[string]string
+		wantCustom map[string]bool
+	}{
+		{
+			name:      "記述順に読み込む",
+			config:    "[profile b]\n[default]\n[profile a]\n[sso-session s]\nsso_region = us-east-1\n",
+			wantNames: []string{"b", "default", "a"},
+		},
+		{
+			name:       "[profile default] を [default] より優先する",
+			config:     "[default]\nregion = us-east-1\n\n[profile default]\nregion = ap-northeast-1\n",
+			wantNames:  []string{"default"},
+			wantRegion: map[string]string{"default": "ap-northeast-1"},
+		},
+		{
+			name:       "services セクションのエンドポイント",
+			config:     "[profile local]\nservices = dev\n\n[profile remote]\n\n[services dev]\ns3 =\n  endpoint_url = http://localhost:4566\n",
+			wantNames:  []string{"local", "remote"},
+			wantCustom: map[string]bool{"local": true, "remote": false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profiles, err := loadAWSProfilesConcurrent("config", withConfigSource(strings.NewReader(tt.config)))
+			if err != nil {
+				t.Fatalf("loadAWSProfilesConcurrent() error = %v", err)
+			}
+			if got := profileNames(profiles); !reflect.DeepEqual(got, tt.wantNames) {
+				t.Errorf("プロファイル名 = %v, want %v", got, tt.wantNames)
+			}
+			byName := profilesByName(profiles)
+			for name, want := range tt.wantRegion {
+				if got := byName[name].Region; got != want {
+					t.Errorf("%s の region = %q, want %q", name, got, want)
+				}
+			}
+			for name, want := range tt.wantCustom {
+				if got := byName[name].CustomEndpoint; got != want {
+					t.Errorf("%s の CustomEndpoint = %v, want %v", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadAWSProfilesConcurrentErrors(t *testing.T) {
+	dir := t.TempDir()
+	_, err := loadAWSProfilesConcurrent(filepath.Join(dir, "missing"))
+	var notFound *ConfigNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Errorf("存在しないファイルのエラー = %v, want *ConfigNotFoundError", err)
+	}
+
+	_, err = loadAWSProfilesConcurrent("config", withConfigSource(strings.NewReader("[profile broken\n")))
+	var parseErr *ConfigParseError
+	if !errors.As(err, &parseErr) {
+		t.Errorf("解析できない設定のエラー = %v, want *ConfigParseError", err)
+	}
+}
+
+func BenchmarkLoadAWSProfilesConcurrent(b *testing.B) {
+	path := writeSyntheticConfig(b, b.TempDir(), 1000)
+	for _, workers := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for range b.N {
+				if _, err := loadAWSProfilesConcurrent(path, WithConcurrency(workers)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkBuildSectionProfiles(b *testing.B) {
+	path := writeSyntheticConfig(b, b.TempDir(), 1000)
+	cfg, err := loadConfigWithIncludes(path, ini.LoadOptions{AllowPythonMultilineValues: true}, map[string]bool{})
+	if err != nil {
+		b.Fatal(err)
+	}
+	sections := cfg.Sections()
+	for _, workers := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			for range b.N {
+				buildSectionProfiles(sections, workers)
+			}
+		})
+	}
+}
+
+func TestConfigSectionProfileSkipsNonProfileSections(t *testing.T) {
+	tests := []struct {
+		section  string
+		wantName string
+		wantOK   bool
+	}{
+		{section: "profile dev", wantName: "dev", wantOK: true},
+		{section: "default", wantName: "default", wantOK: true},
+		{section: "sso-session corp"},
+		{section: "services local"},
+		{section: "preview"},
+		{section: "plugins"},
+		{section: "profile "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.section, func(t *testing.T) {
+			cfg := ini.Empty()
+			section, err := cfg.NewSection(tt.section)
+			if err != nil {
+				t.Fatal(err)
+			}
+			section.Key("region").SetValue("us-east-1")
+			p, ok := configSectionProfile(section)
+			if ok != tt.wantOK {
+				t.Fatalf("configSectionProfile([%s]) ok = %v, want %v", tt.section, ok, tt.wantOK)
+			}
+			if p.Name != tt.wantName {
+				t.Errorf("configSectionProfile([%s]) の名前 = %q, want %q", tt.section, p.Name, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestLoadAWSProfilesSSOFixture(t *testing.T) {
+	profiles, err := loadAWSProfilesConcurrent(filepath.Join("testdata", "sso_config.ini"))
+	if err != nil {
+		t.Fatalf("loadAWSProfilesConcurrent() error = %v", err)
+	}
+	// [sso-session ...]、[services ...] と profile の付かないセクションはプロファイルとして扱わない
+	if got, want := profileNames(profiles), []string{"default", "dev", "prod"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("プロファイル名 = %v, want %v", got, want)
+	}
+}
+
+func TestDefaultProfilePrecedence(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     string
+		wantNames  []string
+		wantRegion string
+	}{
+		{name: "[default] だけ", config: "[default]\nregion = us-east-1\n", wantNames: []string{"default"}, wantRegion: "us-east-1"},
+		{name: "[profile default] だけ", config: "[profile default]\nregion = ap-northeast-1\n", wantNames: []string{"default"}, wantRegion: "ap-northeast-1"},
+		{
+			name:       "[profile default] が後にある",
+			config:     "[default]\nregion = us-east-1\n\n[profile dev]\n\n[profile default]\nregion = ap-northeast-1\n",
+			wantNames:  []string{"default", "dev"},
+			wantRegion: "ap-northeast-1",
+		},
+		{
+			name:       "[profile default] が先にある",
+			config:     "[profile default]\nregion = ap-northeast-1\n\n[profile dev]\n\n[default]\nregion = us-east-1\n",
+			wantNames:  []string{"default", "dev"},
+			wantRegion: "ap-northeast-1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profiles, err := loadAWSProfilesConcurrent("config", withConfigSource(strings.NewReader(tt.config)))
+			if err != nil {
+				t.Fatalf("loadAWSProfilesConcurrent() error = %v", err)
+			}
+			if got := profileNames(profiles); !reflect.DeepEqual(got, tt.wantNames) {
+				t.Errorf("プロファイル名 = %v, want %v", got, tt.wantNames)
+			}
+			if got := profilesByName(profiles)["default"].Region; got != tt.wantRegion {
+				t.Errorf("default の region = %q, want %q", got, tt.wantRegion)
+			}
+		})
+	}
+}
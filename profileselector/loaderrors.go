@@ -0,0 +1,75 @@
+package profileselector
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/user"
+	"strings"
+)
+
+// ConfigNotFoundError は設定ファイルが存在しないときに loadAWSProfiles が返すエラーです。
+type ConfigNotFoundError struct {
+	Path string // 存在しなかった設定ファイルのパス
+}
+
+func (e *ConfigNotFoundError) Error() string {
+	return fmt.Sprintf("設定ファイルが見つかりません (ファイル: %s)", e.Path)
+}
+
+// Unwrap は errors.Is(err, fs.ErrNotExist) でも判定できるように fs.ErrNotExist を返します。
+func (e *ConfigNotFoundError) Unwrap() error {
+	return fs.ErrNotExist
+}
+
+// ConfigParseError は設定ファイルを INI として解析できなかったときに loadAWSProfiles が返すエラーです。
+type ConfigParseError struct {
+	Path  string // 解析に失敗した設定ファイルのパス (標準入力の場合は stdinConfigName)
+	Cause error  // 解析のエラー
+}
+
+func (e *ConfigParseError) Error() string {
+	return fmt.Sprintf("設定ファイル %s の読み込みに失敗しました: %v", e.Path, e.Cause)
+}
+
+func (e *ConfigParseError) Unwrap() error {
+	return e.Cause
+}
+
+// UserHomeDirError はホームディレクトリを取得できなかったときのエラーです。
+type UserHomeDirError struct {
+	Cause error // ユーザー情報の取得のエラー
+}
+
+func (e *UserHomeDirError) Error() string {
+	return fmt.Sprintf("ユーザーホームディレクトリの取得に失敗しました: %v", e.Cause)
+}
+
+func (e *UserHomeDirError) Unwrap() error {
+	return e.Cause
+}
+
+// userHomeDir は現在のユーザーのホームディレクトリを返します。取得できなかった場合は *UserHomeDirError を返します。
+func userHomeDir() (string, error) {
+	usr, err := user.Current()
+	if err != nil {
+		return "", &UserHomeDirError{Cause: err}
+	}
+	return usr.HomeDir, nil
+}
+
+// describeLoadError はプロファイルの読み込みのエラーを、エラーの種類に応じた対処を添えたメッセージにして返します。
+func describeLoadError(err error) string {
+	var notFound *ConfigNotFoundError
+	var parseErr *ConfigParseError
+	var homeErr *UserHomeDirError
+	switch {
+	case errors.As(err, &notFound):
+		return fmt.Sprintf("設定ファイルが %s に見つかりません。aws configure を実行しましたか?", notFound.Path)
+	case errors.As(err, &parseErr):
+		return fmt.Sprintf("設定ファイル %s の書式が正しくありません。セクション名 ([profile <名前>]) や「キー = 値」の行を確認してください: %s", parseErr.Path, strings.TrimSpace(parseErr.Cause.Error()))
+	case errors.As(err, &homeErr):
+		return fmt.Sprintf("ホームディレクトリを特定できませんでした: %v。環境変数 %s で設定ファイルのパスを指定してください。", homeErr.Cause, configFileEnv)
+	}
+	return err.Error()
+}
@@ -0,0 +1,106 @@
+package profileselector
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadAWSProfilesErrorTypes(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(credentialsFileEnv, filepath.Join(dir, "credentials"))
+	valid := filepath.Join(dir, "config")
+	broken := filepath.Join(dir, "broken")
+	files := map[string]string{valid: "[profile dev]\n", broken: "[profile dev\n"}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	missing := filepath.Join(dir, "missing")
+	tests := []struct {
+		name      string
+		sources   profileSources
+		env       string
+		wantPath  string
+		wantParse bool
+	}{
+		{name: "--config のファイルがない", sources: profileSources{configPaths: []string{missing}}, wantPath: missing},
+		{name: "AWS_CONFIG_FILE のファイルがない", env: missing, wantPath: missing},
+		{name: "重ねるファイルの 1 つがない", sources: profileSources{configPaths: []string{missing, valid}}, wantPath: missing},
+		{name: "解析できない", sources: profileSources{configPaths: []string{broken}}, wantPath: broken, wantParse: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(configFileEnv, tt.env)
+			tt.sources.quiet = true
+			_, err := loadAWSProfiles(tt.sources)
+			var notFound *ConfigNotFoundError
+			var parseErr *ConfigParseError
+			switch {
+			case tt.wantParse:
+				if !errors.As(err, &parseErr) {
+					t.Fatalf("loadAWSProfiles() error = %v, want *ConfigParseError", err)
+				}
+				if parseErr.Path != tt.wantPath || parseErr.Cause == nil {
+					t.Errorf("ConfigParseError = %+v, want Path %q と Cause", parseErr, tt.wantPath)
+				}
+			default:
+				if !errors.As(err, &notFound) {
+					t.Fatalf("loadAWSProfiles() error = %v, want *ConfigNotFoundError", err)
+				}
+				if notFound.Path != tt.wantPath {
+					t.Errorf("ConfigNotFoundError.Path = %q, want %q", notFound.Path, tt.wantPath)
+				}
+				if !errors.Is(err, fs.ErrNotExist) {
+					t.Error("errors.Is(err, fs.ErrNotExist) = false, want true")
+				}
+			}
+		})
+	}
+}
+
+func TestConfigParseErrorMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "既定の設定ファイル", path: "/home/user/.aws/config"},
+		{name: "--config で指定したファイル", path: "/tmp/team/config"},
+		{name: "標準入力", path: stdinConfigName},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := &ConfigParseError{Path: tt.path, Cause: errors.New("unclosed section")}
+			want := "設定ファイル " + tt.path + " の読み込みに失敗しました: unclosed section"
+			if got := err.Error(); got != want {
+				t.Errorf("Error() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestDescribeLoadError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "設定ファイルがない", err: &ConfigNotFoundError{Path: "/x/config"}, want: "設定ファイルが /x/config に見つかりません。aws configure を実行しましたか?"},
+		{name: "包まれていても判定", err: fmt.Errorf("読み込み: %w", &ConfigNotFoundError{Path: "/x/config"}), want: "aws configure を実行しましたか?"},
+		{name: "解析できない", err: &ConfigParseError{Path: "/x/config", Cause: errors.New("unclosed section\n")}, want: "設定ファイル /x/config の書式が正しくありません。"},
+		{name: "ホームディレクトリ", err: &UserHomeDirError{Cause: errors.New("unknown user")}, want: "環境変数 " + configFileEnv + " で設定ファイルのパスを指定してください。"},
+		{name: "その他", err: errors.New("予期しないエラー"), want: "予期しないエラー"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeLoadError(tt.err); !strings.Contains(got, tt.want) {
+				t.Errorf("describeLoadError() = %q, want %q を含む", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package profileselector
+
+import (
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// newLoadingSpinner はプロファイルの読み込み中に表示するスピナーを生成します。
+func newLoadingSpinner(th theme) spinner.Model {
+	return spinner.New(spinner.WithSpinner(spinner.Dot), spinner.WithStyle(th.style().Foreground(th.accent)))
+}
+
+// updateLoading は起動直後のプロファイルの読み込み中のメッセージを処理します。
+// 読み込み中は終了のキー以外のキー入力を無視し、処理したメッセージであれば handled に true を返します。
+func (m model) updateLoading(msg tea.Msg) (next tea.Model, cmd tea.Cmd, handled bool) {
+	switch msg := msg.(type) {
+	case profilesLoadedMsg:
+		m.loading = false
+		m = m.withLoadedProfiles(msg.profiles, msg.err)
+		var cmds []tea.Cmd
+		if m.err == nil && m.timeoutRemaining > 0 {
+			cmds = append(cmds, timeoutTickCmd())
+		}
+		// 起動時のトースト (キーバインド設定の警告など) は一覧を表示してから消す
+		if m.toast != "" {
+			m.toastID++
+			cmds = append(cmds, clearToastCmd(m.toastID))
+		}
+		return m, tea.Batch(cmds...), true
+	case spinner.TickMsg:
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd, true
+	case tea.KeyMsg:
+		if m.keys.Matches(actionQuit, msg.String()) {
+			m.quitting = true
+			return m, tea.Quit, true
+		}
+		return m, nil, true
+	}
+	return m, nil, false
+}
+
+// renderLoadingScreen はプロファイルの読み込み中の画面を描画します。
+func (m model) renderLoadingScreen() string {
+	return "\n " + m.spinner.View() + " プロファイルを読み込んでいます...\n"
+}
@@ -0,0 +1,68 @@
+package profileselector
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestLoadingModel(t *testing.T) {
+	tests := []struct {
+		name        string
+		msgs        []tea.Msg
+		wantLoading bool
+		wantErr     bool
+		wantQuit    bool
+		wantNames   []string
+	}{
+		{name: "読み込み中", msgs: []tea.Msg{tea.WindowSizeMsg{Width: 100, Height: 30}}, wantLoading: true},
+		{name: "読み込み中のキー入力は無視", msgs: []tea.Msg{keyPress("down"), keyPress("enter")}, wantLoading: true},
+		{name: "読み込み中でも終了できる", msgs: []tea.Msg{keyPress("q")}, wantLoading: true, wantQuit: true},
+		{
+			name:      "読み込みが完了",
+			msgs:      []tea.Msg{tea.WindowSizeMsg{Width: 100, Height: 30}, profilesLoadedMsg{profiles: testProfiles("dev", "prod")}},
+			wantNames: []string{"dev", "prod"},
+		},
+		{
+			name:    "読み込みに失敗",
+			msgs:    []tea.Msg{profilesLoadedMsg{err: errors.New("読み込みに失敗しました")}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newTestModel(t, options{}, nil) // 一時的な設定ディレクトリを使う
+			m := initialModel(options{}, newStyleRenderer(io.Discard, true))
+			if !m.loading {
+				t.Fatal("initialModel() が読み込み中の状態ではありません")
+			}
+			if m.Init() == nil {
+				t.Fatal("Init() がプロファイルを読み込むコマンドを返しませんでした")
+			}
+			var next tea.Model = m
+			for _, msg := range tt.msgs {
+				next, _ = next.Update(msg)
+			}
+			got := next.(model)
+			if got.loading != tt.wantLoading {
+				t.Errorf("loading = %v, want %v", got.loading, tt.wantLoading)
+			}
+			if (got.err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", got.err, tt.wantErr)
+			}
+			if got.quitting != tt.wantQuit {
+				t.Errorf("quitting = %v, want %v", got.quitting, tt.wantQuit)
+			}
+			if names := profileNames(got.profiles); !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("一覧 = %v, want %v", names, tt.wantNames)
+			}
+			if view := got.View(); tt.wantLoading && !tt.wantQuit && !strings.Contains(view, "プロファイルを読み込んでいます") {
+				t.Errorf("読み込み中の View() = %q", view)
+			}
+		})
+	}
+}
@@ -0,0 +1,1297 @@
+package profileselector
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	// "github.com/charmbracelet/bubbles/viewport" // 未使用になったためコメントアウトまたは削除
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"gopkg.in/ini.v1"
+)
+
+// profileSource はプロファイルの読み込み元ファイルの種類です。
+type profileSource string
+
+const (
+	sourceConfig      profileSource = "config"      // ~/.aws/config
+	sourceCredentials profileSource = "credentials" // ~/.aws/credentials
+)
+
+// awsProfile はAWSプロファイルの情報を保持します。
+type awsProfile struct {
+	Name             string            `json:"name"`                  // プロファイル名
+	RoleArn          string            `json:"roleArn,omitempty"`     // role_arn (存在すれば)
+	RawKeys          map[string]string `json:"rawKeys,omitempty"`     // セクション内の全てのキーと値
+	Source           profileSource     `json:"source,omitempty"`      // 読み込み元ファイルの種類
+	Description      string            `json:"description,omitempty"` // x_description に記述されたプロファイルの説明
+	SourceCycle      bool              `json:"-"`                     // source_profile をたどると循環するか
+	CustomEndpoint   bool              `json:"-"`                     // endpoint_url や services でカスタムエンドポイントを使うか
+	AccountID        string            `json:"-"`                     // role_arn から取り出したアカウント ID (取り出せなければ空)
+	ServiceEndpoints map[string]string `json:"-"`                     // services で参照するセクションのサービス ID と endpoint_url
+	MFASerial        string            `json:"-"`                     // mfa_serial (存在すれば)
+	RequiresMFA      bool              `json:"-"`                     // mfa_serial が設定されており、MFA コードの入力が必要か
+	Region           string            `json:"-"`                     // region (存在すれば)
+	Output           string            `json:"-"`                     // output (AWS CLI の出力形式, 存在すれば)
+	FromSSM          bool              `json:"-"`                     // SSM パラメータストアから読み込んだか
+	SSOExpired       bool              `json:"-"`                     // SSO のトークンの有効期限が切れているか
+	CredentialStatus credentialStatus  `json:"-"`                     // キャッシュされた認証情報 (SSO のトークンや AssumeRole の認証情報) の状態
+	Order            int               `json:"-"`                     // 読み込んだ順番 (config, credentials, SSM の順に 0 から数える)
+	Unset            bool              `json:"-"`                     // --allow-unset で一覧の先頭に追加した、プロファイルの設定を解除する項目か
+	Alias            string            `json:"-"`                     // 別名ファイルに記述された表示用の別名 (なければ空)
+	EnvOverrides     []string          `json:"-"`                     // 選択したときに追加で設定する環境変数 ("名前=値" の形式, 名前順)
+}
+
+// roleArnMode は一覧での role_arn の表示方法です。詳細表示切替キーを押すたびに順に切り替わります。
+type roleArnMode int
+
+const (
+	roleArnHidden roleArnMode = iota // 表示しない
+	roleArnCursor                    // カーソル位置の行だけに表示する
+	roleArnAll                       // 全ての行に表示する
+)
+
+// initialRoleArnMode は起動時の role_arn の表示方法を返します。
+func initialRoleArnMode(showAllRoles bool) roleArnMode {
+	if showAllRoles {
+		return roleArnAll
+	}
+	return roleArnHidden
+}
+
+// next は詳細表示切替キーを押したときの次の表示方法を返します。
+func (r roleArnMode) next() roleArnMode {
+	return (r + 1) % (roleArnAll + 1)
+}
+
+// shows はカーソル位置の行かどうか (atCursor) に応じて、その行に role_arn を表示するかを返します。
+func (r roleArnMode) shows(atCursor bool) bool {
+	return r == roleArnAll || (r == roleArnCursor && atCursor)
+}
+
+// String はヘルプに表示する表示方法の名前を返します。
+func (r roleArnMode) String() string {
+	switch r {
+	case roleArnCursor:
+		return "選択行"
+	case roleArnAll:
+		return "全行"
+	}
+	return "非表示"
+}
+
+// descriptionKey はプロファイルの説明を記述する独自のキーです。
+const descriptionKey = "x_description"
+
+// credentialType は認証情報の種類です。
+type credentialType string
+
+const (
+	credentialIAM     credentialType = "IAM"     // アクセスキー
+	credentialSSO     credentialType = "SSO"     // IAM Identity Center (SSO)
+	credentialRole    credentialType = "Role"    // AssumeRole
+	credentialProcess credentialType = "Process" // credential_process
+	credentialOther   credentialType = "Other"   // 上記以外 (region のみの設定など)
+)
+
+// CredentialType はプロファイルの認証情報の種類を返します。
+func (p awsProfile) CredentialType() credentialType {
+	switch {
+	case p.RawKeys["role_arn"] != "":
+		return credentialRole
+	case p.RawKeys["sso_session"] != "" || p.RawKeys["sso_start_url"] != "":
+		return credentialSSO
+	case p.RawKeys["credential_process"] != "":
+		return credentialProcess
+	case p.RawKeys["aws_access_key_id"] != "":
+		return credentialIAM
+	}
+	return credentialOther
+}
+
+// secretCredentialKeys は RawKeys に保持しない秘密情報のキーです。
+var secretCredentialKeys = []string{"aws_secret_access_key", "aws_session_token"}
+
+// headerHeight はビューポートの計算に使用するヘッダーの行数です。
+// 1行目: タイトル, 2〜3行目: タブバー, 4行目: 種類ごとのプロファイル数, 5行目: 区切り線
+const headerHeight = 5
+
+// footerHeight はビューポートの計算に使用するフッターの行数です。
+// Viewメソッド内のフッター構成 (3行):
+// 1. 区切り線 (ビューポートの直後)
+// 2. ヘルプテキスト (ウィンドウの幅で折り返した 2 行目以降は helpHeight で数える)
+// 3. ステータス情報
+const footerHeight = 3
+
+// model はアプリケーションの状態を保持します。
+type model struct {
+	allProfiles        []awsProfile         // 読み込んだ全てのAWSプロファイル
+	profiles           []awsProfile         // 表示対象のAWSプロファイルのリスト
+	sourceFilter       profileSource        // 表示中のプロファイルの読み込み元
+	cursor             int                  // 現在選択されているプロファイルのインデックス
+	scrollOffset       int                  // リスト表示のスクロールオフセット（開始行。1列表示では開始インデックスと同じ）
+	listVisibleHeight  int                  // リストが表示される実際の高さ（行数）
+	windowWidth        int                  // 現在のウィンドウ幅
+	roleArnMode        roleArnMode          // role_arn の表示方法
+	selectedProfile    string               // ユーザーによって最終的に選択されたプロファイル名
+	activeProfile      string               // 起動時に AWS_DEFAULT_PROFILE で有効になっていたプロファイル名
+	quitting           bool                 // ユーザーがqキーやCtrl+Cで終了しようとしているか
+	err                error                // 初期化時などに発生したエラー
+	ready              bool                 // WindowSizeMsgを一度受信してlistVisibleHeightが設定されたか
+	keys               KeyMap               // キーバインド設定
+	sortMode           sortMode             // プロファイルの並び順
+	history            []historyEntry       // プロファイルの選択履歴
+	lastUsed           map[string]time.Time // プロファイル名ごとの最後に選択した日時
+	altScreen          bool                 // 代替スクリーンで描画しているか
+	typeSummary        profileTypeSummary   // 全プロファイルの種類ごとの件数
+	maxProfiles        int                  // 表示するプロファイルの最大数 (0 は無制限)
+	totalProfiles      int                  // 件数を制限する前のプロファイル数
+	columns            int                  // 現在の表示列数
+	multiColumns       int                  // 複数列表示に切り替えたときの列数
+	toast              string               // ステータス行に一時的に表示するメッセージ
+	toastID            int                  // 表示中のトーストの ID
+	notes              map[string]string    // プロファイル名ごとのメモ
+	editingNote        bool                 // メモを編集中かどうか
+	noteInput          textinput.Model      // メモの入力欄
+	currentTab         int                  // 表示中のタブ (tabProfiles または tabRecent)
+	recentCursor       int                  // Recent タブで選択されている履歴のインデックス
+	count              int                  // 数字キーで入力された次の移動の回数 (0 は未入力)
+	pendingTop         bool                 // gg の 1 つ目の g が入力されたか
+	filter             profileFilter        // コマンドライン引数で指定された表示するプロファイルの条件
+	timeoutRemaining   int                  // 自動選択までの残り秒数 (0 は自動選択しない)
+	envProfileMissing  bool                 // AWS_DEFAULT_PROFILE のプロファイルが設定ファイルに存在しないか
+	diffState          diffState            // 差分モードの状態
+	diffLeft           awsProfile           // 差分モードで選択した比較元のプロファイル
+	diffRight          awsProfile           // 差分モードで選択した比較先のプロファイル
+	zebra              bool                 // 一覧の奇数行に背景色を付けるか
+	border             bool                 // 一覧を角の丸い枠で囲むか
+	warnNoMFA          bool                 // role_arn があり mfa_serial のないプロファイルに印を付けるか
+	deleteMode         bool                 // delete サブコマンドで、選択したプロファイルを削除するモードか
+	confirmingDelete   bool                 // 削除モードで、カーソル位置のプロファイルの削除を確認中か
+	deletedProfile     string               // 削除モードで削除したプロファイル名
+	recentCycle        int                  // 最近選択したプロファイルをたどるときに次に移動する履歴の位置
+	renameMode         bool                 // rename サブコマンドで、選択したプロファイルの名前を変更するモードか
+	renaming           bool                 // 名前の変更モードで、新しい名前を入力中か
+	renameInput        textinput.Model      // 新しいプロファイル名の入力欄
+	renamedProfile     string               // 名前の変更モードで変更した後のプロファイル名
+	searching          bool                 // 検索の入力中か
+	searchInput        textinput.Model      // 検索の入力欄
+	searchQuery        string               // 一覧の絞り込みに使っている検索文字列
+	searchSeq          int                  // 検索の入力の通し番号 (遅延させた絞り込みが最新の入力に対するものかの確認に使う)
+	searchHistory      []string             // 確定した検索文字列の履歴 (古い順, 最大 maxSearchHistory 件)
+	searchHistoryIndex int                  // 検索履歴をたどっている位置 (最も新しいものが 0, たどっていない場合は -1)
+	debounceSearch     bool                 // プロファイルが多いため、検索の絞り込みを遅延させるか
+	jumpKeys           bool                 // 1〜9 のキーを移動回数ではなく N 番目のプロファイルへの移動に使うか
+	cursorIndicator    string               // カーソル位置の行の先頭に表示する文字列
+	cursorColor        lipgloss.Color       // カーソルの色
+	tree               bool                 // source_profile の継承関係の木として一覧を表示するか
+	treeDepths         []int                // 木表示での profiles の各プロファイルの深さ (木表示でない場合は nil)
+	sources            profileSources       // プロファイルの読み込み元 (再読み込みや編集、削除、名前の変更の対象)
+	configPath         string               // 読み込んだ設定ファイルのパス (プロファイルが見つからないときの案内に表示する)
+	allowUnset         bool                 // 一覧の先頭にプロファイルの設定を解除する項目を表示するか
+	theme              theme                // 描画に使う色の組み合わせ
+	wrap               bool                 // 一覧の先頭と末尾でカーソルの移動を反対側につなげるか
+	enteringMFA        bool                 // 選択したプロファイルの MFA コードを入力中か
+	mfaInput           textinput.Model      // MFA コードの入力欄
+	mfaPending         bool                 // 入力した MFA コードで一時的な認証情報を取得中か
+	mfaTarget          awsProfile           // MFA コードを入力中の、選択しようとしているプロファイル
+	mfaProfile         awsProfile           // MFA コードで取得した一時的な認証情報を保存したプロファイル
+	loading            bool                 // 起動直後のプロファイルの読み込み中か
+	spinner            spinner.Model        // プロファイルの読み込み中に表示するスピナー
+	initialProfile     string               // 読み込み後に最初にカーソルを置くプロファイル名
+	compact            bool                 // タイトルやフッターを省き、プロファイル名だけを 1 行に 1 つずつ表示するか
+	showHelp           bool                 // --compact の指定時に、一覧の下に操作の説明を表示しているか
+	shortRoleArn       bool                 // 一覧の RoleARN を「アカウント ID:ロール名」の短い形式で表示するか
+	previewHeight      int                  // 一覧の下のプレビューペインの行数 (0 の場合は表示しない)
+	profileEnvName     string               // プロファイルを設定する環境変数の名前 (AWS_DEFAULT_PROFILE または --env-prefix の <接頭辞>_PROFILE)
+}
+
+// configFileEnv と credentialsFileEnv は AWS CLI と同じく設定ファイルのパスを上書きする環境変数です。
+const (
+	configFileEnv      = "AWS_CONFIG_FILE"
+	credentialsFileEnv = "AWS_SHARED_CREDENTIALS_FILE"
+)
+
+// resolveConfigPath は設定ファイルのパスを返します。
+// 環境変数 AWS_CONFIG_FILE が設定されていればそのパスを、なければ ~/.aws/config を返します。
+func resolveConfigPath() (string, error) {
+	if path := os.Getenv(configFileEnv); path != "" {
+		return expandHome(path)
+	}
+	home, err := userHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".aws", "config"), nil
+}
+
+// resolveCredentialsPath は認証情報ファイルのパスを返します。
+// 環境変数 AWS_SHARED_CREDENTIALS_FILE が設定されていればそのパスを、なければ ~/.aws/credentials を返します。
+func resolveCredentialsPath() (string, error) {
+	if path := os.Getenv(credentialsFileEnv); path != "" {
+		return expandHome(path)
+	}
+	home, err := userHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".aws", "credentials"), nil
+}
+
+// newAWSProfile はセクションのキーと値からプロファイル情報を生成します。
+// 読み込み元に関係なく、シークレットアクセスキーなどの秘密情報は RawKeys に保持しません。
+func newAWSProfile(name string, rawKeys map[string]string, source profileSource) awsProfile {
+	rawKeys = maps.Clone(rawKeys)
+	for _, key := range secretCredentialKeys {
+		delete(rawKeys, key)
+	}
+	return awsProfile{
+		Name:        name,
+		RoleArn:     rawKeys["role_arn"],
+		AccountID:   accountIDFromRoleArn(rawKeys["role_arn"]),
+		MFASerial:   rawKeys["mfa_serial"],
+		RequiresMFA: rawKeys["mfa_serial"] != "",
+		Region:      rawKeys["region"],
+		Output:      rawKeys["output"],
+		RawKeys:     rawKeys,
+		Source:      source,
+		Description: rawKeys[descriptionKey],
+	}
+}
+
+// readFileError はファイルの読み込みに失敗したときのエラーを返します。
+// ファイルは存在するものの権限がなく読み込めない場合は、権限の確認を促すメッセージにします。
+func readFileError(label, path string, err error) error {
+	if errors.Is(err, fs.ErrPermission) {
+		return fmt.Errorf("%s は存在しますが読み込めません。ファイルの権限を確認してください (例: chmod 600 %s): %w", label, path, err)
+	}
+	return fmt.Errorf("%s の読み込みに失敗しました: %w (ファイル: %s)", label, err, path)
+}
+
+// loadAWSProfiles は sources の設定ファイルと認証情報ファイル (デフォルトは ~/.aws/config と ~/.aws/credentials) を読み込み、プロファイル情報を抽出します。
+// 標準入力から設定ファイルの内容が渡された場合は、設定ファイルの代わりにそれを読み込みます。
+// 設定ファイルが存在しない場合は *ConfigNotFoundError を、解析できない場合は *ConfigParseError を、
+// ホームディレクトリを取得できない場合は *UserHomeDirError を返します。
+func loadAWSProfiles(sources profileSources) ([]awsProfile, error) {
+	source, err := sources.configSource()
+	if err != nil {
+		return nil, err
+	}
+	name := stdinConfigName
+	var loaderOpts []LoaderOption
+	if source != nil {
+		loaderOpts = append(loaderOpts, withConfigSource(source))
+	} else if name, err = sources.configPath(); err != nil {
+		return nil, err
+	}
+
+	profiles, err := loadAWSProfilesConcurrent(name, loaderOpts...)
+	if err != nil {
+		return nil, err
+	}
+	// --config に複数のパスが指定された場合は、先に指定された設定ファイルのプロファイルに重ねる
+	if len(sources.configPaths) > 1 {
+		base, err := sources.loadBaseConfigProfiles()
+		if err != nil {
+			return nil, err
+		}
+		profiles = mergeConfigProfiles(base, profiles)
+	}
+
+	credentialsFile, err := sources.credentialsPath()
+	if err != nil {
+		return nil, err
+	}
+	credentialsProfiles, err := loadCredentialsProfiles(credentialsFile)
+	if err != nil {
+		return nil, err
+	}
+	profiles = append(profiles, credentialsProfiles...)
+
+	if sources.ssmPrefix != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), ssmTimeout)
+		defer cancel()
+		client, err := newSDKSSMClient(ctx)
+		if err != nil {
+			return nil, err
+		}
+		ssmProfiles, err := loadProfilesFromSSM(ctx, client, sources.ssmPrefix, sources.quiet)
+		if err != nil {
+			return nil, err
+		}
+		profiles = mergeSSMProfiles(profiles, ssmProfiles)
+	}
+
+	// 並べ替えや絞り込みの後でも元の順番に戻せるように、読み込んだ順番を記録する
+	for i := range profiles {
+		profiles[i].Order = i
+	}
+	markSourceCycles(profiles)
+	markCredentialStatus(profiles)
+	applyAliases(profiles, loadAliasesOrEmpty())
+	envOverrides := loadEnvOverridesOrEmpty()
+	for i := range profiles {
+		profiles[i].EnvOverrides = applyEnvOverrides(profiles[i].Name, envOverrides)
+	}
+	return profiles, nil
+}
+
+// loadCredentialsProfiles は ~/.aws/credentials ファイルを読み込み、プロファイル情報を抽出します。
+// ファイルが存在しない場合は空のリストを返します。
+func loadCredentialsProfiles(credentialsFile string) ([]awsProfile, error) {
+	if _, err := os.Stat(credentialsFile); errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+
+	cfg, err := ini.Load(credentialsFile)
+	if err != nil {
+		return nil, readFileError("~/.aws/credentials", credentialsFile, err)
+	}
+
+	var profiles []awsProfile
+	for _, section := range cfg.Sections() {
+		profileName := strings.TrimSpace(section.Name())
+		if section.Name() == ini.DefaultSection && len(section.Keys()) == 0 {
+			continue
+		}
+		if profileName == "" {
+			continue
+		}
+
+		profiles = append(profiles, newAWSProfile(profileName, section.KeysHash(), sourceCredentials))
+	}
+	return profiles, nil
+}
+
+// profileIndex は profiles の中で name という名前のプロファイルのインデックスを返します。
+// 見つからない場合は -1 を返します。
+func profileIndex(profiles []awsProfile, name string) int {
+	for i, p := range profiles {
+		if p.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// filterBySource は読み込み元が source のプロファイルだけを抽出します。
+func filterBySource(profiles []awsProfile, source profileSource) []awsProfile {
+	var filtered []awsProfile
+	for _, p := range profiles {
+		if p.Source == source {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
+
+// loadSortedProfiles は sources からプロファイルを読み込み、mode に従って並べ替えます。
+func loadSortedProfiles(sources profileSources, mode sortMode, history []historyEntry) ([]awsProfile, error) {
+	profiles, err := loadAWSProfiles(sources)
+	if err != nil {
+		return nil, err
+	}
+	return sortProfiles(profiles, mode, history), nil
+}
+
+// loadHistoryOrEmpty は選択履歴を読み込みます。読み込めなかった場合は空の履歴を返します。
+func loadHistoryOrEmpty() []historyEntry {
+	path, err := historyPath()
+	if err != nil {
+		return nil
+	}
+	history, _ := loadHistory(path)
+	return history
+}
+
+// loadNotesOrEmpty はメモを読み込みます。読み込めない場合は空のメモを返します。
+func loadNotesOrEmpty() map[string]string {
+	path, err := notesPath()
+	if err != nil {
+		return map[string]string{}
+	}
+	notes, err := loadNotes(path)
+	if err != nil {
+		return map[string]string{}
+	}
+	return notes
+}
+
+// initialModel はアプリケーションの初期状態を生成します。画面のスタイルは、選択画面を描画する端末に合わせたレンダラー r で描画します。
+// プロファイルは Init で返すコマンドで opts.sources() から読み込み、読み込みが終わるまではスピナーを表示します。
+func initialModel(opts options, r *lipgloss.Renderer) model {
+	// 環境変数 AWS_DEFAULT_PROFILE (--env-prefix の指定時は <接頭辞>_PROFILE) を読み込み、読み込み後の初期カーソル位置に使う
+	// --default または AWS_PROFILE_SELECTOR_DEFAULT が指定されていれば、そちらを優先する
+	profileEnvName := envVarName(opts.envPrefix, "PROFILE")
+	currentProfileEnv := os.Getenv(profileEnvName)
+
+	// 列表示切替キーで使う列数 (--columns で 2 以上が指定されていなければデフォルトの列数)
+	multiColumns := defaultMultiColumns
+	if opts.columns > 1 {
+		multiColumns = opts.columns
+	}
+
+	// キーバインド設定を読み込み (解析に失敗した場合はデフォルトのキーバインドを使用)
+	// 読み込みの失敗やキーの重複は、プロファイルの読み込み後にトーストで知らせる
+	keys := defaultKeyMap()
+	keysWarning := ""
+	if keyFile, pathErr := keyMapPath(); pathErr == nil {
+		var err error
+		if keys, err = loadKeyMap(keyFile); err != nil {
+			keysWarning = err.Error()
+		}
+	}
+
+	th := resolveTheme(opts.theme, r)
+
+	noteInput := textinput.New()
+	noteInput.Prompt = "メモ: "
+	noteInput.Placeholder = "プロファイルのメモを入力"
+
+	renameInput := textinput.New()
+	renameInput.Prompt = "新しい名前: "
+
+	return model{
+		sourceFilter:       sourceConfig,
+		activeProfile:      currentProfileEnv,
+		profileEnvName:     profileEnvName,
+		initialProfile:     resolveInitialProfile(opts.defaultProfile, currentProfileEnv),
+		loading:            true,
+		spinner:            newLoadingSpinner(th),
+		scrollOffset:       0, // 初期スクロールオフセットは0
+		roleArnMode:        initialRoleArnMode(opts.showAllRoles),
+		ready:              false, // まだウィンドウサイズが不明
+		keys:               keys,
+		toast:              keysWarning,
+		sortMode:           opts.sort,
+		history:            loadHistoryOrEmpty(),
+		lastUsed:           loadLastUsedOrEmpty(),
+		altScreen:          !opts.noAltScreen,
+		maxProfiles:        opts.maxProfiles,
+		filter:             opts.filter,
+		columns:            opts.columns,
+		multiColumns:       multiColumns,
+		notes:              loadNotesOrEmpty(),
+		noteInput:          noteInput,
+		renameInput:        renameInput,
+		mfaInput:           newMFAInput(),
+		searchInput:        newSearchInput(),
+		searchHistory:      loadSearchHistoryOrEmpty(),
+		searchHistoryIndex: -1,
+		jumpKeys:           opts.jumpKeys,
+		cursorIndicator:    cursorIndicatorFromEnv(),
+		cursorColor:        cursorColorFromEnv(th.cursor),
+		theme:              th,
+		wrap:               opts.wrap,
+		compact:            opts.compact,
+		previewHeight:      initialPreviewHeight(opts),
+		tree:               opts.tree,
+		sources:            opts.sources(),
+		configPath:         opts.sources().displayPath(),
+		allowUnset:         opts.allowUnset,
+		timeoutRemaining:   opts.timeout,
+		zebra:              !opts.noZebra && os.Getenv(noZebraEnv) != "1",
+		border:             opts.border,
+		warnNoMFA:          opts.warnNoMFA,
+	}
+}
+
+// withLoadedProfiles は起動直後に読み込んだ並べ替え済みのプロファイル sortedProfiles で一覧を初期化します。
+// 読み込みに失敗した場合 (err が nil でない場合) はエラー画面に切り替えます。
+func (m model) withLoadedProfiles(sortedProfiles []awsProfile, err error) model {
+	if err != nil {
+		m.err = err
+		return m
+	}
+	loadedProfiles := m.filter.apply(sortedProfiles)
+	m.allProfiles = limitProfiles(loadedProfiles, m.maxProfiles, m.history)
+	m.typeSummary = computeTypeSummary(loadedProfiles)
+	m.totalProfiles = len(loadedProfiles)
+	m.debounceSearch = len(m.allProfiles) > searchDebounceThreshold
+
+	// config にプロファイルがなければ credentials のプロファイルを表示
+	m.sourceFilter = sourceConfig
+	if len(filterBySource(m.allProfiles, sourceConfig)) == 0 && len(filterBySource(m.allProfiles, sourceCredentials)) > 0 {
+		m.sourceFilter = sourceCredentials
+	}
+	if m.initialProfile != "" {
+		if i := profileIndex(m.allProfiles, m.initialProfile); i >= 0 {
+			m.sourceFilter = m.allProfiles[i].Source
+		}
+	}
+	m.profiles = filterBySource(m.allProfiles, m.sourceFilter)
+	m.treeDepths = nil
+	if m.tree {
+		m.profiles, m.treeDepths = flattenProfileTree(m.profiles)
+	}
+	if m.allowUnset && len(m.profiles) > 0 {
+		m.profiles, m.treeDepths = withUnsetEntry(m.profiles, m.treeDepths)
+	}
+	m.cursor = 0
+	if i := profileIndex(m.profiles, m.initialProfile); m.initialProfile != "" && i >= 0 {
+		m.cursor = i // ★★★ 初期カーソルを設定 ★★★
+	}
+
+	// 絞り込み前の全プロファイルにも見つからなければ、シェルに古い設定が残っている
+	m.envProfileMissing = m.activeProfile != "" && profileIndex(sortedProfiles, m.activeProfile) < 0
+	return m.clampCursor()
+}
+
+// profilesLoadedMsg はプロファイルの読み込みまたは再読み込みが完了したときに送られるメッセージです。
+type profilesLoadedMsg struct {
+	profiles []awsProfile // 並べ替え済みのプロファイル
+	err      error        // 読み込み時に発生したエラー
+}
+
+// reloadCmd はプロファイルをバックグラウンドで読み込み、結果を profilesLoadedMsg として返すコマンドです。
+func (m model) reloadCmd() tea.Cmd {
+	sources, mode, history := m.sources, m.sortMode, m.history
+	return func() tea.Msg {
+		profiles, err := loadSortedProfiles(sources, mode, history)
+		return profilesLoadedMsg{profiles: profiles, err: err}
+	}
+}
+
+// setProfiles は再読み込みしたプロファイルで一覧を置き換え、同じ名前のプロファイルが残っていればカーソルをそこに維持します。
+func (m model) setProfiles(profiles []awsProfile) model {
+	m.envProfileMissing = m.activeProfile != "" && profileIndex(profiles, m.activeProfile) < 0
+	profiles = m.filter.apply(profiles)
+	m.allProfiles = limitProfiles(profiles, m.maxProfiles, m.history)
+	m.typeSummary = computeTypeSummary(profiles)
+	m.totalProfiles = len(profiles)
+	m.debounceSearch = len(m.allProfiles) > searchDebounceThreshold
+	return m.applyFilters()
+}
+
+// applyFilters は全プロファイルから表示対象のプロファイルを抽出し直します。
+// 抽出前にカーソルがあったプロファイルが残っていれば、カーソルをそこに維持します。
+func (m model) applyFilters() model {
+	currentName := ""
+	if m.cursor >= 0 && m.cursor < len(m.profiles) {
+		currentName = m.profiles[m.cursor].Name
+	}
+
+	m.profiles = filterBySource(m.allProfiles, m.sourceFilter)
+	if m.searchQuery != "" {
+		m.profiles = filterBySearch(m.profiles, m.searchQuery)
+	}
+	if m.tree {
+		m.profiles, m.treeDepths = flattenProfileTree(m.profiles)
+	}
+	// プロファイルの設定を解除する項目は、検索中でない場合だけ先頭に表示する
+	if m.allowUnset && m.searchQuery == "" && len(m.profiles) > 0 {
+		m.profiles, m.treeDepths = withUnsetEntry(m.profiles, m.treeDepths)
+	}
+	m.cursor = profileIndex(m.profiles, currentName)
+	return m.clampCursor()
+}
+
+// clampCursor はカーソルとスクロールオフセットを有効な範囲に収め、カーソルが表示範囲内に入るように調整します。
+func (m model) clampCursor() model {
+	if m.cursor >= len(m.profiles) {
+		m.cursor = len(m.profiles) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+
+	// 複数列表示ではスクロールオフセットを行単位で扱う
+	cols := m.columnCount()
+	cursorRow := m.cursor / cols
+	totalRows := (len(m.profiles) + cols - 1) / cols
+
+	if cursorRow < m.scrollOffset {
+		m.scrollOffset = cursorRow
+	}
+	if m.listVisibleHeight > 0 && cursorRow >= m.scrollOffset+m.listVisibleHeight {
+		m.scrollOffset = cursorRow - m.listVisibleHeight + 1
+	}
+
+	maxScrollOffset := totalRows - m.listVisibleHeight
+	if maxScrollOffset < 0 {
+		maxScrollOffset = 0
+	}
+	if m.scrollOffset > maxScrollOffset {
+		m.scrollOffset = maxScrollOffset
+	}
+	if m.scrollOffset < 0 {
+		m.scrollOffset = 0
+	}
+	return m
+}
+
+// Init はモデル初期化時に実行されるコマンドを返します。
+// プロファイルをバックグラウンドで読み込み、読み込み中はスピナーを回します。
+// --timeout が指定された場合は、読み込みが終わってから自動選択までの残り時間のカウントダウンを開始します。
+func (m model) Init() tea.Cmd {
+	return tea.Batch(m.reloadCmd(), m.spinner.Tick)
+}
+
+// Update はイベントに基づいてモデルを更新し、コマンドを返します。
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.err != nil {
+		return m.updateErrored(msg)
+	}
+	if m.loading {
+		if next, cmd, handled := m.updateLoading(msg); handled {
+			return next, cmd
+		}
+	}
+
+	// キーが押されたら自動選択を取り消し、最近のプロファイルをたどる位置を先頭に戻す
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		m.timeoutRemaining = 0
+		if !m.keys.Matches(actionCycleRecent, keyMsg.String()) {
+			m.recentCycle = 0
+		}
+	}
+
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && m.searching {
+		return m.updateSearchInput(keyMsg)
+	}
+
+	if len(m.profiles) == 0 && m.ready && m.currentTab == tabProfiles && m.searchQuery == "" {
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			key := keyMsg.String()
+			if m.keys.Matches(actionQuit, key) || m.keys.Matches(actionSelect, key) {
+				m.quitting = true
+				return m, tea.Quit
+			}
+		}
+	}
+
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && m.editingNote {
+		return m.updateNoteInput(keyMsg)
+	}
+
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && m.renaming {
+		return m.updateRenameInput(keyMsg)
+	}
+
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && m.enteringMFA {
+		return m.updateMFAInput(keyMsg)
+	}
+
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && m.confirmingDelete {
+		return m.updateDeleteConfirm(keyMsg.String())
+	}
+
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && m.diffState != diffOff && m.currentTab == tabProfiles {
+		if next, cmd, handled := m.updateDiff(keyMsg.String()); handled {
+			return next, cmd
+		}
+	}
+
+	switch msg := msg.(type) {
+	case mfaResultMsg:
+		return m.updateMFAResult(msg)
+
+	case clipboardMsg:
+		if msg.err != nil {
+			m.toast = fmt.Sprintf("コピーに失敗しました: %v", msg.err)
+		} else {
+			m.toast = "Copied!"
+		}
+		m.toastID++
+		return m, clearToastCmd(m.toastID)
+
+	case clearToastMsg:
+		if msg.id == m.toastID {
+			m.toast = ""
+		}
+		return m, nil
+
+	case editorFinishedMsg:
+		if msg.err != nil {
+			m.err = fmt.Errorf("エディタの実行に失敗しました: %w", msg.err)
+			return m, nil
+		}
+		return m, m.reloadCmd()
+
+	case timeoutTickMsg:
+		return m.updateTimeout()
+
+	case searchTickMsg:
+		return m.updateSearchTick(msg)
+
+	case profilesLoadedMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			return m, nil
+		}
+		m = m.setProfiles(msg.profiles)
+		m.toast = fmt.Sprintf("プロファイルを再読み込みしました (%d 件)", len(m.allProfiles))
+		m.toastID++
+		return m, clearToastCmd(m.toastID)
+
+	case tea.WindowSizeMsg:
+		m.windowWidth = msg.Width
+		m.listVisibleHeight = msg.Height - m.chromeHeight()
+		if m.listVisibleHeight < 0 {
+			m.listVisibleHeight = 0
+		}
+		m.ready = true
+
+		// ウィンドウリサイズ時または最初の準備完了時に、カーソルが表示範囲内に入るようにスクロールオフセットを調整
+		if len(m.profiles) > 0 {
+			m = m.clampCursor()
+		}
+
+	case tea.KeyMsg:
+		key := msg.String()
+		switch {
+		case (m.deleteMode || m.renameMode || m.compact) && (m.keys.Matches(actionNextTab, key) || m.keys.Matches(actionPrevTab, key)):
+			// 削除モード、名前の変更モード、--compact の表示では選択履歴のタブに切り替えない
+			return m, nil
+		case m.keys.Matches(actionNextTab, key):
+			m.currentTab = (m.currentTab + 1) % len(tabNames)
+			return m, nil
+		case m.keys.Matches(actionPrevTab, key):
+			m.currentTab = (m.currentTab + len(tabNames) - 1) % len(tabNames)
+			return m, nil
+		}
+		if m.currentTab == tabRecent {
+			return m.updateRecent(key)
+		}
+
+		if m.keys.Matches(actionSwitchSource, key) {
+			if m.sourceFilter == sourceConfig {
+				m.sourceFilter = sourceCredentials
+			} else {
+				m.sourceFilter = sourceConfig
+			}
+			m.scrollOffset = 0
+			return m.applyFilters(), nil
+		}
+
+		if m.keys.Matches(actionSearch, key) && m.diffState == diffOff {
+			m.searching = true
+			m.searchHistoryIndex = -1
+			m.searchInput.CursorEnd()
+			return m, m.searchInput.Focus()
+		}
+
+		if len(m.profiles) == 0 {
+			if m.keys.Matches(actionQuit, key) || m.keys.Matches(actionSelect, key) {
+				m.quitting = true
+				return m, tea.Quit
+			}
+			return m, nil
+		}
+
+		if next, cmd, handled := m.updateJumpKeys(key); handled {
+			return next, cmd
+		}
+		if next, handled := m.updateMotionPrefix(key); handled {
+			return next, nil
+		}
+		// 移動回数は次のキー入力で使い切る (移動以外のキーでは破棄する)
+		count := m.motionCount()
+		m.count = 0
+
+		switch {
+		case m.keys.Matches(actionQuit, key):
+			m.quitting = true
+			return m, tea.Quit
+
+		case m.keys.Matches(actionUp, key):
+			for n := 0; n < count; n++ {
+				m = m.cursorUp()
+			}
+			m = m.clampCursor()
+		case m.keys.Matches(actionDown, key):
+			for n := 0; n < count; n++ {
+				m = m.cursorDown()
+			}
+			m = m.clampCursor()
+		case m.keys.Matches(actionLeft, key):
+			for n := 0; n < count && m.columnCount() > 1 && m.cursor%m.columnCount() > 0; n++ {
+				m.cursor--
+			}
+		case m.keys.Matches(actionRight, key):
+			for n := 0; n < count && m.columnCount() > 1 && m.cursor%m.columnCount() < m.columnCount()-1 && m.cursor+1 < len(m.profiles); n++ {
+				m.cursor++
+			}
+		case m.keys.Matches(actionToggleColumns, key):
+			if m.columns > 1 {
+				m.columns = 1
+			} else {
+				m.columns = m.multiColumns
+			}
+			m.scrollOffset = 0
+			m = m.clampCursor()
+		case m.compact && m.keys.Matches(actionHelp, key):
+			m.showHelp = !m.showHelp
+		case m.keys.Matches(actionToggleDetail, key):
+			m.roleArnMode = m.roleArnMode.next()
+		case m.keys.Matches(actionShortRoleArn, key):
+			m.shortRoleArn = !m.shortRoleArn
+		case m.keys.Matches(actionEdit, key):
+			configFile, err := m.sources.sourcePath(m.sourceFilter)
+			if err != nil {
+				m.err = err
+				return m, nil
+			}
+			return m, openEditorCmd(configFile, m.profiles[m.cursor].Name)
+		case m.keys.Matches(actionReload, key):
+			return m, m.reloadCmd()
+		case m.keys.Matches(actionCycleRecent, key):
+			m = m.cycleRecent()
+		case m.keys.Matches(actionCopy, key):
+			return m, copyToClipboard(m.profiles[m.cursor].Name)
+		case m.keys.Matches(actionDiff, key):
+			m.diffState = diffPickLeft
+		case m.keys.Matches(actionNote, key):
+			m.editingNote = true
+			m.noteInput.SetValue(m.notes[m.profiles[m.cursor].Name])
+			m.noteInput.CursorEnd()
+			return m, m.noteInput.Focus()
+		case m.keys.Matches(actionSelect, key) && m.deleteMode:
+			m.confirmingDelete = true
+			return m, nil
+		case m.keys.Matches(actionSelect, key) && m.renameMode:
+			m.renaming = true
+			m.renameInput.SetValue(m.profiles[m.cursor].Name)
+			m.renameInput.CursorEnd()
+			return m, m.renameInput.Focus()
+		case m.keys.Matches(actionSelect, key):
+			if len(m.profiles) > 0 {
+				return m.selectProfile(m.profiles[m.cursor])
+			}
+			m.quitting = true
+			return m, tea.Quit
+		}
+
+	default:
+		// メモと名前の入力欄のカーソル点滅などを処理
+		if m.editingNote {
+			var cmd tea.Cmd
+			m.noteInput, cmd = m.noteInput.Update(msg)
+			return m, cmd
+		}
+		if m.renaming {
+			var cmd tea.Cmd
+			m.renameInput, cmd = m.renameInput.Update(msg)
+			return m, cmd
+		}
+		if m.searching {
+			var cmd tea.Cmd
+			m.searchInput, cmd = m.searchInput.Update(msg)
+			return m, cmd
+		}
+		if m.enteringMFA {
+			var cmd tea.Cmd
+			m.mfaInput, cmd = m.mfaInput.Update(msg)
+			return m, cmd
+		}
+	}
+	return m, nil
+}
+
+// updateErrored は読み込みなどでエラーが発生した後のメッセージを処理します。
+// エラーの画面はプロファイルの一覧を表示しないため、WindowSizeMsg を含め終了キー以外のメッセージは全て無視し、
+// プロファイルの一覧に依存するカーソルやスクロール位置の計算を行わないようにします。
+func (m model) updateErrored(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok && m.keys.Matches(actionQuit, keyMsg.String()) {
+		m.quitting = true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// updateNoteInput はメモの編集中のキー入力を処理します。
+// Enter でカーソル位置のプロファイルのメモを保存し、Esc で編集を取り消します。
+func (m model) updateNoteInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		m.editingNote = false
+		m.noteInput.Blur()
+
+		name := m.profiles[m.cursor].Name
+		note := strings.TrimSpace(m.noteInput.Value())
+		path, err := notesPath()
+		if err == nil {
+			err = saveNote(path, name, note)
+		}
+		if err != nil {
+			m.toast = err.Error()
+		} else {
+			if note == "" {
+				delete(m.notes, name)
+			} else {
+				m.notes[name] = note
+			}
+			m.toast = "メモを保存しました"
+		}
+		m.toastID++
+		return m, clearToastCmd(m.toastID)
+
+	case "esc", "ctrl+c":
+		m.editingNote = false
+		m.noteInput.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.noteInput, cmd = m.noteInput.Update(msg)
+	return m, cmd
+}
+
+// renderProfileList はプロファイル一覧タブのリストを描画します。
+// ウィンドウ幅に余裕があれば、右側にカーソル位置のプロファイルのプレビューを表示します。
+func (m model) renderProfileList() string {
+	if m.columnCount() > 1 {
+		return m.renderColumns()
+	}
+
+	var s strings.Builder
+	start := m.scrollOffset
+	end := m.scrollOffset + m.listVisibleHeight
+	if end > len(m.profiles) {
+		end = len(m.profiles)
+	}
+	if start > end { // リストが非常に短いか空の場合の安全策
+		start = end
+	}
+
+	// 縞模様の背景色を行末まで伸ばすときの行の幅
+	width := m.innerWidth()
+	// 一覧の下にプレビューペインを置く場合は、右側には表示しない
+	showPreview := width >= previewMinWidth && m.previewHeight == 0
+	rowWidth := width
+	if showPreview {
+		rowWidth = width/2 - 1
+	}
+
+	var rows []string
+	for i := start; i < end; i++ {
+		// プロファイルリストが空でないことを確認 (start/end 計算後だが念のため)
+		if i < 0 || i >= len(m.profiles) {
+			continue
+		}
+		p := m.profiles[i]
+		row := m.rowStyle(i, m.rowBaseStyle(i))
+		rows = append(rows, row.renderProfileRow(p, i == m.cursor, m.isActive(p), m.roleArnMode.shows(i == m.cursor), rowWidth))
+	}
+
+	// ウィンドウ幅に余裕があれば、右側にカーソル位置のプロファイルのプレビューを表示
+	if showPreview {
+		listWidth := width / 2
+		s.WriteString(lipgloss.JoinHorizontal(lipgloss.Top,
+			renderListColumn(rows, listWidth),
+			renderPreview(m.profiles[m.cursor], profilesByName(m.allProfiles), m.notes[m.profiles[m.cursor].Name], width-listWidth, m.listVisibleHeight, m.theme)) + "\n")
+	} else {
+		for _, row := range rows {
+			s.WriteString(row + "\n")
+		}
+	}
+	return s.String()
+}
+
+// renderSourceTabs はタイトルの横に表示する読み込み元の切り替えタブを描画します。
+func renderSourceTabs(active profileSource, th theme) string {
+	activeStyle := th.style().Bold(true).Underline(true).Foreground(th.accent)
+	inactiveStyle := th.style().Faint(true)
+
+	var tabs []string
+	for _, source := range []profileSource{sourceConfig, sourceCredentials} {
+		if source == active {
+			tabs = append(tabs, activeStyle.Render(string(source)))
+		} else {
+			tabs = append(tabs, inactiveStyle.Render(string(source)))
+		}
+	}
+	return strings.Join(tabs, inactiveStyle.Render(" | "))
+}
+
+// selectedAWSProfile は選択したプロファイルの情報を返します。
+// 選択履歴から選んだプロファイルが読み込んだプロファイルに含まれない場合は、名前だけを持つプロファイルを返します。
+func (m model) selectedAWSProfile() awsProfile {
+	if m.allowUnset && m.selectedProfile == unsetProfileName {
+		return unsetProfile()
+	}
+	if m.mfaProfile.Name != "" && m.mfaProfile.Name == m.selectedProfile {
+		return m.mfaProfile
+	}
+	if p, ok := profilesByName(m.allProfiles)[m.selectedProfile]; ok {
+		return p
+	}
+	return awsProfile{Name: m.selectedProfile}
+}
+
+// recordSelection は選択したプロファイルを選択履歴と最終選択日時に記録します。
+// 記録に失敗してもプロファイルの選択自体は続行します。
+func recordSelection(profileName string, quiet bool) {
+	now := time.Now()
+	path, err := historyPath()
+	if err == nil {
+		err = recordHistory(path, profileName, now)
+	}
+	if err != nil {
+		logErr(quiet, "警告: %v\n", err)
+	}
+
+	path, err = lastUsedPath()
+	if err == nil {
+		err = recordLastUsed(path, profileName, now)
+	}
+	if err != nil {
+		logErr(quiet, "警告: %v\n", err)
+	}
+}
+
+// logErr は quiet が false (--quiet が指定されていない) なら、メッセージを標準エラー出力に書き込みます。
+// 選択画面の描画と --dry-run の出力はこの対象ではありません。
+func logErr(quiet bool, format string, args ...any) {
+	if quiet {
+		return
+	}
+	fmt.Fprintf(os.Stderr, format, args...)
+}
+
+// Main は aws-profile-selector コマンドを os.Args の引数で実行し、終了コードで os.Exit を呼び出して終了します。
+func Main() {
+	setupStyleRenderer(false)
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "export":
+			os.Exit(runExport(os.Args[2:]))
+		case "import":
+			os.Exit(runImport(os.Args[2:]))
+		case "create":
+			os.Exit(runCreate(os.Args[2:]))
+		case "delete":
+			os.Exit(runDelete(os.Args[2:]))
+		case "rename":
+			os.Exit(runRename(os.Args[2:]))
+		case "clone":
+			os.Exit(runClone(os.Args[2:]))
+		case "benchmark":
+			os.Exit(runBenchmarkCommand(os.Args[2:]))
+		}
+	}
+
+	opts, err := parseOptions(os.Args[1:])
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		os.Exit(2)
+	}
+
+	// --print-init が指定された場合はシェル関数の定義を出力して終了
+	if opts.printInit != "" {
+		command, err := os.Executable()
+		if err != nil {
+			command = os.Args[0]
+		}
+		fmt.Print(shellInitScript(opts.printInit, command))
+		os.Exit(0)
+	}
+
+	// --completion が指定された場合は補完スクリプトを出力して終了
+	if opts.completion != "" {
+		command, err := os.Executable()
+		if err != nil {
+			command = os.Args[0]
+		}
+		fmt.Print(completionScript(opts.completion, filepath.Base(os.Args[0]), command))
+		os.Exit(0)
+	}
+
+	os.Exit(runSelect(opts))
+}
+
+// runSelect はプロファイルを選択し、選択結果を出力します。終了コードを返します。
+// --index または --dry-run が指定された場合は TUI を起動せずに選択します。
+func runSelect(opts options) int {
+	renderer := setupStyleRenderer(opts.noColor)
+	// --config が指定されていない場合は、パイプで渡された標準入力を設定ファイルとして読み込めるようにする
+	opts.stdinConfig = len(opts.configPaths) == 0
+
+	if opts.check {
+		return runCheck(opts)
+	}
+	if opts.list {
+		return runList(opts)
+	}
+
+	// --clipboard-only の場合は、プロファイルを選択してからコピーに失敗しないように先に確認する
+	if opts.clipboardOnly {
+		if len(opts.execCommand) > 0 {
+			logErr(opts.quiet, "エラー: --clipboard-only と --exec は同時に指定できません\n")
+			return 2
+		}
+		if _, err := clipboardCommand(); err != nil {
+			logErr(opts.quiet, "エラー: %v\n", err)
+			return 1
+		}
+	}
+
+	// プロファイルの設定の解除は環境変数の削除としてしか出力できない
+	if opts.allowUnset && (opts.envrcFile != "" || opts.clipboardOnly) {
+		logErr(opts.quiet, "エラー: --allow-unset は --envrc-file や --clipboard-only と同時に指定できません\n")
+		return 2
+	}
+
+	if opts.profileOnly && opts.template != nil {
+		logErr(opts.quiet, "エラー: --profile-only と --template は同時に指定できません\n")
+		return 2
+	}
+
+	// --fd が指定された場合は、TUI を起動する前に書き込めるか確認
+	var resultFD *os.File
+	if opts.fd != nil {
+		var err error
+		resultFD, err = openResultFD(*opts.fd)
+		if err != nil {
+			logErr(opts.quiet, "エラー: %v\n", err)
+			return 1
+		}
+	}
+
+	// ラッパースクリプトから呼ばれた場合は export キーワードなしで出力する
+	format := newOutputFormat(opts)
+
+	// --index または --dry-run が指定された場合は TUI を起動せずにプロファイルを選択
+	// --first の場合は、絞り込みの結果が 1 件のときだけ TUI を起動せずに選択し、複数あれば TUI で選択する
+	if opts.index != nil || opts.dryRun || opts.first {
+		profiles, err := loadSortedProfiles(opts.sources(), opts.sort, loadHistoryOrEmpty())
+		if err != nil {
+			logErr(opts.quiet, "エラー: %s\n", describeLoadError(err))
+			return 1
+		}
+		profiles = opts.filter.apply(profiles)
+		if opts.index != nil || opts.dryRun || len(profiles) <= 1 {
+			return selectWithoutTUI(profiles, opts, format, resultFD)
+		}
+	}
+
+	// --interactive=false の場合は、選択画面を起動せずにプロファイルを読み込めるかだけを確認する
+	if !opts.interactive {
+		return runSmokeTest(opts)
+	}
+	// パイプラインや CI などで端末がない場合は、選択画面を起動せずに起動時のカーソル位置のプロファイルを選択する
+	if !interactiveTerminal() {
+		return selectWithoutTerminal(opts, format, resultFD)
+	}
+
+	programOpts := []tea.ProgramOption{tea.WithOutput(os.Stderr)}
+	if !opts.noAltScreen {
+		programOpts = append(programOpts, tea.WithAltScreen())
+	}
+	// 標準入力がパイプの場合はキー入力を端末から直接読み込む
+	if stdinIsPiped() {
+		programOpts = append(programOpts, tea.WithInputTTY())
+	}
+	program := tea.NewProgram(initialModel(opts, renderer), programOpts...)
+
+	finalModel, err := program.Run()
+	if err != nil {
+		logErr(opts.quiet, "CLIアプリケーションの実行に失敗しました: %v\n", err)
+		return 1
+	}
+
+	m, ok := finalModel.(model)
+	if !ok {
+		logErr(opts.quiet, "モデルの型変換中に予期せぬエラーが発生しました。\n")
+		return 1
+	}
+
+	if m.err != nil {
+		logErr(opts.quiet, "エラー: %s\n", describeLoadError(m.err))
+		return 1
+	}
+
+	if m.selectedProfile != "" && !m.quitting {
+		p := m.selectedAWSProfile()
+		if !p.Unset {
+			recordSelection(p.Name, opts.quiet)
+		}
+		return deliverSelection(p, opts, format, resultFD)
+	}
+
+	if m.selectedProfile == "" && (m.quitting || len(m.profiles) == 0) {
+		if len(m.profiles) == 0 && !m.quitting {
+			logErr(opts.quiet, "利用可能なAWSプロファイルがありませんでした。\n")
+		} else if m.quitting {
+			logErr(opts.quiet, "プロファイルの選択がキャンセルされました。\n")
+		}
+		return 1
+	}
+	return 1
+}
+
+// selectWithoutTUI は TUI を起動せずに、絞り込み済みの profiles から --index などで決まるプロファイルを選択して出力します。終了コードを返します。
+func selectWithoutTUI(profiles []awsProfile, opts options, format outputFormat, resultFD *os.File) int {
+	i, err := resolveSelection(opts.index, len(profiles))
+	if err != nil {
+		logErr(opts.quiet, "エラー: %v\n", err)
+		return 1
+	}
+
+	// --dry-run の場合は出力する予定のコマンドを標準エラー出力に表示するだけで、選択履歴にも記録しない
+	if opts.dryRun {
+		out, err := formatSelection(profiles[i], format)
+		if err != nil {
+			logErr(opts.quiet, "エラー: %v\n", err)
+			return 1
+		}
+		for _, line := range strings.Split(out, "\n") {
+			fmt.Fprintf(os.Stderr, "[dry-run] %s\n", line)
+		}
+		return 0
+	}
+
+	// TUI と同じように、mfa_serial のあるプロファイルは MFA コードで取得した一時的な認証情報のプロファイルを選択する
+	p, err := passMFAGate(opts.sources(), profiles[i])
+	if err != nil {
+		logErr(opts.quiet, "エラー: %v\n", err)
+		return 1
+	}
+	recordSelection(p.Name, opts.quiet)
+	return deliverSelection(p, opts, format, resultFD)
+}
+
+// deliverSelection は選択したプロファイルを出力します。終了コードを返します。
+// --exec が指定された場合は、出力する代わりにそのプロファイルでコマンドを実行し、
+// --envrc-file が指定された場合は、出力する代わりに direnv の .envrc ファイルに export 文を書き込み、
+// --clipboard-only が指定された場合は、出力する代わりにプロファイル名をクリップボードにコピーします。
+func deliverSelection(p awsProfile, opts options, format outputFormat, resultFD *os.File) int {
+	if len(opts.execCommand) > 0 {
+		return runExec(p, opts)
+	}
+	if opts.envrcFile != "" {
+		if err := writeEnvrc(opts.envrcFile, profileExports(p, opts.envPrefix), opts.envrcOverwrite); err != nil {
+			logErr(opts.quiet, "エラー: %v\n", err)
+			return 1
+		}
+		logErr(opts.quiet, "'%s' を設定する export 文を %s に書き込みました。\n", p.Name, opts.envrcFile)
+		return 0
+	}
+	if opts.clipboardOnly {
+		if err := writeClipboard(p.Name); err != nil {
+			logErr(opts.quiet, "エラー: クリップボードへのコピーに失敗しました: %v\n", err)
+			return 1
+		}
+		logErr(opts.quiet, "'%s' をクリップボードにコピーしました。\n", p.Name)
+		return 0
+	}
+	if err := writeSelection(os.Stdout, resultFD, p, format); err != nil {
+		logErr(opts.quiet, "エラー: %v\n", err)
+		return 1
+	}
+	// シェルにプロファイルを設定する場合だけ、プロンプトが読む現在のプロファイルを更新する
+	saveCurrentProfile(p, opts.quiet)
+	return 0
+}
@@ -0,0 +1,550 @@
+package profileselector
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// newTestModel は一時的な設定ディレクトリを使って初期化し、profiles を読み込み済みのモデルを返します。
+// 利用者の選択履歴やキーバインドの設定を読み書きしないよう、XDG_CONFIG_HOME と AWS の設定ファイルのパスを一時ディレクトリに向けます。
+func newTestModel(t *testing.T, opts options, profiles []awsProfile) model {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "aws", "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "aws", "credentials"))
+	t.Setenv("AWS_DEFAULT_PROFILE", "")
+	if err := os.MkdirAll(filepath.Join(dir, toolDirName), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	m := initialModel(opts, newStyleRenderer(io.Discard, true))
+	m.loading = false
+	m = m.withLoadedProfiles(profiles, nil)
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+	return next.(model)
+}
+
+// keyPress はキーの名前 (tea.KeyMsg.String() の値) から tea.KeyMsg を作ります。
+func keyPress(name string) tea.KeyMsg {
+	if r, ok := strings.CutPrefix(name, "alt+"); ok {
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(r), Alt: true}
+	}
+	switch name {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "up":
+		return tea.KeyMsg{Type: tea.KeyUp}
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	case "left":
+		return tea.KeyMsg{Type: tea.KeyLeft}
+	case "home":
+		return tea.KeyMsg{Type: tea.KeyHome}
+	case "tab":
+		return tea.KeyMsg{Type: tea.KeyTab}
+	case "backspace":
+		return tea.KeyMsg{Type: tea.KeyBackspace}
+	case "ctrl+p":
+		return tea.KeyMsg{Type: tea.KeyCtrlP}
+	case "ctrl+n":
+		return tea.KeyMsg{Type: tea.KeyCtrlN}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(name)}
+}
+
+// pressKeys は m に keys のキー入力を順に送り、最後のモデルを返します。
+func pressKeys(t *testing.T, m model, keys ...string) model {
+	t.Helper()
+	var next tea.Model = m
+	for _, k := range keys {
+		next, _ = next.Update(keyPress(k))
+	}
+	result, ok := next.(model)
+	if !ok {
+		t.Fatalf("Update() が model 以外の %T を返しました", next)
+	}
+	return result
+}
+
+// testProfiles は config から読み込んだ name のプロファイルの一覧を返します。
+func testProfiles(names ...string) []awsProfile {
+	profiles := make([]awsProfile, len(names))
+	for i, name := range names {
+		profiles[i] = awsProfile{Name: name, Source: sourceConfig, Order: i}
+	}
+	return profiles
+}
+
+// profileNames はプロファイルの名前の一覧を返します。
+func profileNames(profiles []awsProfile) []string {
+	var names []string
+	for _, p := range profiles {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+func TestResolveConfigAndCredentialsPath(t *testing.T) {
+	home, err := userHomeDir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		env     string
+		resolve func() (string, error)
+		value   string
+		want    string
+	}{
+		{name: "config の既定", env: configFileEnv, resolve: resolveConfigPath, want: filepath.Join(home, ".aws", "config")},
+		{name: "AWS_CONFIG_FILE", env: configFileEnv, resolve: resolveConfigPath, value: "/etc/aws/config", want: "/etc/aws/config"},
+		{name: "AWS_CONFIG_FILE の ~ を展開", env: configFileEnv, resolve: resolveConfigPath, value: "~/work/config", want: filepath.Join(home, "work", "config")},
+		{name: "credentials の既定", env: credentialsFileEnv, resolve: resolveCredentialsPath, want: filepath.Join(home, ".aws", "credentials")},
+		{name: "AWS_SHARED_CREDENTIALS_FILE", env: credentialsFileEnv, resolve: resolveCredentialsPath, value: "/etc/aws/credentials", want: "/etc/aws/credentials"},
+		{name: "AWS_SHARED_CREDENTIALS_FILE の ~ を展開", env: credentialsFileEnv, resolve: resolveCredentialsPath, value: "~/work/credentials", want: filepath.Join(home, "work", "credentials")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(tt.env, tt.value)
+			got, err := tt.resolve()
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("path = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadAWSProfilesMergesConfigAndCredentials(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	files := map[string]string{
+		"config":      "[profile dev]\nregion = ap-northeast-1\n",
+		"credentials": "[dev]\naws_access_key_id = AKIAEXAMPLE\naws_secret_access_key = secret\n\n[ci]\naws_access_key_id = AKIAEXAMPLE\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv(configFileEnv, filepath.Join(dir, "config"))
+	t.Setenv(credentialsFileEnv, filepath.Join(dir, "credentials"))
+
+	profiles, err := loadAWSProfiles(profileSources{quiet: true})
+	if err != nil {
+		t.Fatalf("loadAWSProfiles() error = %v", err)
+	}
+	var got []string
+	for _, p := range profiles {
+		got = append(got, string(p.Source)+":"+p.Name)
+	}
+	want := []string{"config:dev", "credentials:dev", "credentials:ci"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("読み込んだプロファイル = %v, want %v", got, want)
+	}
+}
+
+func TestLoadAWSProfilesRecordsOrder(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	files := map[string]string{
+		"config":      "[profile prod]\n[default]\n[profile dev]\n",
+		"credentials": "[dev]\naws_access_key_id = AKIAEXAMPLE\n\n[ci]\naws_access_key_id = AKIAEXAMPLE\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv(configFileEnv, filepath.Join(dir, "config"))
+	t.Setenv(credentialsFileEnv, filepath.Join(dir, "credentials"))
+
+	profiles, err := loadAWSProfiles(profileSources{quiet: true})
+	if err != nil {
+		t.Fatalf("loadAWSProfiles() error = %v", err)
+	}
+	tests := []struct {
+		key  string
+		want int
+	}{
+		{key: "config:prod", want: 0},
+		{key: "config:default", want: 1},
+		{key: "config:dev", want: 2},
+		{key: "credentials:dev", want: 3},
+		{key: "credentials:ci", want: 4},
+	}
+	orders := make(map[string]int, len(profiles))
+	for _, p := range profiles {
+		orders[string(p.Source)+":"+p.Name] = p.Order
+	}
+	for _, tt := range tests {
+		if got, ok := orders[tt.key]; !ok || got != tt.want {
+			t.Errorf("%s の Order = %d (読み込み: %v), want %d", tt.key, got, ok, tt.want)
+		}
+	}
+}
+
+func TestReloadKeepsCursorOnSameProfile(t *testing.T) {
+	tests := []struct {
+		name       string
+		before     string
+		after      string
+		wantNames  []string
+		wantCursor string
+	}{
+		{
+			name:       "前にプロファイルが増えてもカーソルは同じプロファイル",
+			before:     "[profile dev]\n[profile prod]\n",
+			after:      "[profile alpha]\n[profile dev]\n[profile prod]\n",
+			wantNames:  []string{"alpha", "dev", "prod"},
+			wantCursor: "prod",
+		},
+		{
+			name:       "カーソル位置のプロファイルが消えたら範囲内に収める",
+			before:     "[profile dev]\n[profile prod]\n",
+			after:      "[profile dev]\n",
+			wantNames:  []string{"dev"},
+			wantCursor: "dev",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := filepath.Join(t.TempDir(), "config")
+			if err := os.WriteFile(config, []byte(tt.before), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			opts := options{configPaths: []string{config}, sort: sortAlpha}
+			profiles, err := loadSortedProfiles(opts.sources(), sortAlpha, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m := newTestModel(t, opts, profiles)
+			m = pressKeys(t, m, "j")
+
+			if err := os.WriteFile(config, []byte(tt.after), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			next, cmd := m.Update(keyPress("r"))
+			if cmd == nil {
+				t.Fatal("r で再読み込みのコマンドが返されませんでした")
+			}
+			msg, ok := cmd().(profilesLoadedMsg)
+			if !ok {
+				t.Fatalf("再読み込みのコマンドが profilesLoadedMsg 以外を返しました")
+			}
+			next, _ = next.Update(msg)
+			m = next.(model)
+
+			if got := profileNames(m.profiles); strings.Join(got, ",") != strings.Join(tt.wantNames, ",") {
+				t.Errorf("再読み込み後のプロファイル = %v, want %v", got, tt.wantNames)
+			}
+			if got := m.profiles[m.cursor].Name; got != tt.wantCursor {
+				t.Errorf("カーソル位置のプロファイル = %q, want %q", got, tt.wantCursor)
+			}
+		})
+	}
+}
+
+// captureOutput は f の実行中に標準出力と標準エラー出力に書き込まれた内容を返します。
+func captureOutput(t *testing.T, f func()) (stdout, stderr string) {
+	t.Helper()
+	read := func(target **os.File) func() string {
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		orig := *target
+		*target = w
+		done := make(chan string)
+		go func() {
+			data, _ := io.ReadAll(r)
+			done <- string(data)
+		}()
+		return func() string {
+			*target = orig
+			w.Close()
+			return <-done
+		}
+	}
+	finishStdout, finishStderr := read(&os.Stdout), read(&os.Stderr)
+	defer lipgloss.SetDefaultRenderer(lipgloss.DefaultRenderer())
+	f()
+	return finishStdout(), finishStderr()
+}
+
+func TestRunSelectDryRun(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv(credentialsFileEnv, filepath.Join(dir, "credentials"))
+	t.Setenv(noExportEnv, "")
+	config := filepath.Join(dir, "config")
+	if err := os.WriteFile(config, []byte("[profile dev]\n[profile prod]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStderr string
+	}{
+		{name: "--index", args: []string{"--dry-run", "--index", "1"}, wantStderr: "[dry-run] export AWS_DEFAULT_PROFILE=prod\n"},
+		{name: "負の --index", args: []string{"--dry-run", "--index", "-2"}, wantStderr: "[dry-run] export AWS_DEFAULT_PROFILE=dev\n"},
+		{name: "絞り込みで 1 件", args: []string{"--dry-run", "--profile-prefix", "pr"}, wantStderr: "[dry-run] export AWS_DEFAULT_PROFILE=prod\n"},
+		{name: "1 件に決まらない", args: []string{"--dry-run"}, wantCode: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseOptionsTo(io.Discard, append([]string{"--config", config}, tt.args...))
+			if err != nil {
+				t.Fatalf("parseOptionsTo() error = %v", err)
+			}
+			var code int
+			stdout, stderr := captureOutput(t, func() { code = runSelect(opts) })
+			if code != tt.wantCode {
+				t.Errorf("終了コード = %d, want %d (stderr: %s)", code, tt.wantCode, stderr)
+			}
+			if stdout != "" {
+				t.Errorf("標準出力 = %q, want 空", stdout)
+			}
+			if tt.wantStderr != "" && stderr != tt.wantStderr {
+				t.Errorf("標準エラー出力 = %q, want %q", stderr, tt.wantStderr)
+			}
+			if history, err := loadHistory(filepath.Join(dir, toolDirName, "history.json")); err == nil && len(history) > 0 {
+				t.Errorf("--dry-run で選択履歴が記録されました: %v", history)
+			}
+		})
+	}
+}
+
+func TestRoleArnModeShows(t *testing.T) {
+	tests := []struct {
+		mode     roleArnMode
+		atCursor bool
+		want     bool
+	}{
+		{mode: roleArnHidden, atCursor: true, want: false},
+		{mode: roleArnHidden, atCursor: false, want: false},
+		{mode: roleArnCursor, atCursor: true, want: true},
+		{mode: roleArnCursor, atCursor: false, want: false},
+		{mode: roleArnAll, atCursor: true, want: true},
+		{mode: roleArnAll, atCursor: false, want: true},
+	}
+	for _, tt := range tests {
+		if got := tt.mode.shows(tt.atCursor); got != tt.want {
+			t.Errorf("%s.shows(%v) = %v, want %v", tt.mode, tt.atCursor, got, tt.want)
+		}
+	}
+}
+
+func TestToggleRoleArnDisplay(t *testing.T) {
+	profiles := testProfiles("dev", "prod", "stg")
+	for i := range profiles {
+		profiles[i].RoleArn = "arn:aws:iam::123456789012:role/" + profiles[i].Name
+	}
+	tests := []struct {
+		name      string
+		opts      options
+		keys      []string
+		wantRoles int // RoleARN を表示する行の数
+	}{
+		{name: "初期状態は表示しない", wantRoles: 0},
+		{name: "1 回でカーソル位置の行だけ", keys: []string{"v"}, wantRoles: 1},
+		{name: "2 回で全ての行", keys: []string{"v", "v"}, wantRoles: 3},
+		{name: "3 回で表示しない状態に戻る", keys: []string{"v", "v", "v"}, wantRoles: 0},
+		{name: "--show-all-roles では最初から全ての行", opts: options{showAllRoles: true}, wantRoles: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t, tt.opts, profiles)
+			m = pressKeys(t, m, tt.keys...)
+			if got := strings.Count(m.renderProfileList(), "(RoleARN: "); got != tt.wantRoles {
+				t.Errorf("RoleARN を表示した行 = %d, want %d", got, tt.wantRoles)
+			}
+		})
+	}
+}
+
+func TestReadFileError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "権限がない",
+			err:  &fs.PathError{Op: "open", Path: "/home/user/.aws/config", Err: fs.ErrPermission},
+			want: "設定ファイル は存在しますが読み込めません。ファイルの権限を確認してください (例: chmod 600 /home/user/.aws/config)",
+		},
+		{
+			name: "その他のエラー",
+			err:  &fs.PathError{Op: "read", Path: "/home/user/.aws/config", Err: errors.New("is a directory")},
+			want: "設定ファイル の読み込みに失敗しました",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := readFileError("設定ファイル", "/home/user/.aws/config", tt.err)
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("readFileError() = %q, want %q を含む", err, tt.want)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("readFileError() が元のエラーを包んでいません: %v", err)
+			}
+			if screen := renderErrorScreen(err, darkTheme); !strings.Contains(screen, tt.want) {
+				t.Errorf("エラー画面に %q がありません:\n%s", tt.want, screen)
+			}
+		})
+	}
+}
+
+func TestLoadAWSProfilesUnreadableConfig(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root ではファイルの権限に関係なく読み込めるため省略します")
+	}
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv(credentialsFileEnv, filepath.Join(dir, "credentials"))
+	config := filepath.Join(dir, "config")
+	if err := os.WriteFile(config, []byte("[profile dev]\n"), 0o000); err != nil {
+		t.Fatal(err)
+	}
+	_, err := loadAWSProfiles(profileSources{configPaths: []string{config}, quiet: true})
+	if !errors.Is(err, fs.ErrPermission) || !strings.Contains(err.Error(), "ファイルの権限を確認してください") {
+		t.Errorf("loadAWSProfiles() error = %v, want 権限の確認を促すエラー", err)
+	}
+}
+
+func TestRunSelectFirst(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv(credentialsFileEnv, filepath.Join(dir, "credentials"))
+	t.Setenv(noExportEnv, "")
+	config := filepath.Join(dir, "config")
+	if err := os.WriteFile(config, []byte("[profile dev]\n[profile prod]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStdout string
+	}{
+		{name: "1 件に一致", args: []string{"--first", "--profile-prefix", "pr"}, wantStdout: "export AWS_DEFAULT_PROFILE=prod\n"},
+		{name: "一致なし", args: []string{"--first", "--profile-prefix", "qa"}, wantCode: 1},
+		// 複数に一致する場合は選択せずに選択画面の起動へ進む (--interactive=false で起動の直前に止める)
+		{name: "複数に一致", args: []string{"--first", "--interactive=false"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseOptionsTo(io.Discard, append([]string{"--config", config}, tt.args...))
+			if err != nil {
+				t.Fatalf("parseOptionsTo() error = %v", err)
+			}
+			var code int
+			stdout, stderr := captureOutput(t, func() { code = runSelect(opts) })
+			if code != tt.wantCode {
+				t.Errorf("終了コード = %d, want %d (stderr: %s)", code, tt.wantCode, stderr)
+			}
+			if stdout != tt.wantStdout {
+				t.Errorf("標準出力 = %q, want %q", stdout, tt.wantStdout)
+			}
+		})
+	}
+}
+
+func TestRunSelectQuiet(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv(credentialsFileEnv, filepath.Join(dir, "credentials"))
+	t.Setenv(noExportEnv, "")
+	config := filepath.Join(dir, "config")
+	if err := os.WriteFile(config, []byte("[profile dev]\n[profile prod]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStdout string
+		wantStderr bool // --quiet なしで標準エラー出力にメッセージを表示するか
+	}{
+		{name: "選択に成功", args: []string{"--index", "0"}, wantStdout: "export AWS_DEFAULT_PROFILE=dev\n"},
+		{name: "範囲外の --index", args: []string{"--index", "5"}, wantCode: 1, wantStderr: true},
+		{name: "存在しない設定ファイル", args: []string{"--config", filepath.Join(dir, "missing"), "--index", "0"}, wantCode: 1, wantStderr: true},
+		{name: ".envrc に書き込む", args: []string{"--index", "1", "--envrc-file", filepath.Join(dir, ".envrc")}, wantStderr: true},
+	}
+	for _, tt := range tests {
+		for _, quiet := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/quiet=%v", tt.name, quiet), func(t *testing.T) {
+				args := append([]string{"--config", config}, tt.args...)
+				if quiet {
+					args = append(args, "--quiet")
+				}
+				opts, err := parseOptionsTo(io.Discard, args)
+				if err != nil {
+					t.Fatalf("parseOptionsTo() error = %v", err)
+				}
+				var code int
+				stdout, stderr := captureOutput(t, func() { code = runSelect(opts) })
+				if code != tt.wantCode {
+					t.Errorf("終了コード = %d, want %d", code, tt.wantCode)
+				}
+				if stdout != tt.wantStdout {
+					t.Errorf("標準出力 = %q, want %q", stdout, tt.wantStdout)
+				}
+				if want := tt.wantStderr && !quiet; (stderr != "") != want {
+					t.Errorf("標準エラー出力 = %q, 表示するか want %v", stderr, want)
+				}
+			})
+		}
+	}
+}
+
+func TestErroredModelIgnoresMessages(t *testing.T) {
+	tests := []struct {
+		name     string
+		msgs     []tea.Msg
+		wantQuit bool
+	}{
+		{name: "ウィンドウサイズの変更", msgs: []tea.Msg{tea.WindowSizeMsg{Width: 120, Height: 40}, tea.WindowSizeMsg{Width: 10, Height: 2}}},
+		{name: "カーソルの移動", msgs: []tea.Msg{keyPress("down"), keyPress("G"), keyPress("up")}},
+		{name: "再読み込みの完了", msgs: []tea.Msg{profilesLoadedMsg{profiles: testProfiles("dev")}}},
+		{name: "終了キー", msgs: []tea.Msg{tea.WindowSizeMsg{Width: 120, Height: 40}, keyPress("q")}, wantQuit: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t, options{}, nil).withLoadedProfiles(nil, errors.New("読み込みに失敗しました"))
+			before := m
+			var next tea.Model = m
+			var cmd tea.Cmd
+			for _, msg := range tt.msgs {
+				next, cmd = next.Update(msg)
+			}
+			got := next.(model)
+			if got.quitting != tt.wantQuit || (cmd != nil) != tt.wantQuit {
+				t.Errorf("quitting = %v (コマンドあり: %v), want %v", got.quitting, cmd != nil, tt.wantQuit)
+			}
+			if got.cursor != before.cursor || got.scrollOffset != before.scrollOffset || got.listVisibleHeight != before.listVisibleHeight {
+				t.Errorf("cursor, scrollOffset, listVisibleHeight = %d, %d, %d, want %d, %d, %d",
+					got.cursor, got.scrollOffset, got.listVisibleHeight, before.cursor, before.scrollOffset, before.listVisibleHeight)
+			}
+			if len(got.profiles) != 0 {
+				t.Errorf("エラーの後にプロファイルを読み込みました: %v", profileNames(got.profiles))
+			}
+		})
+	}
+}
@@ -0,0 +1,262 @@
+package profileselector
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// mfaTag は mfa_serial を設定したプロファイルに付ける印です。
+const mfaTag = "🔒"
+
+// mfaTimeout は MFA コードで一時的な認証情報を取得するときの制限時間です。
+const mfaTimeout = 30 * time.Second
+
+// mfaProfileSuffix は取得した一時的な認証情報を保存するプロファイル名に付ける接尾辞です。
+const mfaProfileSuffix = "-mfa"
+
+// mfaCodePattern は MFA コードとして受け付ける文字列 (6 桁の数字) です。
+var mfaCodePattern = regexp.MustCompile(`^[0-9]{6}$`)
+
+// stsCredentials は aws sts get-session-token / assume-role で取得した一時的な認証情報です。
+type stsCredentials struct {
+	AccessKeyID     string `json:"AccessKeyId"`
+	SecretAccessKey string `json:"SecretAccessKey"`
+	SessionToken    string `json:"SessionToken"`
+	Expiration      string `json:"Expiration"`
+}
+
+// mfaResultMsg は MFA コードによる認証情報の取得が完了したときに送られるメッセージです。
+type mfaResultMsg struct {
+	profile awsProfile // 一時的な認証情報を保存したプロファイル
+	err     error      // 取得や保存で発生したエラー
+}
+
+// newMFAInput は MFA コードの入力欄を生成します。
+func newMFAInput() textinput.Model {
+	input := textinput.New()
+	input.Prompt = "MFA コード: "
+	input.Placeholder = "123456"
+	input.CharLimit = 6
+	return input
+}
+
+// stsArgs は MFA コード code で p の一時的な認証情報を取得する aws コマンドの引数を返します。
+// role_arn のあるプロファイルは source_profile の認証情報でロールを引き受け、それ以外はプロファイル自身のセッショントークンを取得します。
+func stsArgs(p awsProfile, code string, now time.Time) []string {
+	if p.RoleArn != "" {
+		args := []string{"sts", "assume-role",
+			"--role-arn", p.RoleArn,
+			"--role-session-name", fmt.Sprintf("aws-profile-selector-%d", now.Unix()),
+			"--serial-number", p.MFASerial,
+			"--token-code", code,
+			"--output", "json"}
+		if source := p.RawKeys["source_profile"]; source != "" {
+			args = append(args, "--profile", source)
+		}
+		return args
+	}
+	return []string{"sts", "get-session-token",
+		"--serial-number", p.MFASerial,
+		"--token-code", code,
+		"--profile", p.Name,
+		"--output", "json"}
+}
+
+// fetchMFACredentials は aws コマンド (AWS CLI) を実行し、MFA コード code で p の一時的な認証情報を取得します。
+func fetchMFACredentials(ctx context.Context, p awsProfile, code string) (stsCredentials, error) {
+	cmd := exec.CommandContext(ctx, "aws", stsArgs(p, code, time.Now())...)
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return stsCredentials{}, fmt.Errorf("一時的な認証情報の取得に失敗しました: %s (プロファイル: %s)", strings.TrimSpace(string(exitErr.Stderr)), p.Name)
+		}
+		return stsCredentials{}, fmt.Errorf("一時的な認証情報の取得に失敗しました: %w (プロファイル: %s)", err, p.Name)
+	}
+
+	var resp struct {
+		Credentials stsCredentials `json:"Credentials"`
+	}
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return stsCredentials{}, fmt.Errorf("一時的な認証情報の解析に失敗しました: %w (プロファイル: %s)", err, p.Name)
+	}
+	if resp.Credentials.AccessKeyID == "" {
+		return stsCredentials{}, fmt.Errorf("一時的な認証情報が返されませんでした (プロファイル: %s)", p.Name)
+	}
+	return resp.Credentials, nil
+}
+
+// saveMFACredentials は credentialsPath の認証情報ファイルの name のプロファイルに一時的な認証情報を保存します。
+// 既に同じ名前のプロファイルがある場合は、キーを全て置き換えます。
+func saveMFACredentials(credentialsPath, name string, creds stsCredentials) error {
+	cfg, err := loadConfigForUpdate(credentialsPath)
+	if err != nil {
+		return err
+	}
+	cfg.DeleteSection(name)
+	section, err := cfg.NewSection(name)
+	if err != nil {
+		return fmt.Errorf("セクションの作成に失敗しました: %w (プロファイル: %s)", err, name)
+	}
+	section.Key("aws_access_key_id").SetValue(creds.AccessKeyID)
+	section.Key("aws_secret_access_key").SetValue(creds.SecretAccessKey)
+	section.Key("aws_session_token").SetValue(creds.SessionToken)
+	if creds.Expiration != "" {
+		section.Key("x_expiration").SetValue(creds.Expiration)
+	}
+	return saveConfig(cfg, credentialsPath, credentialsFileMode)
+}
+
+// mfaCredentialsCmd は obtainMFAProfile で MFA コード code による p の一時的な認証情報をバックグラウンドで取得して sources の認証情報ファイルに保存し、
+// 結果を mfaResultMsg として返すコマンドです。
+func mfaCredentialsCmd(sources profileSources, p awsProfile, code string) tea.Cmd {
+	return func() tea.Msg {
+		profile, err := obtainMFAProfile(sources, p, code)
+		return mfaResultMsg{profile: profile, err: err}
+	}
+}
+
+// obtainMFAProfile は MFA コード code で p の一時的な認証情報を取得して sources の認証情報ファイルに保存し、保存したプロファイルを返します。
+// 保存先は p の名前に mfaProfileSuffix を付けたプロファイルで、region と output は p のものを引き継ぎます。
+func obtainMFAProfile(sources profileSources, p awsProfile, code string) (awsProfile, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), mfaTimeout)
+	defer cancel()
+
+	creds, err := fetchMFACredentials(ctx, p, code)
+	if err != nil {
+		return awsProfile{}, err
+	}
+	credentialsPath, err := sources.credentialsPath()
+	if err != nil {
+		return awsProfile{}, err
+	}
+	name := p.Name + mfaProfileSuffix
+	if err := saveMFACredentials(credentialsPath, name, creds); err != nil {
+		return awsProfile{}, err
+	}
+
+	rawKeys := map[string]string{}
+	for _, key := range []string{"region", "output"} {
+		if value := p.RawKeys[key]; value != "" {
+			rawKeys[key] = value
+		}
+	}
+	return newAWSProfile(name, rawKeys, sourceCredentials), nil
+}
+
+// promptMFACode は TUI を起動せずに選択する場合に、w に p の MFA コードの入力を促し、r から 1 行読み込んだコードを返します。
+// 6 桁の数字でなければエラーを返します。
+func promptMFACode(r io.Reader, w io.Writer, p awsProfile) (string, error) {
+	fmt.Fprintf(w, "'%s' の MFA コード: ", p.Name)
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("MFA コードの読み込みに失敗しました: %w", err)
+	}
+	code := strings.TrimSpace(line)
+	if !mfaCodePattern.MatchString(code) {
+		return "", fmt.Errorf("MFA コードは 6 桁の数字で入力してください")
+	}
+	return code, nil
+}
+
+// passMFAGate は TUI を起動せずにプロファイル p を選択する場合の MFA の確認です。
+// mfa_serial のないプロファイルはそのまま返し、あるプロファイルは端末 (/dev/tty) で MFA コードを入力させて、
+// 一時的な認証情報を sources の認証情報ファイルに保存したプロファイルを返します。端末がない場合は MFA を省略せずにエラーを返します。
+func passMFAGate(sources profileSources, p awsProfile) (awsProfile, error) {
+	if !p.RequiresMFA {
+		return p, nil
+	}
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return awsProfile{}, fmt.Errorf("'%s' は MFA コードの入力が必要ですが、端末がないため入力できません", p.Name)
+	}
+	defer tty.Close()
+	code, err := promptMFACode(tty, tty, p)
+	if err != nil {
+		return awsProfile{}, err
+	}
+	return obtainMFAProfile(sources, p, code)
+}
+
+// selectProfile はプロファイル p を選択して終了します。mfa_serial のあるプロファイルは、先に MFA コードの入力を始めます。
+// 一覧の Enter、数字キー、Recent タブ、--timeout による自動選択のどこから選択しても MFA コードの入力を省略できないよう、
+// TUI でのプロファイルの選択は全てここを通します。
+func (m model) selectProfile(p awsProfile) (tea.Model, tea.Cmd) {
+	if p.RequiresMFA {
+		return m.startMFAInput(p)
+	}
+	m.selectedProfile = p.Name
+	return m, tea.Quit
+}
+
+// startMFAInput はプロファイル p の MFA コードの入力を始めます。
+func (m model) startMFAInput(p awsProfile) (tea.Model, tea.Cmd) {
+	m.enteringMFA = true
+	m.mfaTarget = p
+	m.mfaInput.SetValue("")
+	return m, m.mfaInput.Focus()
+}
+
+// updateMFAInput は MFA コードの入力中のキー入力を処理します。
+// Enter で入力したコードを使って一時的な認証情報を取得し、Esc で入力を取り消します。
+func (m model) updateMFAInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.mfaPending {
+		// 認証情報の取得中は Ctrl+C による終了以外を受け付けない
+		if msg.String() == "ctrl+c" {
+			m.quitting = true
+			return m, tea.Quit
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "enter":
+		code := strings.TrimSpace(m.mfaInput.Value())
+		if !mfaCodePattern.MatchString(code) {
+			m.toast = "MFA コードは 6 桁の数字で入力してください"
+			m.toastID++
+			return m, clearToastCmd(m.toastID)
+		}
+		m.mfaPending = true
+		m.toast = ""
+		return m, mfaCredentialsCmd(m.sources, m.mfaTarget, code)
+
+	case "esc", "ctrl+c":
+		m.enteringMFA = false
+		m.mfaInput.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.mfaInput, cmd = m.mfaInput.Update(msg)
+	return m, cmd
+}
+
+// updateMFAResult は一時的な認証情報の取得結果を処理します。
+// 取得できた場合は保存したプロファイルを選択して終了し、失敗した場合はエラーを表示して入力に戻ります。
+func (m model) updateMFAResult(msg mfaResultMsg) (tea.Model, tea.Cmd) {
+	m.mfaPending = false
+	if msg.err != nil {
+		m.mfaInput.SetValue("")
+		m.toast = msg.err.Error()
+		m.toastID++
+		return m, clearToastCmd(m.toastID)
+	}
+	m.enteringMFA = false
+	m.mfaInput.Blur()
+	m.mfaProfile = msg.profile
+	m.selectedProfile = msg.profile.Name
+	return m, tea.Quit
+}
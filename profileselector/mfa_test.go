@@ -0,0 +1,298 @@
+package profileselector
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// mfaTestProfiles は MFA の必要な "secure" と必要ない "plain" のプロファイルです。
+func mfaTestProfiles() []awsProfile {
+	return []awsProfile{
+		newAWSProfile("secure", map[string]string{"mfa_serial": "arn:aws:iam::123456789012:mfa/user"}, sourceConfig),
+		newAWSProfile("plain", map[string]string{"region": "us-east-1"}, sourceConfig),
+	}
+}
+
+func TestSelectionPathsRequireMFA(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+		choose func(m model) (tea.Model, tea.Cmd)
+	}{
+		{
+			name: "一覧で Enter",
+			choose: func(m model) (tea.Model, tea.Cmd) {
+				m.cursor = profileIndex(m.profiles, "secure")
+				return m.Update(keyPress("enter"))
+			},
+		},
+		{
+			name: "Alt+数字キー",
+			choose: func(m model) (tea.Model, tea.Cmd) {
+				return m.Update(keyPress("alt+" + string(rune('1'+profileIndex(m.profiles, "secure")))))
+			},
+		},
+		{
+			name: "Recent タブで Enter",
+			choose: func(m model) (tea.Model, tea.Cmd) {
+				m.history = []historyEntry{{Profile: "secure", SelectedAt: time.Now()}}
+				m.currentTab = tabRecent
+				return m.Update(keyPress("enter"))
+			},
+		},
+		{
+			name: "--timeout による自動選択",
+			choose: func(m model) (tea.Model, tea.Cmd) {
+				m.cursor = profileIndex(m.profiles, "secure")
+				m.timeoutRemaining = 1
+				return m.updateTimeout()
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t, options{}, mfaTestProfiles())
+			next, _ := tt.choose(m)
+			got := next.(model)
+			if !got.enteringMFA {
+				t.Fatal("MFA コードの入力が始まりません")
+			}
+			if got.mfaTarget.Name != "secure" {
+				t.Errorf("mfaTarget = %q, want %q", got.mfaTarget.Name, "secure")
+			}
+			// selectedProfile が空のままなら、終了しても選択結果は出力されない
+			if got.selectedProfile != "" {
+				t.Errorf("MFA コードを入力する前に %q が選択されました", got.selectedProfile)
+			}
+		})
+	}
+}
+
+func TestSelectProfileWithoutMFA(t *testing.T) {
+	m := newTestModel(t, options{}, mfaTestProfiles())
+	next, cmd := m.selectProfile(m.profiles[profileIndex(m.profiles, "plain")])
+	got := next.(model)
+	if got.enteringMFA {
+		t.Error("MFA の必要ないプロファイルで MFA コードの入力が始まりました")
+	}
+	if got.selectedProfile != "plain" {
+		t.Errorf("selectedProfile = %q, want %q", got.selectedProfile, "plain")
+	}
+	if cmd == nil {
+		t.Fatal("終了のコマンドが返されません")
+	}
+	if _, ok := cmd().(tea.QuitMsg); !ok {
+		t.Error("選択後に終了しません")
+	}
+}
+
+func TestMFAInputUsesTargetProfile(t *testing.T) {
+	m := newTestModel(t, options{}, mfaTestProfiles())
+	next, _ := m.startMFAInput(m.profiles[profileIndex(m.profiles, "secure")])
+	m = next.(model)
+	// 入力中にカーソルが別のプロファイルを指していても、入力を始めたプロファイルの認証情報を取得する
+	m.cursor = profileIndex(m.profiles, "plain")
+	m = pressKeys(t, m, "1", "2", "3", "4", "5", "6")
+	if view := m.View(); !strings.Contains(view, "'secure' の MFA コード") {
+		t.Errorf("入力欄の説明に入力中のプロファイル名が表示されません:\n%s", view)
+	}
+	// 取得のコマンドは aws コマンドを実行するため、実行せずに取得中になることだけを確認する
+	next, cmd := m.Update(keyPress("enter"))
+	if !next.(model).mfaPending || cmd == nil {
+		t.Fatal("認証情報の取得が始まりません")
+	}
+}
+
+func TestPromptMFACode(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "6 桁の数字", input: "123456\n", want: "123456"},
+		{name: "前後の空白は無視", input: " 123456 \r\n", want: "123456"},
+		{name: "改行なし", input: "123456", want: "123456"},
+		{name: "桁数が足りない", input: "12345\n", wantErr: true},
+		{name: "数字以外", input: "12a456\n", wantErr: true},
+		{name: "空", input: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var prompt bytes.Buffer
+			got, err := promptMFACode(strings.NewReader(tt.input), &prompt, awsProfile{Name: "secure"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("promptMFACode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("promptMFACode() = %q, want %q", got, tt.want)
+			}
+			if !strings.Contains(prompt.String(), "'secure' の MFA コード") {
+				t.Errorf("入力を促す表示 = %q", prompt.String())
+			}
+		})
+	}
+}
+
+func TestPassMFAGateWithoutMFA(t *testing.T) {
+	p := awsProfile{Name: "plain"}
+	got, err := passMFAGate(profileSources{}, p)
+	if err != nil {
+		t.Fatalf("passMFAGate() error = %v", err)
+	}
+	if got.Name != "plain" {
+		t.Errorf("passMFAGate() = %q, want %q", got.Name, "plain")
+	}
+}
+
+func TestSTSArgs(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	tests := []struct {
+		name    string
+		profile awsProfile
+		want    []string
+	}{
+		{
+			name: "ロールの引き受け",
+			profile: newAWSProfile("admin", map[string]string{
+				"role_arn":       "arn:aws:iam::123456789012:role/Admin",
+				"mfa_serial":     "arn:aws:iam::123456789012:mfa/user",
+				"source_profile": "base",
+			}, sourceConfig),
+			want: []string{"sts", "assume-role",
+				"--role-arn", "arn:aws:iam::123456789012:role/Admin",
+				"--role-session-name", "aws-profile-selector-1700000000",
+				"--serial-number", "arn:aws:iam::123456789012:mfa/user",
+				"--token-code", "123456",
+				"--output", "json",
+				"--profile", "base"},
+		},
+		{
+			name:    "セッショントークン",
+			profile: newAWSProfile("user", map[string]string{"mfa_serial": "arn:aws:iam::123456789012:mfa/user"}, sourceCredentials),
+			want: []string{"sts", "get-session-token",
+				"--serial-number", "arn:aws:iam::123456789012:mfa/user",
+				"--token-code", "123456",
+				"--profile", "user",
+				"--output", "json"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stsArgs(tt.profile, "123456", now); !slices.Equal(got, tt.want) {
+				t.Errorf("stsArgs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveMFACredentials(t *testing.T) {
+	creds := stsCredentials{AccessKeyID: "ASIANEW", SecretAccessKey: "secret", SessionToken: "token", Expiration: "2026-10-16T12:00:00Z"}
+	tests := []struct {
+		name     string
+		existing string // 空の場合はファイルを作成しない
+		mode     os.FileMode
+		want     string
+	}{
+		{
+			name: "新しい認証情報ファイル",
+			want: "[dev-mfa]\naws_access_key_id = ASIANEW\naws_secret_access_key = secret\naws_session_token = token\nx_expiration = 2026-10-16T12:00:00Z\n",
+		},
+		{
+			name:     "他のユーザーが読める既存のファイル",
+			existing: "[dev]\naws_access_key_id = AKIA\n\n[dev-mfa]\naws_access_key_id = ASIAOLD\naws_session_token = old\n",
+			mode:     0o644,
+			want:     "[dev]\naws_access_key_id = AKIA\n\n[dev-mfa]\naws_access_key_id = ASIANEW\naws_secret_access_key = secret\naws_session_token = token\nx_expiration = 2026-10-16T12:00:00Z\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "credentials")
+			if tt.existing != "" {
+				if err := os.WriteFile(path, []byte(tt.existing), tt.mode); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.Chmod(path, tt.mode); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := saveMFACredentials(path, "dev-mfa", creds); err != nil {
+				t.Fatalf("saveMFACredentials() error = %v", err)
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if perm := info.Mode().Perm(); perm != 0o600 {
+				t.Errorf("権限 = %o, want 600", perm)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("認証情報ファイル = %q, want %q", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestObtainMFAProfileUsesSourcesCredentials(t *testing.T) {
+	dir := t.TempDir()
+	binDir := filepath.Join(dir, "bin")
+	if err := os.Mkdir(binDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	script := `#!/bin/sh
+echo '{"Credentials": {"AccessKeyId": "ASIANEW", "SecretAccessKey": "secret", "SessionToken": "token", "Expiration": "2026-10-16T12:00:00Z"}}'
+`
+	if err := os.WriteFile(filepath.Join(binDir, "aws"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", binDir)
+
+	config := filepath.Join(dir, "config")
+	if err := os.WriteFile(config, []byte("[profile dev]\nregion = ap-northeast-1\nmfa_serial = arn:aws:iam::123456789012:mfa/user\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name        string
+		credentials string
+	}{
+		{name: "既定の場所にない認証情報ファイル", credentials: filepath.Join(dir, "team", "credentials")},
+		{name: "別の認証情報ファイル", credentials: filepath.Join(dir, "other-credentials")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(credentialsFileEnv, tt.credentials)
+			sources := profileSources{configPaths: []string{config}, quiet: true}
+			profiles, err := loadAWSProfiles(sources)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := obtainMFAProfile(sources, profiles[profileIndex(profiles, "dev")], "123456")
+			if err != nil {
+				t.Fatalf("obtainMFAProfile() error = %v", err)
+			}
+			if got.Name != "dev-mfa" || got.Source != sourceCredentials {
+				t.Errorf("obtainMFAProfile() = %s (%s), want dev-mfa (credentials)", got.Name, got.Source)
+			}
+
+			// 保存したプロファイルは、同じ sources で読み込み直した一覧に表示される
+			profiles, err = loadAWSProfiles(sources)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if i := profileIndex(profiles, "dev-mfa"); i < 0 || profiles[i].Source != sourceCredentials {
+				t.Errorf("読み込み直した一覧に dev-mfa がありません: %v", profileNames(profiles))
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package profileselector
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxMotionCount は数字キーで入力できる移動回数の上限です。
+const maxMotionCount = 9999
+
+// maxJumpKeys は数字キーで直接移動できるプロファイルの数です (1〜9)。
+const maxJumpKeys = 9
+
+// cursorUp はカーソルを 1 行上に移動します。
+// 先頭の行では、--wrap の指定時は同じ列の末尾の行に移動し、指定がなければ移動しません。
+func (m model) cursorUp() model {
+	cols := m.columnCount()
+	switch {
+	case m.cursor-cols >= 0:
+		m.cursor -= cols
+	case m.wrap:
+		m.cursor += (len(m.profiles) - 1 - m.cursor) / cols * cols
+	}
+	return m
+}
+
+// cursorDown はカーソルを 1 行下に移動します。
+// 末尾の行では、--wrap の指定時は同じ列の先頭の行に移動し、指定がなければ移動しません。
+func (m model) cursorDown() model {
+	cols := m.columnCount()
+	switch {
+	case m.cursor+cols < len(m.profiles):
+		m.cursor += cols
+	case m.wrap:
+		m.cursor %= cols
+	}
+	return m
+}
+
+// jumpKeyIndex は "1"〜"9" のキーを、対応するプロファイルの 0 始まりのインデックスに変換します。
+func jumpKeyIndex(key string) (int, bool) {
+	if len(key) != 1 || key[0] < '1' || key[0] > '0'+maxJumpKeys {
+		return 0, false
+	}
+	return int(key[0] - '1'), true
+}
+
+// updateJumpKeys は数字キーによる N 番目のプロファイルへの移動と選択を処理します。処理したキーであれば true を返します。
+// Alt+1〜9 は常に表示中の一覧の N 番目のプロファイルを選択します。
+// 1〜9 は --jump-keys の指定時だけ N 番目のプロファイルに移動し、指定がなければ移動回数の入力として扱います。
+func (m model) updateJumpKeys(key string) (tea.Model, tea.Cmd, bool) {
+	if digit, ok := strings.CutPrefix(key, "alt+"); ok {
+		i, ok := jumpKeyIndex(digit)
+		if !ok || m.deleteMode || m.renameMode {
+			return m, nil, false
+		}
+		if i < len(m.profiles) {
+			next, cmd := m.selectProfile(m.profiles[i])
+			return next, cmd, true
+		}
+		return m, nil, true
+	}
+
+	i, ok := jumpKeyIndex(key)
+	if !ok || !m.jumpKeys {
+		return m, nil, false
+	}
+	m.cursor = min(i, len(m.profiles)-1)
+	m.pendingTop = false
+	return m.clampCursor(), nil, true
+}
+
+// updateMotionPrefix は vim と同じく、数字キーによる移動回数の入力と gg / G による移動を処理します。
+// 処理したキーであれば true を返します。それ以外のキーでは入力途中の gg を取り消します。
+func (m model) updateMotionPrefix(key string) (model, bool) {
+	// 0 は移動回数の入力途中の場合のみ数字として扱う
+	if len(key) == 1 && key[0] >= '0' && key[0] <= '9' && (key != "0" || m.count > 0) {
+		if next := m.count*10 + int(key[0]-'0'); next <= maxMotionCount {
+			m.count = next
+		}
+		m.pendingTop = false
+		return m, true
+	}
+
+	switch {
+	case m.keys.Matches(actionTop, key):
+		if !m.pendingTop {
+			m.pendingTop = true
+			return m, true
+		}
+		m.pendingTop = false
+		m.cursor = m.countLine(0)
+	case m.keys.Matches(actionBottom, key):
+		m.pendingTop = false
+		m.cursor = m.countLine(len(m.profiles) - 1)
+	default:
+		m.pendingTop = false
+		return m, false
+	}
+	m.count = 0
+	return m.clampCursor(), true
+}
+
+// countLine は移動回数が入力されていればその番目 (1 始まり) のインデックスを、なければ fallback を返します。
+func (m model) countLine(fallback int) int {
+	if m.count == 0 {
+		return fallback
+	}
+	return m.count - 1
+}
+
+// motionCount は次の移動に使う回数を返します。移動回数が入力されていなければ 1 を返します。
+func (m model) motionCount() int {
+	if m.count == 0 {
+		return 1
+	}
+	return m.count
+}
+
+// pendingMotion は入力途中の移動回数と g をステータス行に表示する文字列を返します。
+func (m model) pendingMotion() string {
+	pending := ""
+	if m.count > 0 {
+		pending = strconv.Itoa(m.count)
+	}
+	if m.pendingTop {
+		pending += "g"
+	}
+	if pending == "" {
+		return ""
+	}
+	return fmt.Sprintf("  %s", pending)
+}
@@ -0,0 +1,61 @@
+package profileselector
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestWrapNavigation(t *testing.T) {
+	tests := []struct {
+		name       string
+		wrap       bool
+		keys       []string
+		wantCursor int
+	}{
+		{name: "先頭で上 (wrap なし)", keys: []string{"up"}, wantCursor: 0},
+		{name: "先頭で上 (wrap あり)", wrap: true, keys: []string{"up"}, wantCursor: 29},
+		{name: "末尾で下 (wrap なし)", keys: []string{"G", "down"}, wantCursor: 29},
+		{name: "末尾で下 (wrap あり)", wrap: true, keys: []string{"G", "down"}, wantCursor: 0},
+		{name: "一周して戻る", wrap: true, keys: []string{"up", "down"}, wantCursor: 0},
+		{name: "途中では通常どおり", wrap: true, keys: []string{"down", "down", "up"}, wantCursor: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t, options{wrap: tt.wrap}, syntheticProfiles(30))
+			next, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 20})
+			m = pressKeys(t, next.(model), tt.keys...)
+			if m.cursor != tt.wantCursor {
+				t.Errorf("cursor = %d, want %d", m.cursor, tt.wantCursor)
+			}
+			if m.cursor < m.scrollOffset || m.cursor >= m.scrollOffset+m.listVisibleHeight {
+				t.Errorf("カーソル %d が表示範囲 [%d, %d) の外にあります", m.cursor, m.scrollOffset, m.scrollOffset+m.listVisibleHeight)
+			}
+		})
+	}
+}
+
+func TestWrapNavigationColumns(t *testing.T) {
+	tests := []struct {
+		name       string
+		start      int
+		key        string
+		wantCursor int
+	}{
+		{name: "先頭の行の 2 列目で上", start: 1, key: "up", wantCursor: 7},
+		{name: "先頭の行の 3 列目で上 (末尾の行にその列がない)", start: 2, key: "up", wantCursor: 5},
+		{name: "末尾の行で下", start: 7, key: "down", wantCursor: 1},
+		{name: "末尾の行のない列で下", start: 5, key: "down", wantCursor: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 8 件を 3 列で並べると、末尾の行は 6, 7 の 2 件だけになる
+			m := newTestModel(t, options{wrap: true, columns: 3}, syntheticProfiles(8))
+			m.cursor = tt.start
+			m = pressKeys(t, m, tt.key)
+			if m.cursor != tt.wantCursor {
+				t.Errorf("cursor = %d, want %d", m.cursor, tt.wantCursor)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package profileselector
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// notesPath はプロファイルのメモを保存するファイルのパスを返します。
+func notesPath() (string, error) {
+	dir, err := toolConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "notes.json"), nil
+}
+
+// loadNotes はメモファイルを読み込み、プロファイル名とメモの対応を返します。
+// ファイルが存在しない場合は空の対応を返します。
+func loadNotes(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return map[string]string{}, nil
+		}
+		return nil, fmt.Errorf("メモの読み込みに失敗しました: %w (ファイル: %s)", err, path)
+	}
+
+	notes := map[string]string{}
+	if err := json.Unmarshal(data, &notes); err != nil {
+		return nil, fmt.Errorf("メモの解析に失敗しました: %w (ファイル: %s)", err, path)
+	}
+	return notes, nil
+}
+
+// saveNote はプロファイルのメモをメモファイルに保存します。note が空の場合はメモを削除します。
+func saveNote(path, profileName, note string) error {
+	notes, err := loadNotes(path)
+	if err != nil {
+		return err
+	}
+
+	if note == "" {
+		delete(notes, profileName)
+	} else {
+		notes[profileName] = note
+	}
+
+	data, err := json.MarshalIndent(notes, "", "  ")
+	if err != nil {
+		return fmt.Errorf("メモの変換に失敗しました: %w", err)
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("メモの書き込みに失敗しました: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package profileselector
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSaveNote(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notes.json")
+	steps := []struct {
+		name    string
+		profile string
+		note    string
+		want    map[string]string
+	}{
+		{name: "追加", profile: "dev", note: "開発用", want: map[string]string{"dev": "開発用"}},
+		{name: "別のプロファイルに追加", profile: "prod", note: "本番", want: map[string]string{"dev": "開発用", "prod": "本番"}},
+		{name: "更新", profile: "dev", note: "検証用", want: map[string]string{"dev": "検証用", "prod": "本番"}},
+		{name: "空のメモで削除", profile: "prod", note: "", want: map[string]string{"dev": "検証用"}},
+	}
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			if err := saveNote(path, step.profile, step.note); err != nil {
+				t.Fatalf("saveNote() error = %v", err)
+			}
+			got, err := loadNotes(path)
+			if err != nil {
+				t.Fatalf("loadNotes() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, step.want) {
+				t.Errorf("loadNotes() = %v, want %v", got, step.want)
+			}
+		})
+	}
+	assertNoTempFiles(t, filepath.Dir(path))
+}
+
+func TestLoadNotes(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string // 空の場合はファイルを作成しない
+		want    map[string]string
+		wantErr bool
+	}{
+		{name: "ファイルなし", want: map[string]string{}},
+		{name: "メモあり", content: `{"prod": "本番。取り扱い注意"}`, want: map[string]string{"prod": "本番。取り扱い注意"}},
+		{name: "JSON として不正", content: `{"prod": `, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "notes.json")
+			if tt.content != "" {
+				if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got, err := loadNotes(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadNotes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadNotes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditNote(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []string
+		want map[string]string
+	}{
+		{name: "Enter で保存", keys: []string{"n", "本", "番", "enter"}, want: map[string]string{"prod": "本番"}},
+		{name: "Esc で取り消し", keys: []string{"n", "本", "番", "esc"}, want: map[string]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t, options{}, testProfiles("dev", "prod"))
+			m = pressKeys(t, m, "j")
+			m = pressKeys(t, m, tt.keys...)
+			if m.editingNote {
+				t.Fatal("メモの編集が終わっていません")
+			}
+			if !reflect.DeepEqual(m.notes, tt.want) {
+				t.Errorf("notes = %v, want %v", m.notes, tt.want)
+			}
+
+			// 保存したメモは次に起動したときにも読み込まれる
+			path, err := notesPath()
+			if err != nil {
+				t.Fatal(err)
+			}
+			saved, err := loadNotes(path)
+			if err != nil {
+				t.Fatalf("loadNotes() error = %v", err)
+			}
+			if !reflect.DeepEqual(saved, tt.want) {
+				t.Errorf("保存されたメモ = %v, want %v", saved, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,287 @@
+package profileselector
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// defaultProfileEnv は初期カーソル位置のプロファイルを AWS_DEFAULT_PROFILE とは別に指定する環境変数です。
+const defaultProfileEnv = "AWS_PROFILE_SELECTOR_DEFAULT"
+
+// options はコマンドライン引数で指定されたオプションを保持します。
+type options struct {
+	index *int     // --index で指定されたプロファイルのインデックス (未指定の場合は nil)
+	sort  sortMode // プロファイルの並び順
+	fd    *int     // --fd で指定された選択結果の書き込み先ファイルディスクリプタ (未指定の場合は nil)
+
+	maxProfiles   int           // 表示するプロファイルの最大数 (0 は無制限)
+	columns       int           // プロファイルを並べる列数
+	timeout       int           // キー入力がなければカーソル位置のプロファイルを自動選択するまでの秒数 (0 は自動選択しない)
+	previewHeight int           // --preview のプレビューペインの行数
+	filter        profileFilter // 表示するプロファイルの条件 (--profile-prefix, --account-id)
+
+	noAltScreen  bool // 代替スクリーンを使わずに描画する (終了後も画面がスクロールバックに残る)
+	noZebra      bool // 一覧の縞模様 (奇数行の背景色) を無効にする
+	border       bool // 一覧を角の丸い枠で囲む
+	tree         bool // source_profile の継承関係の木として一覧を表示する
+	warnNoMFA    bool // role_arn があり mfa_serial のないプロファイルに印を付ける
+	showAllRoles bool // 起動時から全ての行に role_arn を表示する
+	jumpKeys     bool // 1〜9 のキーで N 番目のプロファイルに移動する (移動回数の入力には使わない)
+	allowUnset   bool // 一覧の先頭にプロファイルの設定を解除する項目を表示する
+	wrap         bool // 一覧の末尾で下に移動すると先頭に、先頭で上に移動すると末尾に移動する
+	compact      bool // タイトルやフッターを省き、プロファイル名だけを 1 行に 1 つずつ表示する
+	preview      bool // 一覧の下にカーソル位置のプロファイルのキーと値を表示するプレビューペインを置く
+	interactive  bool // 選択画面を起動する (false の場合はプロファイルを読み込めるかだけを確認して終了する)
+	noColor      bool // 色や太字などの装飾を付けずに描画する (環境変数 NO_COLOR を設定した場合も同じ)
+
+	theme          string             // 描画に使う色のテーマ (dark|light|auto)
+	envPrefix      string             // プロファイルを設定する環境変数の接頭辞 (デフォルトは AWS_DEFAULT)
+	printInit      string             // --print-init で指定されたシェル (未指定の場合は空)
+	completion     string             // --completion で指定された補完スクリプトのシェル (未指定の場合は空)
+	list           bool               // プロファイル名の一覧を出力して終了する
+	shell          string             // 選択結果を出力するシェルの形式 (未指定の場合は POSIX シェル)
+	cleanEnv       bool               // 選択結果の前に認証情報の環境変数を削除するコマンドを出力する
+	exportOutput   bool               // 選択結果に AWS_DEFAULT_OUTPUT を設定するコマンドも出力する
+	template       *template.Template // --template で指定された選択結果の出力のテンプレート (未指定の場合は nil)
+	profileOnly    bool               // 選択結果としてプロファイル名だけを出力する
+	dryRun         bool               // 選択結果を出力せずに、出力する予定のコマンドを標準エラー出力に表示する
+	quiet          bool               // エラーやキャンセルなどのメッセージを標準エラー出力に書き込まない
+	first          bool               // 絞り込みの結果が 1 件なら TUI を起動せずに選択する
+	check          bool               // 全てのプロファイルを検査して結果を出力し、終了する
+	execCommand    []string           // --exec で指定された、選択したプロファイルで実行するコマンドと引数 (未指定の場合は nil)
+	clipboardOnly  bool               // 選択結果を出力する代わりに、プロファイル名をクリップボードにコピーする
+	envrcFile      string             // --envrc-file で指定された、選択結果の export 文を書き込む .envrc ファイルのパス (未指定の場合は空)
+	envrcOverwrite bool               // .envrc ファイルに追記せずに上書きする
+
+	defaultProfile string   // --default で指定された初期カーソル位置のプロファイル名
+	configPaths    []string // --config で指定された設定ファイルのパス (後のものほど優先, 未指定の場合は nil)
+	ssmPrefix      string   // --ssm-prefix で指定された SSM パラメータストアのパス (未指定の場合は空)
+	stdinConfig    bool     // パイプで渡された標準入力を設定ファイルとして読み込む (--config が指定されていない選択のときに runSelect で有効にする)
+}
+
+// sources は opts で指定されたプロファイルの読み込み元を返します。
+func (opts options) sources() profileSources {
+	return profileSources{configPaths: opts.configPaths, stdin: opts.stdinConfig, ssmPrefix: opts.ssmPrefix, quiet: opts.quiet}
+}
+
+// parseOptions はコマンドライン引数を解析します。
+func parseOptions(args []string) (options, error) {
+	return parseOptionsTo(os.Stderr, args)
+}
+
+// parseOptionsTo は parseOptions と同じようにコマンドライン引数を解析し、解析のエラーと使い方を w に書き込みます。
+func parseOptionsTo(w io.Writer, args []string) (options, error) {
+	opts := options{sort: sortAlpha, theme: themeDark, previewHeight: defaultPreviewHeight, envPrefix: defaultEnvPrefix}
+	fs := newOptionFlagSet(&opts)
+	fs.SetOutput(w)
+	if err := fs.Parse(args); err != nil {
+		return options{}, err
+	}
+	if len(opts.execCommand) > 0 {
+		opts.execCommand = append(opts.execCommand, fs.Args()...)
+	}
+	return opts, nil
+}
+
+// newOptionFlagSet はプロファイルの選択のオプションを opts に設定する FlagSet を生成します。
+func newOptionFlagSet(opts *options) *flag.FlagSet {
+	fs := flag.NewFlagSet("aws-profile-selector", flag.ContinueOnError)
+	fs.Func("index", "TUI を起動せずに指定したインデックス (0始まり, 負の値は末尾から) のプロファイルを選択する", func(s string) error {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("整数を指定してください: %s", s)
+		}
+		opts.index = &n
+		return nil
+	})
+
+	fs.Func("sort", "プロファイルの並び順 (alpha|last-used|type|none, デフォルト: alpha)", func(s string) error {
+		mode, err := parseSortMode(s)
+		if err != nil {
+			return err
+		}
+		opts.sort = mode
+		return nil
+	})
+
+	fs.Func("fd", "選択したプロファイル名を標準出力の代わりに指定したファイルディスクリプタに書き込む", func(s string) error {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("整数を指定してください: %s", s)
+		}
+		opts.fd = &n
+		return nil
+	})
+	fs.Func("config", "読み込む設定ファイルのパス (環境変数 AWS_CONFIG_FILE より優先。カンマ区切りや繰り返しで複数指定すると後のファイルほど優先してマージする。未指定で標準入力がパイプの場合は標準入力から読み込む)", func(s string) error {
+		opts.configPaths = append(opts.configPaths, splitConfigPaths(s)...)
+		return nil
+	})
+	fs.StringVar(&opts.ssmPrefix, "ssm-prefix", "", "指定した SSM パラメータストアのパス以下のパラメータもプロファイルとして読み込む")
+	fs.StringVar(&opts.defaultProfile, "default", "", "起動時にカーソルを置くプロファイル名 (環境変数 "+defaultProfileEnv+" や AWS_DEFAULT_PROFILE より優先)")
+	fs.IntVar(&opts.maxProfiles, "max-profiles", 0, "表示するプロファイルの最大数 (選択履歴があれば最近選択したもの、なければアルファベット順で先頭のものを表示, 0 は無制限)")
+	fs.Func("profile-prefix", "指定した接頭辞で始まる名前のプロファイルだけを表示する (複数指定するといずれかに一致するものを表示)", func(s string) error {
+		opts.filter.prefixes = append(opts.filter.prefixes, s)
+		return nil
+	})
+	fs.Func("account-id", "role_arn のアカウント ID が指定した値のプロファイルだけを表示する (複数指定するといずれかに一致するものを表示)", func(s string) error {
+		opts.filter.accountIDs = append(opts.filter.accountIDs, s)
+		return nil
+	})
+	fs.BoolVar(&opts.filter.rolesOnly, "roles-only", false, "role_arn のあるプロファイルだけを表示する")
+	fs.Func("allow-list", "1 行に 1 つプロファイル名またはグロブパターンを記述したファイルを読み込み、いずれかに一致するプロファイルだけを表示する", func(s string) error {
+		patterns, err := loadAllowList(s)
+		if err != nil {
+			return err
+		}
+		opts.filter.allowList = patterns
+		return nil
+	})
+	fs.Func("deny-list", "1 行に 1 つプロファイル名またはグロブパターンを記述したファイルを読み込み、いずれかに一致するプロファイルを表示しない (--allow-list より優先)", func(s string) error {
+		patterns, err := loadDenyList(s)
+		if err != nil {
+			return err
+		}
+		opts.filter.denyList = patterns
+		return nil
+	})
+	fs.IntVar(&opts.columns, "columns", 1, "プロファイルを並べる列数 (列表示切替キーで 1 列表示と切り替え可能)")
+	fs.IntVar(&opts.timeout, "timeout", 0, "指定した秒数の間キー入力がなければ、起動時のカーソル位置 (AWS_DEFAULT_PROFILE) のプロファイルを自動選択する (0 は自動選択しない)")
+	fs.BoolVar(&opts.noAltScreen, "no-altscreen", false, "代替スクリーンを使わずに描画し、終了後も選択画面をスクロールバックに残す")
+	fs.BoolVar(&opts.noZebra, "no-zebra", false, "一覧の奇数行に背景色を付ける縞模様を無効にする (環境変数 "+noZebraEnv+"=1 でも無効)")
+	fs.BoolVar(&opts.border, "border", false, "タイトルを上辺に置いた角の丸い枠で一覧を囲む (枠の分だけ一覧の幅が狭くなる)")
+	fs.BoolFunc("no-border", "枠で囲まずに表示する (--border を打ち消す)", func(string) error {
+		opts.border = false
+		return nil
+	})
+	fs.BoolVar(&opts.tree, "tree", false, "source_profile の参照先を親、参照元を字下げした子とする木として一覧を表示する")
+	fs.BoolVar(&opts.showAllRoles, "show-all-roles", false, "起動時から全ての行に RoleARN を表示する (詳細表示切替キーで 非表示 → 選択行 → 全行 の順に切り替え)")
+	fs.BoolVar(&opts.jumpKeys, "jump-keys", false, "1〜9 のキーを移動回数の入力ではなく、表示中の一覧の N 番目のプロファイルへの移動に使う")
+	fs.Func("theme", "描画に使う色のテーマ (dark|light|auto, デフォルト: dark, auto では端末の背景色から判定)", func(s string) error {
+		theme, err := parseTheme(s)
+		if err != nil {
+			return err
+		}
+		opts.theme = theme
+		return nil
+	})
+	fs.BoolVar(&opts.noColor, "no-color", false, "色や太字などの装飾を付けずに描画する (環境変数 NO_COLOR が設定されている場合も同じ)")
+	fs.BoolVar(&opts.wrap, "wrap", false, "一覧の末尾で下に移動すると先頭に、先頭で上に移動すると末尾に移動する")
+	fs.BoolVar(&opts.interactive, "interactive", true, "選択画面を起動する (--interactive=false では選択画面を起動せずに、プロファイルを読み込めるかだけを確認して要約を標準エラー出力に表示する)")
+	fs.BoolVar(&opts.preview, "preview", false, "一覧の下にカーソル位置のプロファイルの全てのキーと値を表示するプレビューペインを置く")
+	fs.Func("preview-height", fmt.Sprintf("--preview のプレビューペインの行数 (デフォルト: %d)", defaultPreviewHeight), func(s string) error {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			return fmt.Errorf("1 以上の整数を指定してください: %s", s)
+		}
+		opts.previewHeight = n
+		return nil
+	})
+	fs.BoolVar(&opts.compact, "compact", false, "タイトルやフッターを省き、プロファイル名だけを 1 行に 1 つずつ表示する (? キーで操作の説明を表示)")
+	fs.BoolVar(&opts.allowUnset, "allow-unset", false, "一覧の先頭に "+unsetProfileName+" を表示し、選択すると AWS_DEFAULT_PROFILE を削除するコマンドを出力する")
+	fs.BoolVar(&opts.warnNoMFA, "warn-no-mfa", false, "role_arn があり mfa_serial のないプロファイルに "+noMFATag+" の印を付ける")
+	fs.Func("shell", "選択結果を指定したシェルの構文で出力する (bash|zsh|fish|direnv, デフォルト: bash, direnv では region も出力)", func(s string) error {
+		shell, err := parseOutputShell(s)
+		if err != nil {
+			return err
+		}
+		opts.shell = shell
+		return nil
+	})
+	fs.Func("env-prefix", "プロファイルと region を設定する環境変数の接頭辞 (<接頭辞>_PROFILE と <接頭辞>_REGION に設定する, デフォルト: "+defaultEnvPrefix+")", func(s string) error {
+		prefix, err := parseEnvPrefix(s)
+		if err != nil {
+			return err
+		}
+		opts.envPrefix = prefix
+		return nil
+	})
+	fs.BoolVar(&opts.profileOnly, "profile-only", false, "選択結果として export 文の代わりにプロファイル名だけを出力する")
+	fs.Func("template", "選択結果の代わりに、選択したプロファイル (.Name, .RoleArn, .Region, .AccountID) に対して実行した text/template の結果を出力する", func(s string) error {
+		tmpl, err := parseOutputTemplate(s)
+		if err != nil {
+			return err
+		}
+		opts.template = tmpl
+		return nil
+	})
+	fs.BoolVar(&opts.cleanEnv, "clean-env", false, "プロファイルより優先される AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN を削除するコマンドも出力する")
+	fs.BoolVar(&opts.exportOutput, "export-output", false, "選択したプロファイルに output があれば、AWS_DEFAULT_OUTPUT を設定するコマンドも出力する")
+	fs.BoolVar(&opts.first, "first", false, "絞り込みの結果が 1 件なら TUI を起動せずにそのプロファイルを選択する (0 件ならエラー、複数なら TUI で選択)")
+	fs.BoolVar(&opts.check, "check", false, "TUI を起動せずに全てのプロファイルを検査し、結果を出力して終了する (問題があれば終了コード 1)")
+	fs.Func("exec", "選択結果を出力する代わりに、選択したプロファイルを AWS_DEFAULT_PROFILE と AWS_DEFAULT_REGION に設定してコマンドを実行する (値は分割せずにコマンド名とし、-- の後の引数をコマンドの引数にする)", func(s string) error {
+		// 引用符を含む引数を壊さないよう、値は空白で分割せずにそのままコマンド名として扱う
+		if strings.TrimSpace(s) == "" {
+			return fmt.Errorf("実行するコマンドを指定してください")
+		}
+		opts.execCommand = []string{s}
+		return nil
+	})
+	fs.BoolVar(&opts.clipboardOnly, "clipboard-only", false, "選択結果を出力する代わりに、選択したプロファイル名をクリップボードにコピーして終了する")
+	fs.StringVar(&opts.envrcFile, "envrc-file", "", "選択結果を出力する代わりに、AWS_DEFAULT_PROFILE と AWS_DEFAULT_REGION の export 文を指定した direnv の .envrc ファイルに追記する")
+	fs.BoolVar(&opts.envrcOverwrite, "envrc-overwrite", false, "--envrc-file のファイルに追記せずに上書きする")
+	fs.BoolVar(&opts.quiet, "quiet", false, "エラーやキャンセルなどのメッセージを標準エラー出力に書き込まない (終了コードは変わらない)")
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "TUI を起動せずに --index または絞り込みで決まるプロファイルを選択し、出力する予定のコマンドを標準エラー出力に表示する")
+	fs.Func("print-init", "選択したプロファイルを設定するシェル関数 (awsp) の定義を出力して終了する (bash|zsh|fish)", func(s string) error {
+		shell, err := parseShell(s)
+		if err != nil {
+			return err
+		}
+		opts.printInit = shell
+		return nil
+	})
+	fs.Func("completion", "フラグとプロファイル名を補完するシェルの補完スクリプトを出力して終了する (bash|zsh|fish)", func(s string) error {
+		shell, err := parseShell(s)
+		if err != nil {
+			return err
+		}
+		opts.completion = shell
+		return nil
+	})
+	fs.BoolVar(&opts.list, "list", false, "TUI を起動せずに、絞り込んで並べ替えたプロファイル名を 1 行に 1 つずつ出力して終了する")
+	return fs
+}
+
+// resolveInitialProfile は起動時にカーソルを置くプロファイル名を返します。
+// --default、環境変数 AWS_PROFILE_SELECTOR_DEFAULT、AWS_DEFAULT_PROFILE の順に優先します。
+func resolveInitialProfile(flagValue, envProfile string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	if name := os.Getenv(defaultProfileEnv); name != "" {
+		return name
+	}
+	return envProfile
+}
+
+// resolveSelection は TUI を起動せずに選択するプロファイルのインデックスを返します。
+// index が指定されていればそのインデックスを、指定されていなければ絞り込みの結果が 1 件の場合にそのプロファイルを選択します。
+func resolveSelection(index *int, length int) (int, error) {
+	if index != nil {
+		return resolveIndex(*index, length)
+	}
+	if length == 0 {
+		return 0, fmt.Errorf("条件に一致するプロファイルがありません")
+	}
+	if length != 1 {
+		return 0, fmt.Errorf("プロファイルを 1 つに決められません (プロファイル数: %d)。--index を指定するか、--profile-prefix などで 1 件に絞り込んでください", length)
+	}
+	return 0, nil
+}
+
+// resolveIndex は --index で指定されたインデックスを、長さ length のリストの有効なインデックスに変換します。
+// 負の値は末尾から数えます (-1 が最後の要素)。
+func resolveIndex(index, length int) (int, error) {
+	resolved := index
+	if resolved < 0 {
+		resolved += length
+	}
+	if resolved < 0 || resolved >= length {
+		return 0, fmt.Errorf("インデックス %d は範囲外です (プロファイル数: %d)", index, length)
+	}
+	return resolved, nil
+}
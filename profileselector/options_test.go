@@ -0,0 +1,64 @@
+package profileselector
+
+import (
+	"io"
+	"slices"
+	"testing"
+)
+
+func TestParseOptionsExec(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "指定なし", args: nil, want: nil},
+		{name: "コマンドだけ", args: []string{"--exec", "aws"}, want: []string{"aws"}},
+		{name: "-- の後の引数", args: []string{"--exec", "aws", "--", "s3", "ls"}, want: []string{"aws", "s3", "ls"}},
+		{name: "空白を含む引数はそのまま渡す", args: []string{"--exec", "aws", "--", "s3", "ls", "s3://my bucket/"}, want: []string{"aws", "s3", "ls", "s3://my bucket/"}},
+		{name: "値は空白で分割しない", args: []string{"--exec", "/opt/my tools/aws"}, want: []string{"/opt/my tools/aws"}},
+		{name: "空のコマンド", args: []string{"--exec", " "}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseOptionsTo(io.Discard, tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseOptionsTo() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(opts.execCommand, tt.want) {
+				t.Errorf("execCommand = %q, want %q", opts.execCommand, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveIndex(t *testing.T) {
+	tests := []struct {
+		name    string
+		index   int
+		length  int
+		want    int
+		wantErr bool
+	}{
+		{name: "先頭", index: 0, length: 5, want: 0},
+		{name: "範囲内", index: 3, length: 5, want: 3},
+		{name: "最後", index: 4, length: 5, want: 4},
+		{name: "範囲外の正の値", index: 5, length: 5, wantErr: true},
+		{name: "-1 は最後", index: -1, length: 5, want: 4},
+		{name: "負の値で先頭", index: -5, length: 5, want: 0},
+		{name: "範囲外の負の値", index: -6, length: 5, wantErr: true},
+		{name: "プロファイルなし", index: 0, length: 0, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveIndex(tt.index, tt.length)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveIndex(%d, %d) error = %v, wantErr %v", tt.index, tt.length, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("resolveIndex(%d, %d) = %d, want %d", tt.index, tt.length, got, tt.want)
+			}
+		})
+	}
+}
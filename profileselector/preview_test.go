@@ -0,0 +1,52 @@
+package profileselector
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestPreviewValue(t *testing.T) {
+	tests := []struct {
+		key   string
+		value string
+		want  string
+	}{
+		{key: "region", value: "ap-northeast-1", want: "ap-northeast-1"},
+		{key: "aws_access_key_id", value: "AKIAEXAMPLE", want: "AKIAEXAMPLE"},
+		{key: "aws_secret_access_key", value: "super-secret", want: maskedSecretValue},
+		{key: "aws_session_token", value: "session-token", want: maskedSecretValue},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := previewValue(tt.key, tt.value); got != tt.want {
+				t.Errorf("previewValue(%q, %q) = %q, want %q", tt.key, tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderPreviewMasksSecrets(t *testing.T) {
+	p := awsProfile{
+		Name:   "dev",
+		Source: sourceConfig,
+		RawKeys: map[string]string{
+			"aws_access_key_id":     "AKIAEXAMPLE",
+			"aws_secret_access_key": "super-secret",
+			"aws_session_token":     "session-token",
+		},
+	}
+	out := ansi.Strip(renderPreview(p, map[string]awsProfile{"dev": p}, "", 80, 20, darkTheme))
+	for _, secret := range []string{"super-secret", "session-token"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("プレビューに %q が表示されています:\n%s", secret, out)
+		}
+	}
+	if !strings.Contains(out, "aws_secret_access_key = "+maskedSecretValue) {
+		t.Errorf("プレビューでシークレットアクセスキーが伏せ字になっていません:\n%s", out)
+	}
+	if !strings.Contains(out, "AKIAEXAMPLE") {
+		t.Errorf("プレビューにアクセスキー ID が表示されていません:\n%s", out)
+	}
+}
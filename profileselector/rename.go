@@ -0,0 +1,186 @@
+package profileselector
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"gopkg.in/ini.v1"
+)
+
+// renameProfileInConfig は configPath の設定ファイルで、oldName のプロファイルのセクション名を newName に変更します。
+// セクションの位置とキーはそのまま残し、同じファイル内で oldName を参照する source_profile も newName に書き換えます。
+func renameProfileInConfig(configPath, oldName, newName string) error {
+	return renameProfileInFile(configPath, oldName, newName, false)
+}
+
+// renameProfileInCredentials は credentialsPath の認証情報ファイルで、oldName のプロファイルのセクション名を newName に変更します。
+// 認証情報ファイルのセクション名には "profile " プレフィックスを付けません。
+func renameProfileInCredentials(credentialsPath, oldName, newName string) error {
+	return renameProfileInFile(credentialsPath, oldName, newName, true)
+}
+
+// renameProfileInFile は path のファイルで oldName のプロファイルのセクション名を newName に変更します。
+// credentials が true の場合は認証情報ファイルとして、プロファイル名をそのままセクション名にします。
+func renameProfileInFile(path, oldName, newName string, credentials bool) error {
+	cfg, err := loadConfigForUpdate(path)
+	if err != nil {
+		return err
+	}
+	findSection, sectionName, perm := findProfileSection, profileSectionName, os.FileMode(configFileMode)
+	if credentials {
+		findSection, sectionName, perm = findCredentialsSection, func(name string) string { return name }, credentialsFileMode
+	}
+	section := findSection(cfg, oldName)
+	if section == nil {
+		return fmt.Errorf("プロファイル '%s' が見つかりません (ファイル: %s)", oldName, path)
+	}
+	if findSection(cfg, newName) != nil {
+		return fmt.Errorf("プロファイル '%s' は既に存在します (ファイル: %s)", newName, path)
+	}
+
+	// config では default 以外は "profile " プレフィックスを付けないと AWS CLI に認識されないため、元のセクションの形式に関係なく付け直す
+	renamed, err := renameSection(cfg, section.Name(), sectionName(newName))
+	if err != nil {
+		return err
+	}
+	renameSourceProfileRefs(renamed, oldName, newName)
+	return saveConfig(renamed, path, perm)
+}
+
+// findCredentialsSection は認証情報ファイルからプロファイル名と同じ名前のセクションを探します。
+func findCredentialsSection(cfg *ini.File, profileName string) *ini.Section {
+	section, err := cfg.GetSection(profileName)
+	if err != nil {
+		return nil
+	}
+	return section
+}
+
+// renameSection は cfg のセクションを順番どおりに複製し、oldName のセクションだけ名前を newName に変えた設定を返します。
+// ini.File にはセクション名を変更する方法がないため、位置を保つために全体を作り直します。
+func renameSection(cfg *ini.File, oldName, newName string) (*ini.File, error) {
+	renamed := ini.Empty()
+	for _, section := range cfg.Sections() {
+		name := section.Name()
+		if name == oldName {
+			name = newName
+		}
+		dst, err := renamed.NewSection(name)
+		if err != nil {
+			return nil, fmt.Errorf("セクションの作成に失敗しました: %w (セクション: %s)", err, name)
+		}
+		dst.Comment = section.Comment
+		if err := copySectionKeys(dst, section); err != nil {
+			return nil, err
+		}
+	}
+	return renamed, nil
+}
+
+// renameSourceProfileRefs は cfg の全てのセクションで、oldName を指す source_profile を newName に書き換えます。
+// 書き換えたキーの数を返します。
+func renameSourceProfileRefs(cfg *ini.File, oldName, newName string) int {
+	count := 0
+	for _, section := range cfg.Sections() {
+		if key, err := section.GetKey("source_profile"); err == nil && key.Value() == oldName {
+			key.SetValue(newName)
+			count++
+		}
+	}
+	return count
+}
+
+// validateProfileName は新しく付けるプロファイル名として使えるかを確認します。
+func validateProfileName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("プロファイル名を入力してください")
+	case strings.ContainsAny(name, shellMetaCharacters):
+		return fmt.Errorf("プロファイル名に空白や [ ] ; | $ などのシェルで特別な意味を持つ文字は使えません")
+	}
+	return nil
+}
+
+// updateRenameInput は名前の変更中のキー入力を処理します。
+// Enter でカーソル位置のプロファイルの名前を変更して終了し、Esc で変更を取り消します。
+func (m model) updateRenameInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		p := m.profiles[m.cursor]
+		newName := strings.TrimSpace(m.renameInput.Value())
+		if newName == p.Name {
+			m.renaming = false
+			m.renameInput.Blur()
+			return m, nil
+		}
+		err := validateProfileName(newName)
+		if err == nil {
+			err = renameProfile(m.sources, p, newName)
+		}
+		if err != nil {
+			m.toast = err.Error()
+			m.toastID++
+			return m, clearToastCmd(m.toastID)
+		}
+		m.renaming = false
+		m.renamedProfile = newName
+		return m, tea.Quit
+
+	case "esc", "ctrl+c":
+		m.renaming = false
+		m.renameInput.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.renameInput, cmd = m.renameInput.Update(msg)
+	return m, cmd
+}
+
+// renameProfile はプロファイル p の名前を sources の読み込み元のファイルで newName に変更します。
+// credentials のプロファイルの場合は、config の source_profile の参照も書き換えます。
+func renameProfile(sources profileSources, p awsProfile, newName string) error {
+	path, err := sources.sourcePath(p.Source)
+	if err != nil {
+		return err
+	}
+	if p.Source != sourceCredentials {
+		return renameProfileInConfig(path, p.Name, newName)
+	}
+	if err := renameProfileInCredentials(path, p.Name, newName); err != nil {
+		return err
+	}
+
+	configFile, err := sources.configPath()
+	if err != nil {
+		return err
+	}
+	cfg, err := loadConfigForUpdate(configFile)
+	if err != nil {
+		return err
+	}
+	if renameSourceProfileRefs(cfg, p.Name, newName) == 0 {
+		return nil
+	}
+	return saveConfig(cfg, configFile, configFileMode)
+}
+
+// runRename は rename サブコマンドを実行し、終了コードを返します。
+// プロファイルの一覧を表示し、選択したプロファイルの名前を入力した名前に変更します。
+func runRename(args []string) int {
+	m, code, ok := runModeTUI(args, func(m model) model {
+		m.renameMode = true
+		return m
+	})
+	if !ok {
+		return code
+	}
+	if m.renamedProfile == "" {
+		fmt.Fprintln(os.Stderr, "プロファイル名の変更がキャンセルされました。")
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "プロファイル '%s' の名前を '%s' に変更しました。\n", m.profiles[m.cursor].Name, m.renamedProfile)
+	return 0
+}
@@ -0,0 +1,181 @@
+package profileselector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRenameProfileInConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		oldName string
+		newName string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "profile プレフィックス付きのセクション",
+			config:  "[profile dev]\nregion = us-east-1\n\n[profile child]\nsource_profile = dev\n",
+			oldName: "dev",
+			newName: "development",
+			want:    "[profile development]\nregion = us-east-1\n\n[profile child]\nsource_profile = development\n",
+		},
+		{
+			name:    "default の名前を変更",
+			config:  "[default]\nregion = us-east-1\n\n[profile child]\nsource_profile = default\n",
+			oldName: "default",
+			newName: "main",
+			want:    "[profile main]\nregion = us-east-1\n\n[profile child]\nsource_profile = main\n",
+		},
+		{
+			name:    "default に名前を変更",
+			config:  "[profile main]\nregion = us-east-1\n",
+			oldName: "main",
+			newName: "default",
+			want:    "[default]\nregion = us-east-1\n",
+		},
+		{
+			name:    "プレフィックスのないセクション",
+			config:  "[dev]\nregion = us-east-1\n",
+			oldName: "dev",
+			newName: "development",
+			want:    "[profile development]\nregion = us-east-1\n",
+		},
+		{
+			name:    "変更先の名前が既に存在する",
+			config:  "[profile dev]\n[profile prod]\n",
+			oldName: "dev",
+			newName: "prod",
+			wantErr: true,
+		},
+		{
+			name:    "変更元のプロファイルが存在しない",
+			config:  "[profile dev]\n",
+			oldName: "staging",
+			newName: "stg",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config")
+			if err := os.WriteFile(path, []byte(tt.config), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			err := renameProfileInConfig(path, tt.oldName, tt.newName)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("renameProfileInConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("設定ファイル = %q, want %q", data, tt.want)
+			}
+
+			profiles, err := loadAWSProfilesConcurrent(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if profileIndex(profiles, tt.newName) < 0 {
+				t.Errorf("名前を変更したプロファイル %q が読み込めません", tt.newName)
+			}
+		})
+	}
+}
+
+func TestRenameProfile(t *testing.T) {
+	tests := []struct {
+		name            string
+		source          profileSource
+		credentials     string
+		config          string
+		oldName         string
+		newName         string
+		wantCredentials string
+		wantConfig      string
+	}{
+		{
+			name:            "config のプロファイル",
+			source:          sourceConfig,
+			credentials:     "[dev]\naws_access_key_id = AKIA\n",
+			config:          "[profile dev]\nregion = us-east-1\n\n[profile child]\nsource_profile = dev\n",
+			oldName:         "dev",
+			newName:         "development",
+			wantCredentials: "[dev]\naws_access_key_id = AKIA\n",
+			wantConfig:      "[profile development]\nregion = us-east-1\n\n[profile child]\nsource_profile = development\n",
+		},
+		{
+			name:            "credentials のプロファイル",
+			source:          sourceCredentials,
+			credentials:     "[dev]\naws_access_key_id = AKIA\n\n[prod]\naws_access_key_id = AKIB\n",
+			config:          "[profile child]\nsource_profile = dev\n",
+			oldName:         "dev",
+			newName:         "development",
+			wantCredentials: "[development]\naws_access_key_id = AKIA\n\n[prod]\naws_access_key_id = AKIB\n",
+			wantConfig:      "[profile child]\nsource_profile = development\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			credentialsPath := filepath.Join(dir, "credentials")
+			configPath := filepath.Join(dir, "config")
+			t.Setenv(credentialsFileEnv, credentialsPath)
+			if err := os.WriteFile(credentialsPath, []byte(tt.credentials), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(configPath, []byte(tt.config), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			sources := profileSources{configPaths: []string{configPath}}
+			if err := renameProfile(sources, awsProfile{Name: tt.oldName, Source: tt.source}, tt.newName); err != nil {
+				t.Fatalf("renameProfile() error = %v", err)
+			}
+			for path, want := range map[string]string{credentialsPath: tt.wantCredentials, configPath: tt.wantConfig} {
+				data, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(data) != want {
+					t.Errorf("%s = %q, want %q", filepath.Base(path), data, want)
+				}
+			}
+
+			profiles, err := loadCredentialsProfiles(credentialsPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.source == sourceCredentials && profileIndex(profiles, tt.newName) < 0 {
+				t.Errorf("名前を変更したプロファイル %q が読み込めません: %v", tt.newName, profileNames(profiles))
+			}
+		})
+	}
+}
+
+func TestValidateProfileName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{name: "dev", wantErr: false},
+		{name: "team.dev-1", wantErr: false},
+		{name: "", wantErr: true},
+		{name: "a b", wantErr: true},
+		{name: "[dev]", wantErr: true},
+		{name: "dev;rm", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateProfileName(tt.name); (err != nil) != tt.wantErr {
+				t.Errorf("validateProfileName(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+		})
+	}
+}
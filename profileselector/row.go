@@ -0,0 +1,145 @@
+package profileselector
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// rowStyle は一覧の行を描画するときの、プロファイル自体以外の表示の設定です。
+// model の状態から rowStyle を作り、renderProfileRow はこの設定と引数のプロファイルだけから行を描画します。
+type rowStyle struct {
+	theme           theme
+	base            lipgloss.Style // 行の土台のスタイル (縞模様の奇数行では背景色付き)
+	cursorIndicator string         // 選択中の行の先頭に表示するカーソル
+	cursorColor     lipgloss.Color // カーソルの色 (削除モードではテーマの danger)
+	jumpKey         int            // カーソルの代わりに表示する数字キーの番号 (--jump-keys, 0 の場合は表示しない)
+	indent          string         // 木表示での source_profile の深さに応じた字下げ
+	diffLeft        bool           // 差分モードで比較元に選んだプロファイルか
+	warnNoMFA       bool           // mfa_serial のないロールのプロファイルに印を付けるか (--warn-no-mfa)
+	showAccount     bool           // アカウント ID を表示するか
+	shortRoleArn    bool           // RoleARN を短い形式で表示するか
+	note            string         // 表示するメモ (空の場合は表示しない)
+	lastUsed        string         // 最後に選択してからの経過時間 (空の場合は表示しない)
+}
+
+// rowStyle は一覧の i 番目の行を base のスタイルを土台にして描画するときの設定を返します。
+func (m model) rowStyle(i int, base lipgloss.Style) rowStyle {
+	p := m.profiles[i]
+	r := rowStyle{
+		theme:           m.theme,
+		base:            base,
+		cursorIndicator: m.cursorIndicator,
+		cursorColor:     m.cursorColor,
+		diffLeft:        m.diffState == diffPickRight && p.Name == m.diffLeft.Name && p.Source == m.diffLeft.Source,
+		warnNoMFA:       m.warnNoMFA,
+		// 詳細表示中は全てのプロファイルにアカウント ID を表示し、カーソル位置の行にはメモも表示する
+		showAccount:  m.roleArnMode != roleArnHidden,
+		shortRoleArn: m.shortRoleArn,
+	}
+	if m.deleteMode {
+		r.cursorColor = m.theme.danger
+	}
+	if m.jumpKeys && i < maxJumpKeys {
+		r.jumpKey = i + 1
+	}
+	if i < len(m.treeDepths) {
+		r.indent = treeIndent(m.treeDepths[i])
+	}
+	if m.roleArnMode != roleArnHidden && m.cursor == i {
+		r.note = m.notes[p.Name]
+	}
+	if t, ok := m.lastUsed[p.Name]; ok && !p.Unset {
+		r.lastUsed = humanizeSince(t, time.Now())
+	}
+	return r
+}
+
+// isActive は p が環境変数 AWS_DEFAULT_PROFILE などで現在有効なプロファイルかを返します。
+func (m model) isActive(p awsProfile) bool {
+	return m.activeProfile != "" && p.Name == m.activeProfile
+}
+
+// renderProfileRow は一覧のプロファイル p の行を幅 width に収めて描画します。
+// selected はカーソル位置の行か、active は現在有効なプロファイルか、showDetail は RoleARN と output を表示するかです。
+// 幅に収まらない部分は切り詰め、余った幅は縞模様の背景色で埋めます。
+func (r rowStyle) renderProfileRow(p awsProfile, selected, active bool, showDetail bool, width int) string {
+	details := renderProfileDetails(p, r.base, r.showAccount, showDetail, r.shortRoleArn, r.note, r.theme)
+	if r.lastUsed != "" {
+		details += r.base.Faint(true).Render("  " + r.lastUsed)
+	}
+	return padRow(r.renderProfileName(p, selected, active)+details, width, r.base)
+}
+
+// renderProfileName はプロファイル p の行のカーソル、名前、印を描画します。
+func (r rowStyle) renderProfileName(p awsProfile, selected, active bool) string {
+	th, base := r.theme, r.base
+	nameStyle := base
+	activeStyle := th.style().Background(th.activeBg).Foreground(th.activeFg)
+
+	cursorText := base.Render(strings.Repeat(" ", lipgloss.Width(r.cursorIndicator)))
+	if r.jumpKey > 0 {
+		// 数字キーで移動できる行には番号を表示する
+		cursorText = base.Faint(true).Render(fmt.Sprintf("%-*s", lipgloss.Width(r.cursorIndicator), fmt.Sprintf("%d ", r.jumpKey)))
+	}
+	if selected {
+		cursorText = base.Foreground(r.cursorColor).Render(r.cursorIndicator)
+		nameStyle = nameStyle.Bold(true).Underline(true)
+	}
+
+	// 現在有効なプロファイルにはカーソル位置に関係なく印と背景色を付ける
+	markers := ""
+	if active {
+		markers = base.Foreground(th.success).Render(" *")
+		nameStyle = nameStyle.Inherit(activeStyle)
+	}
+
+	// 差分モードで比較元に選んだプロファイルには印を付ける
+	if r.diffLeft {
+		markers += base.Foreground(th.note).Render(" (比較元)")
+	}
+
+	// source_profile が循環しているプロファイルには警告の印を付ける
+	if p.SourceCycle {
+		markers += base.Foreground(th.danger).Render(" ⚠")
+	}
+
+	// キャッシュされた認証情報が有効なプロファイルには緑の印を、有効期限が切れたプロファイルには
+	// 再ログイン (aws sso login など) が必要なことを示す赤いタグを付ける
+	switch p.CredentialStatus {
+	case credentialValid:
+		markers += base.Foreground(th.success).Render(" " + credentialValidTag)
+	case credentialExpired:
+		markers += base.Foreground(th.danger).Render(" " + credentialExpiredTag)
+	}
+
+	// --warn-no-mfa の指定時は、mfa_serial の書き忘れの可能性があるロールのプロファイルに控えめな印を付ける
+	if r.warnNoMFA && p.RoleArn != "" && p.MFASerial == "" {
+		markers += base.Foreground(th.warning).Faint(true).Render(" " + noMFATag)
+	}
+
+	// 選択時に MFA コードの入力が必要なプロファイルには鍵の印を付ける
+	if p.RequiresMFA {
+		markers += base.Render(" " + mfaTag)
+	}
+
+	// SSM パラメータストアから読み込んだプロファイルにはタグを付ける
+	if p.FromSSM {
+		markers += base.Foreground(th.accent).Render(" " + ssmTag)
+	}
+
+	// LocalStack などのカスタムエンドポイントを使うプロファイルにはタグを付ける
+	if p.CustomEndpoint {
+		markers += base.Foreground(th.endpoint).Render(" " + customEndpointTag)
+	}
+	// 木表示では source_profile の深さに応じて字下げする
+	if r.indent != "" {
+		cursorText += base.Faint(true).Render(r.indent)
+	}
+	if p.Unset {
+		nameStyle = nameStyle.Faint(true).Italic(true)
+	}
+	return cursorText + nameStyle.Render(p.DisplayName()) + markers
+}
@@ -0,0 +1,128 @@
+package profileselector
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// searchDebounceThreshold は検索の絞り込みを遅延させるプロファイル数です。
+// これより多いプロファイルを読み込んだ場合は、キー入力のたびに絞り込まずに searchDebounceDelay だけ待ちます。
+const searchDebounceThreshold = 500
+
+// searchDebounceDelay は検索の絞り込みを遅延させる時間です。
+const searchDebounceDelay = 50 * time.Millisecond
+
+// searchTickMsg は遅延させた検索の絞り込みを行うときに送られるメッセージです。
+type searchTickMsg struct {
+	seq int // 絞り込みを予約したときの入力の通し番号 (最新の入力でなければ無視する)
+}
+
+// newSearchInput は検索の入力欄を生成します。
+func newSearchInput() textinput.Model {
+	input := textinput.New()
+	input.Prompt = "Search: "
+	return input
+}
+
+// debounceSearchCmd は searchDebounceDelay の後に、現在の入力に対する searchTickMsg を送るコマンドを返します。
+func (m model) debounceSearchCmd() tea.Cmd {
+	seq := m.searchSeq
+	return tea.Tick(searchDebounceDelay, func(time.Time) tea.Msg {
+		return searchTickMsg{seq: seq}
+	})
+}
+
+// filterBySearch は名前または別名に query を含むプロファイルだけを返します。大文字と小文字は区別しません。
+func filterBySearch(profiles []awsProfile, query string) []awsProfile {
+	query = strings.ToLower(query)
+	var matched []awsProfile
+	for _, p := range profiles {
+		if strings.Contains(strings.ToLower(p.Name), query) || strings.Contains(strings.ToLower(p.Alias), query) {
+			matched = append(matched, p)
+		}
+	}
+	return matched
+}
+
+// updateSearchInput は検索中のキー入力を処理します。
+// 入力のたびに一覧を絞り込み、Enter で絞り込んだまま検索を終え、Esc で絞り込みを解除します。
+// 検索中も ↑ / ↓ でカーソルを移動でき、Ctrl+P / Ctrl+N で検索履歴をたどれます。
+// 入力欄が空か入力欄のカーソルが先頭にあるときは、↑ でも検索履歴をさかのぼり、たどっている間は ↓ で新しい方に戻ります。
+func (m model) updateSearchInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		// 遅延させた絞り込みが残っていれば、入力欄の内容で絞り込んでから検索を終える
+		if m.searchQuery != m.searchInput.Value() {
+			m.searchQuery = m.searchInput.Value()
+			m = m.applyFilters()
+		}
+		// 一致するプロファイルがなければ、検索を続ける
+		if len(m.profiles) == 0 {
+			return m, nil
+		}
+		m.searching = false
+		m.searchInput.Blur()
+		return m.recordSearch(m.searchQuery)
+
+	case "esc", "ctrl+c":
+		m.searching = false
+		m.searchInput.Blur()
+		m.searchInput.SetValue("")
+		m.searchQuery = ""
+		return m.applyFilters(), nil
+
+	case "up":
+		// シェルの履歴と同じように、入力欄が空か先頭にいるとき (または履歴をたどっている途中) は検索履歴をさかのぼる
+		if m.searchInput.Value() == "" || m.searchInput.Position() == 0 || m.searchHistoryIndex >= 0 {
+			return m.recallSearchHistory(true)
+		}
+		m.cursor--
+		return m.clampCursor(), nil
+
+	case "down":
+		if m.searchHistoryIndex >= 0 {
+			return m.recallSearchHistory(false)
+		}
+		if m.cursor < len(m.profiles)-1 {
+			m.cursor++
+		}
+		return m.clampCursor(), nil
+
+	case "ctrl+p":
+		return m.recallSearchHistory(true)
+
+	case "ctrl+n":
+		return m.recallSearchHistory(false)
+	}
+
+	var cmd tea.Cmd
+	m.searchInput, cmd = m.searchInput.Update(msg)
+	m.searchHistoryIndex = -1
+	return m.searchInputChanged(cmd)
+}
+
+// searchInputChanged は検索の入力欄が変わったときに一覧を絞り込みます。
+// プロファイルが多い場合は、絞り込みを遅延させるコマンドを cmd と共に返します。
+func (m model) searchInputChanged(cmd tea.Cmd) (tea.Model, tea.Cmd) {
+	if m.searchInput.Value() == m.searchQuery {
+		return m, cmd
+	}
+	if m.debounceSearch {
+		m.searchSeq++
+		return m, tea.Batch(cmd, m.debounceSearchCmd())
+	}
+	m.searchQuery = m.searchInput.Value()
+	return m.applyFilters(), cmd
+}
+
+// updateSearchTick は遅延させた検索の絞り込みを行います。最新の入力に対するものでなければ何もしません。
+func (m model) updateSearchTick(msg searchTickMsg) (tea.Model, tea.Cmd) {
+	if msg.seq != m.searchSeq || !m.searching {
+		return m, nil
+	}
+	m.searchQuery = m.searchInput.Value()
+	return m.applyFilters(), nil
+}
@@ -0,0 +1,150 @@
+package profileselector
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFilterProfilesByName(t *testing.T) {
+	profiles := testProfiles("dev", "prod", "dev-admin", "Staging")
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{query: "", want: []string{"dev", "prod", "dev-admin", "Staging"}},
+		{query: "dev", want: []string{"dev", "dev-admin"}},
+		{query: "STAG", want: []string{"Staging"}},
+		{query: "none", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got := profileNames(filterBySearch(profiles, tt.query))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("filterBySearch(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchUpRecallsHistory(t *testing.T) {
+	tests := []struct {
+		name       string
+		keys       []string
+		wantQuery  string
+		wantCursor int
+	}{
+		{name: "入力が空なら ↑ で最新の検索文字列", keys: []string{"/", "up"}, wantQuery: "prod"},
+		{name: "↑ を続けると古い検索文字列", keys: []string{"/", "up", "up"}, wantQuery: "dev"},
+		{name: "↓ で新しい検索文字列に戻る", keys: []string{"/", "up", "up", "down"}, wantQuery: "prod"},
+		{name: "最新より先に戻ると入力欄を空にする", keys: []string{"/", "up", "down"}, wantQuery: "", wantCursor: 1},
+		{name: "入力欄のカーソルが先頭なら ↑ で検索履歴", keys: []string{"/", "e", "home", "up"}, wantQuery: "prod"},
+		{name: "入力の途中では ↑ でカーソル移動", keys: []string{"/", "e", "down", "up"}, wantQuery: "e", wantCursor: 0},
+		{name: "Ctrl+P でも検索履歴", keys: []string{"/", "e", "ctrl+p"}, wantQuery: "prod"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t, options{}, testProfiles("dev", "prod", "dev-admin"))
+			m.searchHistory = []string{"dev", "prod"}
+			m = pressKeys(t, m, tt.keys...)
+			if got := m.searchInput.Value(); got != tt.wantQuery {
+				t.Errorf("検索文字列 = %q, want %q", got, tt.wantQuery)
+			}
+			if m.cursor != tt.wantCursor {
+				t.Errorf("カーソル位置 = %d, want %d", m.cursor, tt.wantCursor)
+			}
+		})
+	}
+}
+
+func TestSearchMatchCount(t *testing.T) {
+	profiles := testProfiles("dev-api", "dev-web", "prod-api", "prod-web", "staging")
+	tests := []struct {
+		name      string
+		keys      []string
+		wantCount string
+	}{
+		{name: "入力なし", keys: []string{"/"}, wantCount: "(showing 5 of 5)"},
+		{name: "1 文字", keys: []string{"/", "d"}, wantCount: "(showing 4 of 5)"},
+		{name: "2 文字", keys: []string{"/", "d", "e"}, wantCount: "(showing 2 of 5)"},
+		{name: "一致なし", keys: []string{"/", "d", "e", "x"}, wantCount: "(showing 0 of 5)"},
+		{name: "削除すると戻る", keys: []string{"/", "d", "e", "x", "backspace"}, wantCount: "(showing 2 of 5)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t, options{}, profiles)
+			m = pressKeys(t, m, tt.keys...)
+			if footer := renderFooter(m); !strings.Contains(footer, tt.wantCount) {
+				t.Errorf("フッターに %q がありません:\n%s", tt.wantCount, footer)
+			}
+		})
+	}
+}
+
+func TestSearchDebounce(t *testing.T) {
+	profiles := make([]awsProfile, searchDebounceThreshold+1)
+	for i := range profiles {
+		profiles[i] = awsProfile{Name: fmt.Sprintf("p%04d", i), Source: sourceConfig, Order: i}
+	}
+	m := newTestModel(t, options{}, profiles)
+	if !m.debounceSearch {
+		t.Fatalf("%d 件のプロファイルで debounceSearch = false", len(profiles))
+	}
+	m = pressKeys(t, m, "/", "0", "0", "4", "2")
+	if len(m.profiles) != len(profiles) {
+		t.Fatalf("遅延の前に絞り込まれました (%d 件)", len(m.profiles))
+	}
+
+	tests := []struct {
+		name string
+		msg  searchTickMsg
+		want int
+	}{
+		{name: "古い入力に対する絞り込みは無視する", msg: searchTickMsg{seq: m.searchSeq - 1}, want: len(profiles)},
+		{name: "最新の入力に対する絞り込み", msg: searchTickMsg{seq: m.searchSeq}, want: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, _ := m.Update(tt.msg)
+			if got := len(next.(model).profiles); got != tt.want {
+				t.Errorf("絞り込み後のプロファイル数 = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSearchEnterAppliesPendingQuery(t *testing.T) {
+	profiles := make([]awsProfile, searchDebounceThreshold+1)
+	for i := range profiles {
+		profiles[i] = awsProfile{Name: fmt.Sprintf("p%04d", i), Source: sourceConfig, Order: i}
+	}
+	tests := []struct {
+		name        string
+		keys        []string
+		wantCount   int
+		wantSearch  bool
+		wantHistory []string
+	}{
+		{name: "遅延中の入力で絞り込んで検索を終える", keys: []string{"/", "0", "0", "4", "2", "enter"}, wantCount: 1, wantHistory: []string{"0042"}},
+		{name: "遅延中の入力に一致するものがなければ検索を続ける", keys: []string{"/", "x", "enter"}, wantCount: 0, wantSearch: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t, options{}, profiles)
+			m = pressKeys(t, m, tt.keys...)
+			if len(m.profiles) != tt.wantCount {
+				t.Errorf("絞り込み後のプロファイル数 = %d, want %d", len(m.profiles), tt.wantCount)
+			}
+			if m.searching != tt.wantSearch {
+				t.Errorf("searching = %v, want %v", m.searching, tt.wantSearch)
+			}
+			if m.searchQuery != m.searchInput.Value() {
+				t.Errorf("searchQuery = %q, 入力欄 = %q", m.searchQuery, m.searchInput.Value())
+			}
+			if !slices.Equal(m.searchHistory, tt.wantHistory) {
+				t.Errorf("searchHistory = %v, want %v", m.searchHistory, tt.wantHistory)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package profileselector
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxSearchHistory は検索履歴ファイルに保持する最大件数です。
+const maxSearchHistory = 20
+
+// searchHistoryPath は検索履歴ファイルのパスを返します。
+func searchHistoryPath() (string, error) {
+	dir, err := toolConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "search_history.json"), nil
+}
+
+// loadSearchHistory は検索履歴ファイルを読み込みます。古い順に並んだ検索文字列を返します。
+// ファイルが存在しない場合は空の履歴を返します。
+func loadSearchHistory(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("検索履歴の読み込みに失敗しました: %w (ファイル: %s)", err, path)
+	}
+
+	var history []string
+	if err := json.Unmarshal(data, &history); err != nil {
+		return nil, fmt.Errorf("検索履歴の解析に失敗しました: %w (ファイル: %s)", err, path)
+	}
+	return history, nil
+}
+
+// saveSearchHistory は検索履歴を検索履歴ファイルに書き込みます。
+func saveSearchHistory(path string, history []string) error {
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return fmt.Errorf("検索履歴の変換に失敗しました: %w", err)
+	}
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("検索履歴の書き込みに失敗しました: %w", err)
+	}
+	return nil
+}
+
+// loadSearchHistoryOrEmpty は検索履歴を読み込みます。読み込めなかった場合は空の履歴を返します。
+func loadSearchHistoryOrEmpty() []string {
+	path, err := searchHistoryPath()
+	if err != nil {
+		return nil
+	}
+	history, _ := loadSearchHistory(path)
+	return history
+}
+
+// appendSearchHistory は検索履歴の末尾に query を追加します。
+// 直前と同じ検索文字列は追加せず、maxSearchHistory 件を超えた場合は古いものから削除します。
+func appendSearchHistory(history []string, query string) []string {
+	if n := len(history); n > 0 && history[n-1] == query {
+		return history
+	}
+	history = append(history, query)
+	if len(history) > maxSearchHistory {
+		history = history[len(history)-maxSearchHistory:]
+	}
+	return history
+}
+
+// recordSearch は確定した検索文字列を検索履歴に追加し、検索履歴ファイルに保存します。
+// 保存に失敗した場合はトーストでエラーを表示します。
+func (m model) recordSearch(query string) (model, tea.Cmd) {
+	if query == "" {
+		return m, nil
+	}
+	m.searchHistory = appendSearchHistory(m.searchHistory, query)
+	path, err := searchHistoryPath()
+	if err == nil {
+		err = saveSearchHistory(path, m.searchHistory)
+	}
+	if err != nil {
+		m.toast = err.Error()
+		m.toastID++
+		return m, clearToastCmd(m.toastID)
+	}
+	return m, nil
+}
+
+// recallSearchHistory は検索履歴をたどり、入力欄に検索文字列を入れます。
+// older が true なら 1 つ古い検索文字列に、false なら 1 つ新しい検索文字列に移り、最も新しいものより先では入力欄を空にします。
+func (m model) recallSearchHistory(older bool) (tea.Model, tea.Cmd) {
+	index := m.searchHistoryIndex
+	if older {
+		index++
+	} else {
+		index--
+	}
+	if index >= len(m.searchHistory) || index < -1 {
+		return m, nil
+	}
+	m.searchHistoryIndex = index
+
+	value := ""
+	if index >= 0 {
+		value = m.searchHistory[len(m.searchHistory)-1-index]
+	}
+	m.searchInput.SetValue(value)
+	m.searchInput.CursorEnd()
+	return m.searchInputChanged(nil)
+}
@@ -0,0 +1,50 @@
+package profileselector
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAppendSearchHistory(t *testing.T) {
+	full := make([]string, maxSearchHistory)
+	for i := range full {
+		full[i] = fmt.Sprintf("q%d", i)
+	}
+	tests := []struct {
+		name    string
+		history []string
+		query   string
+		want    []string
+	}{
+		{name: "空の履歴に追加", history: nil, query: "dev", want: []string{"dev"}},
+		{name: "直前と同じ文字列は追加しない", history: []string{"prod", "dev"}, query: "dev", want: []string{"prod", "dev"}},
+		{name: "以前と同じ文字列は追加する", history: []string{"dev", "prod"}, query: "dev", want: []string{"dev", "prod", "dev"}},
+		{name: "上限を超えたら古いものから削除", history: full, query: "new", want: append(append([]string{}, full[1:]...), "new")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history := append([]string(nil), tt.history...)
+			if got := appendSearchHistory(history, tt.query); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("appendSearchHistory() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSaveSearchHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "search_history.json")
+	want := []string{"dev", "prod"}
+	if err := saveSearchHistory(path, want); err != nil {
+		t.Fatalf("saveSearchHistory() error = %v", err)
+	}
+	got, err := loadSearchHistory(path)
+	if err != nil {
+		t.Fatalf("loadSearchHistory() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("loadSearchHistory() = %v, want %v", got, want)
+	}
+	assertNoTempFiles(t, filepath.Dir(path))
+}
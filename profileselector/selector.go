@@ -0,0 +1,173 @@
+package profileselector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Profile は Select で選んだ AWS プロファイルです。
+type Profile struct {
+	Name         string            // プロファイル名
+	Source       string            // 読み込み元ファイルの種類 ("config" または "credentials")
+	Region       string            // region (設定されていなければ空)
+	Output       string            // output (AWS CLI の出力形式, 設定されていなければ空)
+	RoleArn      string            // role_arn (設定されていなければ空)
+	AccountID    string            // role_arn から取り出したアカウント ID (取り出せなければ空)
+	MFASerial    string            // mfa_serial (設定されていなければ空)
+	Description  string            // x_description に記述されたプロファイルの説明
+	Alias        string            // 別名ファイルに記述された表示用の別名 (なければ空)
+	FromSSM      bool              // SSM パラメータストアから読み込んだか
+	Unset        bool              // --allow-unset で一覧の先頭に追加した、プロファイルの設定を解除する項目か
+	Keys         map[string]string // セクション内のキーと値 (シークレットアクセスキーなどの秘密情報は含まない)
+	EnvOverrides []string          // 選択したときに追加で設定する環境変数 ("名前=値" の形式, 名前順)
+}
+
+// newProfile は読み込んだプロファイル p を Select で返す Profile に変換します。
+func newProfile(p awsProfile) Profile {
+	return Profile{
+		Name:         p.Name,
+		Source:       string(p.Source),
+		Region:       p.Region,
+		Output:       p.Output,
+		RoleArn:      p.RoleArn,
+		AccountID:    p.AccountID,
+		MFASerial:    p.MFASerial,
+		Description:  p.Description,
+		Alias:        p.Alias,
+		FromSSM:      p.FromSSM,
+		Unset:        p.Unset,
+		Keys:         maps.Clone(p.RawKeys),
+		EnvOverrides: slices.Clone(p.EnvOverrides),
+	}
+}
+
+// toAWSProfile は p を選択結果の出力に使うプロファイルに変換します。
+func (p Profile) toAWSProfile() awsProfile {
+	profile := newAWSProfile(p.Name, p.Keys, profileSource(p.Source))
+	profile.Alias = p.Alias
+	profile.FromSSM = p.FromSSM
+	profile.Unset = p.Unset
+	profile.EnvOverrides = p.EnvOverrides
+	return profile
+}
+
+// ErrCanceled は選択画面でプロファイルを選ばずに終了したときに Select が返すエラーです。
+var ErrCanceled = errors.New("プロファイルの選択がキャンセルされました")
+
+// ProfileSelector は他の Go のプログラムにプロファイルの選択画面を組み込むためのセレクターです。
+// New で生成し、Select で選択画面を実行します。
+type ProfileSelector struct {
+	configPath string    // 読み込む設定ファイルのパス (空の場合は AWS CLI と同じく AWS_CONFIG_FILE または ~/.aws/config)
+	shell      string    // ExportCommand で返すコマンドのシェル (空の場合は bash, zsh などの POSIX シェル)
+	args       []string  // コマンドラインと同じ形式で指定するオプション (--sort last-used など)
+	input      io.Reader // キー入力を読み込む端末 (nil の場合は標準入力)
+	output     io.Writer // 選択画面を描画する端末
+}
+
+// Option は New で生成する ProfileSelector の設定です。
+type Option func(*ProfileSelector)
+
+// WithConfigPath は読み込む設定ファイルのパスを指定します。
+func WithConfigPath(path string) Option {
+	return func(s *ProfileSelector) { s.configPath = path }
+}
+
+// WithShell は ExportCommand で返すコマンドのシェル (bash, zsh, fish, powershell, direnv) を指定します。
+func WithShell(shell string) Option {
+	return func(s *ProfileSelector) { s.shell = shell }
+}
+
+// WithArgs はコマンドラインと同じ形式のオプションを指定します (例: WithArgs("--sort", "last-used", "--roles-only"))。
+func WithArgs(args ...string) Option {
+	return func(s *ProfileSelector) { s.args = append(s.args, args...) }
+}
+
+// WithInput はキー入力を読み込む端末を指定します。
+func WithInput(r io.Reader) Option {
+	return func(s *ProfileSelector) { s.input = r }
+}
+
+// WithOutput は選択画面を描画する端末を指定します。
+func WithOutput(w io.Writer) Option {
+	return func(s *ProfileSelector) { s.output = w }
+}
+
+// New は opts で設定した ProfileSelector を生成します。
+// 何も指定しない場合は、コマンドとして実行したときと同じく標準入力からキー入力を読み、標準エラー出力に選択画面を描画します。
+func New(opts ...Option) *ProfileSelector {
+	s := &ProfileSelector{output: os.Stderr}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// parseOptions は ProfileSelector の設定をプロファイルの選択のオプションに変換します。
+func (s *ProfileSelector) parseOptions() (options, error) {
+	args := append([]string(nil), s.args...)
+	if s.configPath != "" {
+		args = append(args, "--config", s.configPath)
+	}
+	if s.shell != "" {
+		args = append(args, "--shell", s.shell)
+	}
+	opts, err := parseOptionsTo(io.Discard, args)
+	if err != nil {
+		return options{}, fmt.Errorf("オプションの解析に失敗しました: %w", err)
+	}
+	return opts, nil
+}
+
+// Select は選択画面を実行し、選んだプロファイルを返します。
+// プロファイルを選ばずに終了した場合は ErrCanceled を、ctx がキャンセルされた場合は選択画面を閉じて ctx.Err() を返します。
+// 設定ファイルのパスや描画のスタイルはこの ProfileSelector の設定だけで決まり、環境変数などのプロセス全体の状態は変更しません。
+// 標準入力から設定を読み込むことはなく、選択履歴にも記録しません。
+func (s *ProfileSelector) Select(ctx context.Context) (Profile, error) {
+	opts, err := s.parseOptions()
+	if err != nil {
+		return Profile{}, err
+	}
+
+	programOpts := []tea.ProgramOption{tea.WithContext(ctx), tea.WithOutput(s.output)}
+	if s.input != nil {
+		programOpts = append(programOpts, tea.WithInput(s.input))
+	}
+	if !opts.noAltScreen {
+		programOpts = append(programOpts, tea.WithAltScreen())
+	}
+
+	finalModel, err := tea.NewProgram(initialModel(opts, newStyleRenderer(s.output, opts.noColor)), programOpts...).Run()
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return Profile{}, ctxErr
+	}
+	if err != nil {
+		return Profile{}, fmt.Errorf("選択画面の実行に失敗しました: %w", err)
+	}
+	m, ok := finalModel.(model)
+	if !ok {
+		return Profile{}, errors.New("モデルの型変換中に予期せぬエラーが発生しました")
+	}
+	if m.err != nil {
+		return Profile{}, m.err
+	}
+	if m.selectedProfile == "" || m.quitting {
+		return Profile{}, ErrCanceled
+	}
+	return newProfile(m.selectedAWSProfile()), nil
+}
+
+// ExportCommand は p を設定するシェルのコマンドを、WithShell と WithArgs の指定に従った形式で返します。
+func (s *ProfileSelector) ExportCommand(p Profile) (string, error) {
+	opts, err := s.parseOptions()
+	if err != nil {
+		return "", err
+	}
+	return formatSelection(p.toAWSProfile(), newOutputFormat(opts))
+}
@@ -0,0 +1,206 @@
+package profileselector
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"golang.org/x/sys/unix"
+)
+
+// fakeTTY は Select に渡す擬似端末です。tty を選択画面の入出力にし、pty からキーを入力して描画された内容を記録します。
+type fakeTTY struct {
+	tty *os.File // 選択画面が読み書きする側
+	pty *os.File // キー入力を書き込み、描画を読み取る側
+
+	mu  sync.Mutex
+	out bytes.Buffer
+}
+
+// openFakeTTY は幅 80、高さ 24 の擬似端末を開きます。擬似端末を使えない環境ではテストをスキップします。
+func openFakeTTY(t *testing.T) *fakeTTY {
+	t.Helper()
+	pty, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		t.Skipf("擬似端末を開けません: %v", err)
+	}
+	var n int
+	err = controlFD(pty, func(fd int) error {
+		if err := unix.IoctlSetPointerInt(fd, unix.TIOCSPTLCK, 0); err != nil {
+			return err
+		}
+		n, err = unix.IoctlGetInt(fd, unix.TIOCGPTN)
+		return err
+	})
+	if err != nil {
+		pty.Close()
+		t.Skipf("擬似端末を開けません: %v", err)
+	}
+	tty, err := os.OpenFile(fmt.Sprintf("/dev/pts/%d", n), os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		pty.Close()
+		t.Skipf("擬似端末を開けません: %v", err)
+	}
+	err = controlFD(tty, func(fd int) error {
+		return unix.IoctlSetWinsize(fd, unix.TIOCSWINSZ, &unix.Winsize{Row: 24, Col: 80})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := &fakeTTY{tty: tty, pty: pty}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		io.Copy(f, pty)
+	}()
+	t.Cleanup(func() {
+		tty.Close()
+		pty.Close()
+		<-done
+	})
+	return f
+}
+
+// controlFD は f のファイルディスクリプタで fn を実行します。
+func controlFD(f *os.File, fn func(fd int) error) error {
+	conn, err := f.SyscallConn()
+	if err != nil {
+		return err
+	}
+	var fnErr error
+	if err := conn.Control(func(fd uintptr) { fnErr = fn(int(fd)) }); err != nil {
+		return err
+	}
+	return fnErr
+}
+
+func (f *fakeTTY) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.out.Write(p)
+}
+
+// waitFor は s が描画されるまで待ちます。描画された内容は装飾のエスケープシーケンスを除いて比べ、一定時間内に描画されなければ false を返します。
+func (f *fakeTTY) waitFor(s string) bool {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		f.mu.Lock()
+		found := strings.Contains(ansi.Strip(f.out.String()), s)
+		f.mu.Unlock()
+		if found {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
+// selectOnFakeTTY は擬似端末で New(opts...) の Select を実行し、waitFor が描画されてから keys を入力した結果を返します。
+// keys が空の場合は、入力する代わりに ctx をキャンセルします。
+func selectOnFakeTTY(t *testing.T, waitFor, keys string, opts ...Option) (Profile, error) {
+	t.Helper()
+	f := openFakeTTY(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		if !f.waitFor(waitFor) || keys == "" {
+			cancel()
+			return
+		}
+		f.pty.WriteString(keys)
+	}()
+	opts = append(opts, WithInput(f.tty), WithOutput(f.tty), WithArgs("--no-altscreen"))
+	return New(opts...).Select(ctx)
+}
+
+// setupSelectorEnv は選択履歴などを一時ディレクトリに向け、profiles の設定ファイルを書き込んでそのパスを返します。
+func setupSelectorEnv(t *testing.T, profiles ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	t.Setenv("AWS_DEFAULT_PROFILE", "")
+	if err := os.MkdirAll(filepath.Join(dir, toolDirName), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	var config strings.Builder
+	for _, name := range profiles {
+		config.WriteString("[profile " + name + "]\nregion = us-east-1\n")
+	}
+	path := filepath.Join(dir, "config")
+	if err := os.WriteFile(path, []byte(config.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestProfileSelectorSelect(t *testing.T) {
+	tests := []struct {
+		name     string
+		keys     string
+		wantName string
+		wantErr  error
+	}{
+		{name: "そのまま選択", keys: "\r", wantName: "dev"},
+		{name: "下に移動して選択", keys: "j\r", wantName: "prod"},
+		{name: "選ばずに終了", keys: "q", wantErr: ErrCanceled},
+		{name: "ctx のキャンセル", keys: "", wantErr: context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := setupSelectorEnv(t, "dev", "prod")
+			got, err := selectOnFakeTTY(t, "prod", tt.keys, WithConfigPath(path))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Select() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Select() error = %v", err)
+			}
+			if got.Name != tt.wantName || got.Source != "config" || got.Region != "us-east-1" {
+				t.Errorf("Select() = %+v, want %s (config, us-east-1)", got, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestProfileSelectorSelectKeepsProcessState(t *testing.T) {
+	envConfig := setupSelectorEnv(t, "from-env")
+	t.Setenv(configFileEnv, envConfig)
+	renderer := lipgloss.DefaultRenderer()
+
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte("[profile from-option]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	got, err := selectOnFakeTTY(t, "from-option", "\r", WithConfigPath(path))
+	if err != nil || got.Name != "from-option" {
+		t.Fatalf("Select() = %q, %v, want from-option", got.Name, err)
+	}
+	if env := os.Getenv(configFileEnv); env != envConfig {
+		t.Errorf("Select() の後の %s = %q, want %q", configFileEnv, env, envConfig)
+	}
+	if lipgloss.DefaultRenderer() != renderer {
+		t.Error("Select() が lipgloss の既定のレンダラーを変更しました")
+	}
+
+	// 2 回目の Select は 1 回目の設定ファイルを引き継がない
+	got, err = selectOnFakeTTY(t, "from-env", "\r")
+	if err != nil || got.Name != "from-env" {
+		t.Fatalf("2 回目の Select() = %q, %v, want from-env", got.Name, err)
+	}
+}
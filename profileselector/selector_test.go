@@ -0,0 +1,28 @@
+package profileselector
+
+import "testing"
+
+func TestProfileSelectorExportCommand(t *testing.T) {
+	p := Profile{Name: "dev", Source: "config", Region: "ap-northeast-1", Keys: map[string]string{"region": "ap-northeast-1"}}
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{name: "bash", want: "export AWS_DEFAULT_PROFILE=dev"},
+		{name: "fish", opts: []Option{WithShell("fish")}, want: "set -gx AWS_DEFAULT_PROFILE dev"},
+		{name: "direnv", opts: []Option{WithShell("direnv")}, want: "export AWS_DEFAULT_PROFILE=dev\nexport AWS_DEFAULT_REGION=ap-northeast-1"},
+	}
+	t.Setenv(noExportEnv, "")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.opts...).ExportCommand(p)
+			if err != nil {
+				t.Fatalf("ExportCommand() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ExportCommand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package profileselector
+
+import (
+	"strings"
+
+	"gopkg.in/ini.v1"
+)
+
+// servicesSectionPrefix はサービスごとのエンドポイントを定義するセクション名の接頭辞です。
+// プロファイルの services キーでセクション名を参照します。
+//
+//	[profile localstack]
+//	services = local-services
+//
+//	[services local-services]
+//	s3 =
+//	  endpoint_url = http://localhost:4566
+const servicesSectionPrefix = "services "
+
+// customEndpointTag はカスタムエンドポイントを使うプロファイルに付けるタグです。
+const customEndpointTag = "[custom-endpoint]"
+
+// serviceEndpoints は設定 cfg の [services ...] セクションから、services セクション名ごとに、サービス ID と endpoint_url の対応を返します。
+// cfg はサービスごとのサブセクションを読み取れるよう、複数行の値を許可して読み込んだものを渡します。
+func serviceEndpoints(cfg *ini.File) map[string]map[string]string {
+	services := make(map[string]map[string]string)
+	for _, section := range cfg.Sections() {
+		if !strings.HasPrefix(section.Name(), servicesSectionPrefix) {
+			continue
+		}
+		name := strings.TrimSpace(strings.TrimPrefix(section.Name(), servicesSectionPrefix))
+		services[name] = parseServiceEndpoints(section)
+	}
+	return services
+}
+
+// parseServiceEndpoints は [services ...] セクションの各サービスのサブセクションから endpoint_url を取り出します。
+func parseServiceEndpoints(section *ini.Section) map[string]string {
+	endpoints := make(map[string]string)
+	for _, key := range section.Keys() {
+		for _, line := range strings.Split(key.Value(), "\n") {
+			name, value, ok := strings.Cut(line, "=")
+			if ok && strings.TrimSpace(name) == "endpoint_url" {
+				endpoints[key.Name()] = strings.TrimSpace(value)
+			}
+		}
+	}
+	return endpoints
+}
+
+// markCustomEndpoints は endpoint_url を持つプロファイルと、エンドポイントを上書きする services セクションを参照するプロファイルに印を付けます。
+// services セクションを参照するプロファイルには、そのセクションのエンドポイントの上書きも設定します。
+func markCustomEndpoints(profiles []awsProfile, services map[string]map[string]string) {
+	for i := range profiles {
+		p := &profiles[i]
+		p.ServiceEndpoints = services[p.RawKeys["services"]]
+		p.CustomEndpoint = p.RawKeys["endpoint_url"] != "" || len(p.ServiceEndpoints) > 0
+	}
+}
@@ -0,0 +1,67 @@
+package profileselector
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestServiceEndpoints(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   map[string]map[string]string // プロファイル名と ServiceEndpoints
+	}{
+		{
+			name:   "services セクションを参照",
+			config: "[profile local]\nservices = dev\n\n[services dev]\ns3 =\n  endpoint_url = http://localhost:4566\ndynamodb =\n  region = us-east-1\n  endpoint_url = http://localhost:8000\n",
+			want:   map[string]map[string]string{"local": {"s3": "http://localhost:4566", "dynamodb": "http://localhost:8000"}},
+		},
+		{
+			name:   "参照しないプロファイルには設定しない",
+			config: "[profile remote]\nregion = us-east-1\n\n[services dev]\ns3 =\n  endpoint_url = http://localhost:4566\n",
+			want:   map[string]map[string]string{"remote": nil},
+		},
+		{
+			name:   "存在しない services セクションの参照",
+			config: "[profile local]\nservices = missing\n",
+			want:   map[string]map[string]string{"local": nil},
+		},
+		{
+			name:   "endpoint_url のないサービスは含めない",
+			config: "[profile local]\nservices = dev\n\n[services dev]\ns3 =\n  addressing_style = path\n",
+			want:   map[string]map[string]string{"local": {}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profiles, err := loadAWSProfilesConcurrent("config", withConfigSource(strings.NewReader(tt.config)))
+			if err != nil {
+				t.Fatalf("loadAWSProfilesConcurrent() error = %v", err)
+			}
+			byName := profilesByName(profiles)
+			for name, want := range tt.want {
+				if got := byName[name].ServiceEndpoints; !reflect.DeepEqual(got, want) {
+					t.Errorf("%s の ServiceEndpoints = %v, want %v", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderPreviewShowsServiceEndpoints(t *testing.T) {
+	p := awsProfile{
+		Name:             "local",
+		Source:           sourceConfig,
+		RawKeys:          map[string]string{"services": "dev"},
+		ServiceEndpoints: map[string]string{"s3": "http://localhost:4566"},
+	}
+	out := ansi.Strip(renderPreview(p, map[string]awsProfile{"local": p}, "", 80, 20, darkTheme))
+	for _, want := range []string{"endpoints (services dev):", "s3 = http://localhost:4566"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("プレビューに %q がありません:\n%s", want, out)
+		}
+	}
+}
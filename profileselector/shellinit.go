@@ -0,0 +1,52 @@
+package profileselector
+
+import (
+	"fmt"
+	"strings"
+)
+
+// shellFunctionName は --print-init で出力するシェル関数の名前です。
+const shellFunctionName = "awsp"
+
+// supportedShells は --print-init に指定できるシェルです。
+var supportedShells = []string{"bash", "zsh", "fish"}
+
+// parseShell は --print-init に指定されたシェル名を検証します。
+func parseShell(s string) (string, error) {
+	for _, shell := range supportedShells {
+		if s == shell {
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("シェルには %s のいずれかを指定してください: %s", strings.Join(supportedShells, ", "), s)
+}
+
+// shellInitScript は選択したプロファイルを現在のシェルに設定する関数の定義を返します。
+// 選択画面は標準エラー出力に描画されるため、関数は標準出力の export 文だけを受け取って評価します。
+// command は aws-profile-selector の実行ファイルのパスです。
+func shellInitScript(shell, command string) string {
+	quoted := quoteShellWord(shell, command)
+	if shell == "fish" {
+		return fmt.Sprintf(`function %s
+    set -l cmd_output (%s --shell fish $argv); or return
+    printf '%%s\n' $cmd_output | source
+end
+`, shellFunctionName, quoted)
+	}
+	return fmt.Sprintf(`%s() {
+  local cmd_output
+  cmd_output="$(%s "$@")" || return
+  eval "$cmd_output"
+}
+`, shellFunctionName, quoted)
+}
+
+// quoteShellWord は s をシェルの 1 つの単語として扱われるようにシングルクォートで囲みます。
+func quoteShellWord(shell, s string) string {
+	if shell == "fish" {
+		// fish のシングルクォート内では \ と ' をバックスラッシュでエスケープする
+		return "'" + strings.NewReplacer(`\`, `\\`, "'", `\'`).Replace(s) + "'"
+	}
+	// bash と zsh のシングルクォート内では ' を '\'' に置き換える
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,59 @@
+package profileselector
+
+import (
+	"os"
+
+	"github.com/charmbracelet/x/term"
+)
+
+// interactiveTerminal は選択画面を表示できる端末があるかを返します。
+// 選択画面を描画する標準エラー出力が端末で、キー入力を標準入力または /dev/tty から読み込める場合に true を返します。
+func interactiveTerminal() bool {
+	if !term.IsTerminal(os.Stderr.Fd()) {
+		return false
+	}
+	if term.IsTerminal(os.Stdin.Fd()) {
+		return true
+	}
+	tty, err := os.Open("/dev/tty")
+	if err != nil {
+		return false
+	}
+	tty.Close()
+	return true
+}
+
+// selectWithoutTerminal は選択画面を表示できる端末がない場合に、起動時のカーソル位置になるプロファイル
+// (--default、AWS_PROFILE_SELECTOR_DEFAULT、AWS_DEFAULT_PROFILE の順) を選択して出力し、終了コードを返します。
+// そのプロファイルがない場合は、端末で実行するかプロファイルを指定するよう案内して 1 を返します。
+func selectWithoutTerminal(opts options, format outputFormat, resultFD *os.File) int {
+	profiles, err := loadSortedProfiles(opts.sources(), opts.sort, loadHistoryOrEmpty())
+	if err != nil {
+		logErr(opts.quiet, "エラー: %s\n", describeLoadError(err))
+		return 1
+	}
+	profiles = opts.filter.apply(profiles)
+	name := resolveInitialProfile(opts.defaultProfile, os.Getenv(envVarName(opts.envPrefix, "PROFILE")))
+	i := profileIndex(profiles, name)
+	if name == "" || i < 0 {
+		logErr(opts.quiet, "エラー: 端末がないため選択画面を起動できません。端末で実行するか、--default、--index、--first でプロファイルを指定してください (読み込みの確認だけなら --interactive=false)。\n")
+		return 1
+	}
+	logErr(opts.quiet, "端末がないため、選択画面を起動せずに '%s' を選択します。\n", name)
+	opts.index = &i
+	return selectWithoutTUI(profiles, opts, format, resultFD)
+}
+
+// runSmokeTest は選択画面を起動せずにプロファイルを読み込み、読み込めたかどうかの要約を標準エラー出力に書き込んで終了コードを返します。
+// CI で設定ファイルを解析できることを確認するために使い、読み込みに失敗した場合は 1 を返します。
+func runSmokeTest(opts options) int {
+	profiles, err := loadSortedProfiles(opts.sources(), opts.sort, nil)
+	if err != nil {
+		logErr(opts.quiet, "エラー: %s\n", describeLoadError(err))
+		return 1
+	}
+	profiles = opts.filter.apply(profiles)
+	logErr(opts.quiet, "%d 件のプロファイルを読み込みました (config: %d 件, credentials: %d 件)。\n",
+		len(profiles), len(filterBySource(profiles, sourceConfig)), len(filterBySource(profiles, sourceCredentials)))
+	return 0
+}
@@ -0,0 +1,45 @@
+package profileselector
+
+import (
+	"os"
+	"testing"
+)
+
+func TestInteractiveTerminal(t *testing.T) {
+	f := openFakeTTY(t)
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		r.Close()
+		w.Close()
+	})
+	// 標準入力がパイプの場合は /dev/tty からキー入力を読み込めるかで決まる
+	tty, ttyErr := os.Open("/dev/tty")
+	if ttyErr == nil {
+		tty.Close()
+	}
+	tests := []struct {
+		name          string
+		stdin, stderr *os.File
+		want          bool
+	}{
+		{name: "標準入力と標準エラー出力が端末", stdin: f.tty, stderr: f.tty, want: true},
+		{name: "標準エラー出力がパイプ", stdin: f.tty, stderr: w, want: false},
+		{name: "どちらもパイプ", stdin: r, stderr: w, want: false},
+		{name: "標準入力がパイプ", stdin: r, stderr: f.tty, want: ttyErr == nil},
+	}
+	stdin, stderr := os.Stdin, os.Stderr
+	t.Cleanup(func() { os.Stdin, os.Stderr = stdin, stderr })
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Stdin, os.Stderr = tt.stdin, tt.stderr
+			got := interactiveTerminal()
+			os.Stdin, os.Stderr = stdin, stderr
+			if got != tt.want {
+				t.Errorf("interactiveTerminal() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
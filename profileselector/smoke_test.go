@@ -0,0 +1,102 @@
+package profileselector
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunSelectWithoutTerminal(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv(credentialsFileEnv, filepath.Join(dir, "credentials"))
+	t.Setenv(noExportEnv, "")
+	t.Setenv(defaultProfileEnv, "")
+	t.Setenv("AWS_DEFAULT_PROFILE", "")
+	valid := filepath.Join(dir, "config")
+	broken := filepath.Join(dir, "broken")
+	files := map[string]string{valid: "[profile dev]\n[profile prod]\n", broken: "[profile dev\n"}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 標準エラー出力を置き換えるため、テストの中では常に端末がない状態になる
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStdout string
+		wantStderr string
+	}{
+		{name: "読み込めることだけを確認", args: []string{"--config", valid, "--interactive=false"}, wantStderr: "2 件のプロファイルを読み込みました (config: 2 件, credentials: 0 件)。"},
+		{name: "解析できない設定ファイル", args: []string{"--config", broken, "--interactive=false"}, wantCode: 1, wantStderr: "エラー:"},
+		{name: "存在しない設定ファイル", args: []string{"--config", filepath.Join(dir, "missing"), "--interactive=false"}, wantCode: 1, wantStderr: "エラー:"},
+		{name: "端末がなければ --default を選択", args: []string{"--config", valid, "--default", "prod"}, wantStdout: "export AWS_DEFAULT_PROFILE=prod\n", wantStderr: "'prod' を選択します"},
+		{name: "端末がなく選択するプロファイルもない", args: []string{"--config", valid}, wantCode: 1, wantStderr: "端末がないため選択画面を起動できません"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := parseOptionsTo(io.Discard, tt.args)
+			if err != nil {
+				t.Fatalf("parseOptionsTo() error = %v", err)
+			}
+			var code int
+			stdout, stderr := captureOutput(t, func() { code = runSelect(opts) })
+			if code != tt.wantCode {
+				t.Errorf("終了コード = %d, want %d (stderr: %s)", code, tt.wantCode, stderr)
+			}
+			if stdout != tt.wantStdout {
+				t.Errorf("標準出力 = %q, want %q", stdout, tt.wantStdout)
+			}
+			if !strings.Contains(stderr, tt.wantStderr) {
+				t.Errorf("標準エラー出力 = %q, want %q を含む", stderr, tt.wantStderr)
+			}
+		})
+	}
+}
+
+func TestSelectWithoutTerminal(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv(credentialsFileEnv, filepath.Join(dir, "credentials"))
+	t.Setenv(noExportEnv, "")
+	config := filepath.Join(dir, "config")
+	if err := os.WriteFile(config, []byte("[profile dev]\n[profile prod]\n[profile stg]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name          string
+		defaultFlag   string
+		selectorEnv   string
+		awsDefaultEnv string
+		wantCode      int
+		wantStdout    string
+	}{
+		{name: "--default", defaultFlag: "prod", selectorEnv: "stg", wantStdout: "export AWS_DEFAULT_PROFILE=prod\n"},
+		{name: defaultProfileEnv, selectorEnv: "stg", awsDefaultEnv: "dev", wantStdout: "export AWS_DEFAULT_PROFILE=stg\n"},
+		{name: "AWS_DEFAULT_PROFILE", awsDefaultEnv: "dev", wantStdout: "export AWS_DEFAULT_PROFILE=dev\n"},
+		{name: "存在しないプロファイル", defaultFlag: "qa", wantCode: 1},
+		{name: "指定なし", wantCode: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(defaultProfileEnv, tt.selectorEnv)
+			t.Setenv("AWS_DEFAULT_PROFILE", tt.awsDefaultEnv)
+			opts, err := parseOptionsTo(io.Discard, []string{"--config", config, "--default", tt.defaultFlag})
+			if err != nil {
+				t.Fatalf("parseOptionsTo() error = %v", err)
+			}
+			var code int
+			stdout, stderr := captureOutput(t, func() { code = selectWithoutTerminal(opts, newOutputFormat(opts), nil) })
+			if code != tt.wantCode {
+				t.Errorf("終了コード = %d, want %d (stderr: %s)", code, tt.wantCode, stderr)
+			}
+			if stdout != tt.wantStdout {
+				t.Errorf("標準出力 = %q, want %q", stdout, tt.wantStdout)
+			}
+		})
+	}
+}
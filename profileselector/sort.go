@@ -0,0 +1,130 @@
+package profileselector
+
+import (
+	"fmt"
+	"sort"
+)
+
+// sortMode はプロファイルの並び順の種類です。
+type sortMode string
+
+const (
+	sortAlpha    sortMode = "alpha"     // プロファイル名のアルファベット順
+	sortLastUsed sortMode = "last-used" // 最近選択した順
+	sortType     sortMode = "type"      // 認証情報の種類ごと
+	sortNone     sortMode = "none"      // 設定ファイルに記述された順
+)
+
+// parseSortMode は --sort に指定された文字列を sortMode に変換します。
+func parseSortMode(s string) (sortMode, error) {
+	switch mode := sortMode(s); mode {
+	case sortAlpha, sortLastUsed, sortType, sortNone:
+		return mode, nil
+	}
+	return "", fmt.Errorf("並び順には alpha, last-used, type, none のいずれかを指定してください: %s", s)
+}
+
+// credentialTypeOrder は type で並べ替えるときの認証情報の種類の順番です。
+var credentialTypeOrder = map[credentialType]int{
+	credentialIAM:     0,
+	credentialSSO:     1,
+	credentialRole:    2,
+	credentialProcess: 3,
+	credentialOther:   4,
+}
+
+// byOrder は設定ファイルに記述された順 (Order) で比較する sort.Slice 用の関数を返します。
+func byOrder(profiles []awsProfile) func(i, j int) bool {
+	return func(i, j int) bool {
+		return profiles[i].Order < profiles[j].Order
+	}
+}
+
+// byName はプロファイル名のアルファベット順で比較し、同じ名前なら設定ファイルに記述された順で比較します。
+func byName(a, b awsProfile) bool {
+	if a.Name != b.Name {
+		return a.Name < b.Name
+	}
+	return a.Order < b.Order
+}
+
+// byAlpha はプロファイル名のアルファベット順で比較する sort.Slice 用の関数を返します。
+// config と credentials に同じ名前のプロファイルがある場合は、設定ファイルに記述された順で並べます。
+func byAlpha(profiles []awsProfile) func(i, j int) bool {
+	return func(i, j int) bool {
+		return byName(profiles[i], profiles[j])
+	}
+}
+
+// byLastUsed は最近選択した順で比較する sort.Slice 用の関数を返します。
+// 選択履歴のないプロファイルは後ろに、アルファベット順で並べます。
+func byLastUsed(profiles []awsProfile, history []historyEntry) func(i, j int) bool {
+	lastUsed := lastUsedTimes(history)
+	return func(i, j int) bool {
+		ti, tj := lastUsed[profiles[i].Name], lastUsed[profiles[j].Name]
+		if !ti.Equal(tj) {
+			return ti.After(tj)
+		}
+		return byName(profiles[i], profiles[j])
+	}
+}
+
+// byType は認証情報の種類ごとに比較する sort.Slice 用の関数を返します。
+// 同じ種類のプロファイルはアルファベット順で並べます。
+func byType(profiles []awsProfile) func(i, j int) bool {
+	return func(i, j int) bool {
+		oi, oj := credentialTypeOrder[profiles[i].CredentialType()], credentialTypeOrder[profiles[j].CredentialType()]
+		if oi != oj {
+			return oi < oj
+		}
+		return byName(profiles[i], profiles[j])
+	}
+}
+
+// limitProfiles はプロファイルを最大 limit 件に絞り込みます (limit が 0 以下の場合は絞り込みません)。
+// 選択履歴があれば最近選択したものを、なければアルファベット順で先頭のものを残し、残したプロファイルは元の並び順を維持します。
+func limitProfiles(profiles []awsProfile, limit int, history []historyEntry) []awsProfile {
+	if limit <= 0 || len(profiles) <= limit {
+		return profiles
+	}
+
+	less := byAlpha(profiles)
+	if len(history) > 0 {
+		less = byLastUsed(profiles, history)
+	}
+
+	// 元のスライスのインデックスを並べ替えて上位 limit 件を選び、元の並び順に戻す
+	indexes := make([]int, len(profiles))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(a, b int) bool {
+		return less(indexes[a], indexes[b])
+	})
+	kept := indexes[:limit]
+	sort.Ints(kept)
+
+	limited := make([]awsProfile, 0, limit)
+	for _, i := range kept {
+		limited = append(limited, profiles[i])
+	}
+	return limited
+}
+
+// sortProfiles は mode に従って並べ替えたプロファイルの一覧を返します。元のスライスは変更しません。
+func sortProfiles(profiles []awsProfile, mode sortMode, history []historyEntry) []awsProfile {
+	sorted := make([]awsProfile, len(profiles))
+	copy(sorted, profiles)
+
+	switch mode {
+	case sortAlpha:
+		sort.SliceStable(sorted, byAlpha(sorted))
+	case sortLastUsed:
+		sort.SliceStable(sorted, byLastUsed(sorted, history))
+	case sortType:
+		sort.SliceStable(sorted, byType(sorted))
+	case sortNone:
+		sort.SliceStable(sorted, byOrder(sorted))
+	}
+	return sorted
+}
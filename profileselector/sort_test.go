@@ -0,0 +1,160 @@
+package profileselector
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSortProfiles(t *testing.T) {
+	profiles := []awsProfile{
+		{Name: "prod", Order: 0, RawKeys: map[string]string{"role_arn": "arn:aws:iam::123456789012:role/Admin"}},
+		{Name: "dev", Order: 1, RawKeys: map[string]string{"sso_session": "corp"}},
+		{Name: "ci", Order: 2, RawKeys: map[string]string{"aws_access_key_id": "AKIAEXAMPLE"}},
+		{Name: "batch", Order: 3, RawKeys: map[string]string{"credential_process": "/usr/bin/creds"}},
+		{Name: "admin", Order: 4, RawKeys: map[string]string{"role_arn": "arn:aws:iam::123456789012:role/Admin"}},
+	}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	history := []historyEntry{
+		{Profile: "ci", SelectedAt: now.Add(-2 * time.Hour)},
+		{Profile: "prod", SelectedAt: now.Add(-time.Hour)},
+		{Profile: "ci", SelectedAt: now},
+	}
+	tests := []struct {
+		mode    sortMode
+		history []historyEntry
+		want    []string
+	}{
+		{mode: sortAlpha, want: []string{"admin", "batch", "ci", "dev", "prod"}},
+		{mode: sortNone, want: []string{"prod", "dev", "ci", "batch", "admin"}},
+		{mode: sortType, want: []string{"ci", "dev", "admin", "prod", "batch"}},
+		{mode: sortLastUsed, history: history, want: []string{"ci", "prod", "admin", "batch", "dev"}},
+		{mode: sortLastUsed, want: []string{"admin", "batch", "ci", "dev", "prod"}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			got := profileNames(sortProfiles(profiles, tt.mode, tt.history))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sortProfiles(%s) = %v, want %v", tt.mode, got, tt.want)
+			}
+		})
+	}
+	if got := profileNames(profiles); !reflect.DeepEqual(got, []string{"prod", "dev", "ci", "batch", "admin"}) {
+		t.Errorf("sortProfiles() が元のスライスを変更しました: %v", got)
+	}
+}
+
+func TestParseSortMode(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    sortMode
+		wantErr bool
+	}{
+		{value: "alpha", want: sortAlpha},
+		{value: "last-used", want: sortLastUsed},
+		{value: "type", want: sortType},
+		{value: "none", want: sortNone},
+		{value: "random", wantErr: true},
+		{value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseSortMode(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSortMode(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseSortMode(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+// syntheticProfiles は設定ファイルに記述された順に p099, p098, ..., p000 と並ぶ n 件のプロファイルを返します。
+func syntheticProfiles(n int) []awsProfile {
+	profiles := make([]awsProfile, n)
+	for i := range profiles {
+		profiles[i] = awsProfile{Name: fmt.Sprintf("p%03d", n-1-i), Source: sourceConfig, Order: i}
+	}
+	return profiles
+}
+
+func TestLimitProfiles(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	history := []historyEntry{
+		{Profile: "p050", SelectedAt: now.Add(-time.Hour)},
+		{Profile: "p010", SelectedAt: now},
+	}
+	tests := []struct {
+		name    string
+		limit   int
+		history []historyEntry
+		want    []string
+	}{
+		{name: "履歴なしはアルファベット順で先頭のものを残す", limit: 3, want: []string{"p002", "p001", "p000"}},
+		{name: "履歴があれば最近選択したものを残す", limit: 3, history: history, want: []string{"p050", "p010", "p000"}},
+		{name: "上限がプロファイル数以上", limit: 100},
+		{name: "0 は無制限", limit: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profiles := syntheticProfiles(100)
+			got := limitProfiles(profiles, tt.limit, tt.history)
+			if tt.want == nil {
+				if !reflect.DeepEqual(got, profiles) {
+					t.Errorf("limitProfiles() で %d 件に絞り込まれました, want 100 件", len(got))
+				}
+				return
+			}
+			if len(got) != tt.limit {
+				t.Fatalf("len(limitProfiles()) = %d, want %d", len(got), tt.limit)
+			}
+			// 残したプロファイルは元の並び順 (設定ファイルに記述された順) を維持する
+			if names := profileNames(got); !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("limitProfiles() = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaxProfilesWarning(t *testing.T) {
+	m := newTestModel(t, options{maxProfiles: 10}, syntheticProfiles(100))
+	if len(m.allProfiles) != 10 {
+		t.Fatalf("len(allProfiles) = %d, want 10", len(m.allProfiles))
+	}
+	if footer := renderFooter(m); !strings.Contains(footer, "全 100 件のうち 10 件のみ表示しています") {
+		t.Errorf("フッターに件数を制限した警告がありません:\n%s", footer)
+	}
+}
+
+func TestSortProfilesBreaksTiesByOrder(t *testing.T) {
+	// config と credentials に同じ名前のプロファイルがある場合を、Order と逆の並びで渡す
+	profiles := []awsProfile{
+		{Name: "dev", Source: sourceCredentials, Order: 2},
+		{Name: "ci", Source: sourceCredentials, Order: 3},
+		{Name: "dev", Source: sourceConfig, Order: 0},
+		{Name: "prod", Source: sourceConfig, Order: 1},
+	}
+	tests := []struct {
+		mode sortMode
+		want []string
+	}{
+		{mode: sortNone, want: []string{"config:dev", "config:prod", "credentials:dev", "credentials:ci"}},
+		{mode: sortAlpha, want: []string{"credentials:ci", "config:dev", "credentials:dev", "config:prod"}},
+		{mode: sortLastUsed, want: []string{"credentials:ci", "config:dev", "credentials:dev", "config:prod"}},
+		{mode: sortType, want: []string{"credentials:ci", "config:dev", "credentials:dev", "config:prod"}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.mode), func(t *testing.T) {
+			var got []string
+			for _, p := range sortProfiles(profiles, tt.mode, nil) {
+				got = append(got, string(p.Source)+":"+p.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sortProfiles(%s) = %v, want %v", tt.mode, got, tt.want)
+			}
+		})
+	}
+}
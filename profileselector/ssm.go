@@ -0,0 +1,142 @@
+package profileselector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+)
+
+// ssmTimeout は SSM パラメータストアからプロファイルを読み込むときの制限時間です。
+const ssmTimeout = 30 * time.Second
+
+// ssmTag は SSM パラメータストアから読み込んだプロファイルに付けるタグです。
+const ssmTag = "[ssm]"
+
+// ssmParameter は SSM パラメータストアのパラメータです。
+type ssmParameter struct {
+	Name  string `json:"Name"`  // パラメータ名 (パスを含む)
+	Value string `json:"Value"` // パラメータの値
+}
+
+// ssmClient は SSM パラメータストアからパラメータを取得するクライアントです。
+type ssmClient interface {
+	// GetParametersByPath は path 以下の全てのパラメータを再帰的に取得します。
+	GetParametersByPath(ctx context.Context, path string) ([]ssmParameter, error)
+}
+
+// sdkSSMClient は aws-sdk-go-v2 で SSM パラメータストアからパラメータを取得する ssmClient です。
+// 認証情報とリージョンは AWS SDK の既定の方法 (環境変数、共有設定ファイルなど) で解決します。
+type sdkSSMClient struct {
+	api ssm.GetParametersByPathAPIClient
+}
+
+// newSDKSSMClient は AWS SDK の既定の設定を読み込み、sdkSSMClient を作成します。
+func newSDKSSMClient(ctx context.Context) (*sdkSSMClient, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("AWS SDK の設定の読み込みに失敗しました: %w", err)
+	}
+	return &sdkSSMClient{api: ssm.NewFromConfig(cfg)}, nil
+}
+
+func (c *sdkSSMClient) GetParametersByPath(ctx context.Context, path string) ([]ssmParameter, error) {
+	paginator := ssm.NewGetParametersByPathPaginator(c.api, &ssm.GetParametersByPathInput{
+		Path:           aws.String(path),
+		Recursive:      aws.Bool(true),
+		WithDecryption: aws.Bool(true),
+	})
+	var params []ssmParameter
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("SSM パラメータの取得に失敗しました: %w (パス: %s)", err, path)
+		}
+		for _, param := range page.Parameters {
+			params = append(params, ssmParameter{Name: aws.ToString(param.Name), Value: aws.ToString(param.Value)})
+		}
+	}
+	return params, nil
+}
+
+// awsProfileNamePattern は AWS のプロファイル名として使える文字列です。
+// 英数字と AWS CLI が設定ファイルのセクション名として扱える記号に限り、パスの区切りの / は含めません。
+var awsProfileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.@+=,:-]+$`)
+
+// maxAWSProfileNameLength はプロファイル名の最大の長さです。
+const maxAWSProfileNameLength = 64
+
+// validateAWSProfileName は SSM パラメータ名から作ったプロファイル名が AWS のプロファイル名の規則を満たすかを確認します。
+func validateAWSProfileName(name string) error {
+	switch {
+	case len(name) > maxAWSProfileNameLength:
+		return fmt.Errorf("プロファイル名 %q は %d 文字を超えています", name, maxAWSProfileNameLength)
+	case !awsProfileNamePattern.MatchString(name):
+		return fmt.Errorf("プロファイル名 %q には英数字と _ . @ + = , : - 以外の文字が含まれています", name)
+	}
+	return checkProfileNameForShell(name)
+}
+
+// loadProfilesFromSSM は SSM パラメータストアの prefix 以下のパラメータをプロファイルとして読み込みます。
+// prefix を除いたパラメータ名をプロファイル名に、値の JSON オブジェクト ({"region": "...", "role_arn": "..."} など) をプロファイルのキーと値にします。
+// プロファイル名が AWS のプロファイル名の規則を満たさないパラメータは読み飛ばし、quiet が false なら警告を表示します。
+// プロファイルは名前順に返します。
+func loadProfilesFromSSM(ctx context.Context, client ssmClient, prefix string, quiet bool) ([]awsProfile, error) {
+	params, err := client.GetParametersByPath(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	base := strings.TrimSuffix(prefix, "/") + "/"
+	profiles := make([]awsProfile, 0, len(params))
+	for _, param := range params {
+		name := strings.TrimPrefix(param.Name, base)
+		if name == "" || name == param.Name {
+			continue
+		}
+		if err := validateAWSProfileName(name); err != nil {
+			logErr(quiet, "警告: SSM パラメータ %s を読み飛ばします: %v\n", param.Name, err)
+			continue
+		}
+
+		var values map[string]any
+		if err := json.Unmarshal([]byte(param.Value), &values); err != nil {
+			return nil, fmt.Errorf("SSM パラメータの値を JSON オブジェクトとして解析できませんでした: %w (パラメータ: %s)", err, param.Name)
+		}
+		rawKeys := make(map[string]string, len(values))
+		for key, value := range values {
+			if s, ok := value.(string); ok {
+				rawKeys[key] = s
+			} else {
+				rawKeys[key] = fmt.Sprint(value)
+			}
+		}
+
+		p := newAWSProfile(name, rawKeys, sourceConfig)
+		p.FromSSM = true
+		profiles = append(profiles, p)
+	}
+	sort.Slice(profiles, func(i, j int) bool {
+		return profiles[i].Name < profiles[j].Name
+	})
+	return profiles, nil
+}
+
+// mergeSSMProfiles は SSM パラメータストアから読み込んだプロファイルのうち、profiles に同じ名前のないものを追加します。
+// 同じ名前のプロファイルは設定ファイルのものを優先します。
+func mergeSSMProfiles(profiles, ssmProfiles []awsProfile) []awsProfile {
+	byName := profilesByName(profiles)
+	for _, p := range ssmProfiles {
+		if _, ok := byName[p.Name]; !ok {
+			profiles = append(profiles, p)
+		}
+	}
+	return profiles
+}
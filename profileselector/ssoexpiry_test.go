@@ -0,0 +1,141 @@
+package profileselector
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeSSOToken は name のファイル名で SSO のトークンを dir に書き込みます。
+func writeSSOToken(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// ssoCacheFileName は AWS CLI が key のトークンを保存するファイル名を返します。
+func ssoCacheFileName(key string) string {
+	sum := sha1.Sum([]byte(key))
+	return hex.EncodeToString(sum[:]) + ".json"
+}
+
+func TestParseSSOExpiresAt(t *testing.T) {
+	want := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		value   string
+		wantErr bool
+	}{
+		{value: "2026-01-02T03:04:05Z"},
+		{value: "2026-01-02T12:04:05+09:00"},
+		{value: "2026-01-02T03:04:05UTC"},
+		{value: "2026/01/02 03:04:05", wantErr: true},
+		{value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseSSOExpiresAt(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSSOExpiresAt(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equal(want) {
+				t.Errorf("parseSSOExpiresAt(%q) = %v, want %v", tt.value, got, want)
+			}
+		})
+	}
+}
+
+func TestCheckSSOExpiry(t *testing.T) {
+	const startURL = "https://example.awsapps.com/start"
+	past := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	future := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	token := func(expiresAt string) string {
+		return `{"startUrl": "` + startURL + `", "expiresAt": "` + expiresAt + `"}`
+	}
+	tests := []struct {
+		name    string
+		rawKeys map[string]string
+		files   map[string]string
+		want    bool
+	}{
+		{
+			name:    "sso_start_url のトークンが期限切れ",
+			rawKeys: map[string]string{"sso_start_url": startURL},
+			files:   map[string]string{ssoCacheFileName(startURL): token(past)},
+			want:    true,
+		},
+		{
+			name:    "sso_start_url のトークンが有効",
+			rawKeys: map[string]string{"sso_start_url": startURL},
+			files:   map[string]string{ssoCacheFileName(startURL): token(future)},
+			want:    false,
+		},
+		{
+			name:    "sso_session のトークンが期限切れ",
+			rawKeys: map[string]string{"sso_session": "corp"},
+			files:   map[string]string{ssoCacheFileName("corp"): token(past)},
+			want:    true,
+		},
+		{
+			name:    "ファイル名が違っても startUrl で見つける",
+			rawKeys: map[string]string{"sso_start_url": startURL},
+			files:   map[string]string{"other.json": token(past)},
+			want:    true,
+		},
+		{
+			name:    "トークンがない",
+			rawKeys: map[string]string{"sso_start_url": startURL},
+			want:    false,
+		},
+		{
+			name:    "expiresAt を解析できない",
+			rawKeys: map[string]string{"sso_start_url": startURL},
+			files:   map[string]string{ssoCacheFileName(startURL): token("never")},
+			want:    false,
+		},
+		{
+			name:  "SSO のプロファイルではない",
+			files: map[string]string{ssoCacheFileName(startURL): token(past)},
+			want:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writeSSOToken(t, dir, name, content)
+			}
+			p := awsProfile{Name: "sso", RawKeys: tt.rawKeys}
+			if got := checkSSOExpiry(p, dir); got != tt.want {
+				t.Errorf("checkSSOExpiry() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderProfileNameCredentialTag(t *testing.T) {
+	th := asciiTheme()
+	r := rowStyle{theme: th, base: th.style(), cursorIndicator: defaultCursor}
+	tests := []struct {
+		status      credentialStatus
+		wantExpired bool
+		wantValid   bool
+	}{
+		{status: credentialUnknown},
+		{status: credentialValid, wantValid: true},
+		{status: credentialExpired, wantExpired: true},
+	}
+	for _, tt := range tests {
+		got := r.renderProfileName(awsProfile{Name: "sso", CredentialStatus: tt.status}, false, false)
+		if strings.Contains(got, credentialExpiredTag) != tt.wantExpired {
+			t.Errorf("状態 %d: %q に %s を含むか = %v, want %v", tt.status, got, credentialExpiredTag, !tt.wantExpired, tt.wantExpired)
+		}
+		if strings.Contains(got, credentialValidTag) != tt.wantValid {
+			t.Errorf("状態 %d: %q に %s を含むか = %v, want %v", tt.status, got, credentialValidTag, !tt.wantValid, tt.wantValid)
+		}
+	}
+}
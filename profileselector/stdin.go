@@ -0,0 +1,56 @@
+package profileselector
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// stdinConfigName は標準入力から読み込んだ設定を、エラーメッセージなどで表す名前です。
+const stdinConfigName = "(標準入力)"
+
+// stdinConfig は標準入力から読み込んだ設定ファイルの内容です。
+// 標準入力は一度しか読めないため、再読み込みでは最初に読み込んだ内容を使います。
+var stdinConfig struct {
+	once sync.Once
+	data []byte
+	err  error
+}
+
+// stdinIsPiped は標準入力が端末ではなく、パイプやファイルからリダイレクトされているかを返します。
+// /dev/null などのキャラクタデバイスはリダイレクトされていないものとして扱います。
+func stdinIsPiped() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice == 0
+}
+
+// configSource は設定ファイルの内容を標準入力から読み込む場合に、その内容を返す io.Reader を返します。
+// 標準入力から読み込むのは、--config が指定されずに TUI またはプロファイルの選択を行う (s.stdin が true の) ときだけです。
+// 標準入力から読み込まない場合は nil を返し、呼び出し元は設定ファイルを読み込みます。
+func (s profileSources) configSource() (io.Reader, error) {
+	if !s.stdin || !stdinIsPiped() {
+		return nil, nil
+	}
+	stdinConfig.once.Do(func() {
+		stdinConfig.data, stdinConfig.err = io.ReadAll(os.Stdin)
+	})
+	if stdinConfig.err != nil {
+		return nil, fmt.Errorf("標準入力からの設定の読み込みに失敗しました: %w", stdinConfig.err)
+	}
+	return bytes.NewReader(stdinConfig.data), nil
+}
+
+// configBaseDir は name から読み込んだ設定ファイルで、相対パスの include の基準にするディレクトリを返します。
+// 標準入力から読み込んだ場合はカレントディレクトリを基準にします。
+func configBaseDir(name string) string {
+	if name == stdinConfigName {
+		return "."
+	}
+	return filepath.Dir(name)
+}
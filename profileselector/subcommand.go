@@ -0,0 +1,47 @@
+package profileselector
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// runModeTUI は delete などのサブコマンドで、args のオプションに従ってプロファイルの一覧を表示する TUI を実行します。
+// configure では初期状態のモデルにサブコマンドごとのモードを設定します。
+// TUI を実行できなかった場合やエラーで終了した場合は、エラーを表示して終了コードと false を返します。
+func runModeTUI(args []string, configure func(model) model) (model, int, bool) {
+	opts, err := parseOptions(args)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return model{}, 0, false
+		}
+		return model{}, 2, false
+	}
+	renderer := setupStyleRenderer(opts.noColor)
+	// サブコマンドではカーソル位置のプロファイルを自動選択しない
+	opts.timeout = 0
+
+	programOpts := []tea.ProgramOption{tea.WithOutput(os.Stderr)}
+	if !opts.noAltScreen {
+		programOpts = append(programOpts, tea.WithAltScreen())
+	}
+
+	finalModel, err := tea.NewProgram(configure(initialModel(opts, renderer)), programOpts...).Run()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "CLIアプリケーションの実行に失敗しました: %v\n", err)
+		return model{}, 1, false
+	}
+	m, ok := finalModel.(model)
+	if !ok {
+		fmt.Fprintln(os.Stderr, "モデルの型変換中に予期せぬエラーが発生しました。")
+		return model{}, 1, false
+	}
+	if m.err != nil {
+		fmt.Fprintf(os.Stderr, "エラー: %v\n", m.err)
+		return model{}, 1, false
+	}
+	return m, 0, true
+}
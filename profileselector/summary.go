@@ -0,0 +1,50 @@
+package profileselector
+
+import (
+	"fmt"
+	"strings"
+)
+
+// profileTypeSummary は認証情報の種類ごとのプロファイル数を保持します。
+type profileTypeSummary struct {
+	IAM     int
+	SSO     int
+	Role    int
+	Process int
+	Other   int
+}
+
+// computeTypeSummary は認証情報の種類ごとにプロファイル数を集計します。
+func computeTypeSummary(profiles []awsProfile) profileTypeSummary {
+	var summary profileTypeSummary
+	for _, p := range profiles {
+		switch p.CredentialType() {
+		case credentialIAM:
+			summary.IAM++
+		case credentialSSO:
+			summary.SSO++
+		case credentialRole:
+			summary.Role++
+		case credentialProcess:
+			summary.Process++
+		default:
+			summary.Other++
+		}
+	}
+	return summary
+}
+
+// String は "IAM: 3  SSO: 5  Role: 12  Process: 1" の形式で集計結果を返します。
+// Other は該当するプロファイルがある場合のみ含めます。
+func (s profileTypeSummary) String() string {
+	parts := []string{
+		fmt.Sprintf("%s: %d", credentialIAM, s.IAM),
+		fmt.Sprintf("%s: %d", credentialSSO, s.SSO),
+		fmt.Sprintf("%s: %d", credentialRole, s.Role),
+		fmt.Sprintf("%s: %d", credentialProcess, s.Process),
+	}
+	if s.Other > 0 {
+		parts = append(parts, fmt.Sprintf("%s: %d", credentialOther, s.Other))
+	}
+	return strings.Join(parts, "  ")
+}
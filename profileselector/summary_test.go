@@ -0,0 +1,57 @@
+package profileselector
+
+import "testing"
+
+func TestComputeTypeSummary(t *testing.T) {
+	iam := awsProfile{Name: "iam", RawKeys: map[string]string{"aws_access_key_id": "AKIAEXAMPLE"}}
+	sso := awsProfile{Name: "sso", RawKeys: map[string]string{"sso_start_url": "https://example.awsapps.com/start"}}
+	role := awsProfile{Name: "role", RawKeys: map[string]string{"role_arn": "arn:aws:iam::123456789012:role/Admin", "source_profile": "iam"}}
+	process := awsProfile{Name: "process", RawKeys: map[string]string{"credential_process": "/usr/bin/creds"}}
+	other := awsProfile{Name: "other", RawKeys: map[string]string{"region": "us-east-1"}}
+	tests := []struct {
+		name       string
+		profiles   []awsProfile
+		want       profileTypeSummary
+		wantString string
+	}{
+		{name: "プロファイルなし", want: profileTypeSummary{}, wantString: "IAM: 0  SSO: 0  Role: 0  Process: 0"},
+		{
+			name:       "種類ごとに集計",
+			profiles:   []awsProfile{iam, sso, sso, role, role, role, process},
+			want:       profileTypeSummary{IAM: 1, SSO: 2, Role: 3, Process: 1},
+			wantString: "IAM: 1  SSO: 2  Role: 3  Process: 1",
+		},
+		{
+			name:       "どれにも当てはまらないプロファイルがあれば Other も表示",
+			profiles:   []awsProfile{iam, other, other},
+			want:       profileTypeSummary{IAM: 1, Other: 2},
+			wantString: "IAM: 1  SSO: 0  Role: 0  Process: 0  Other: 2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := computeTypeSummary(tt.profiles)
+			if got != tt.want {
+				t.Errorf("computeTypeSummary() = %+v, want %+v", got, tt.want)
+			}
+			if got.String() != tt.wantString {
+				t.Errorf("String() = %q, want %q", got.String(), tt.wantString)
+			}
+		})
+	}
+}
+
+func TestTypeSummaryIgnoresSearch(t *testing.T) {
+	profiles := []awsProfile{
+		{Name: "dev", Source: sourceConfig, RawKeys: map[string]string{"sso_session": "corp"}},
+		{Name: "prod", Source: sourceConfig, Order: 1, RawKeys: map[string]string{"role_arn": "arn:aws:iam::123456789012:role/Admin"}},
+	}
+	m := newTestModel(t, options{}, profiles)
+	m = pressKeys(t, m, "/", "p", "r", "o", "d")
+	if len(m.profiles) != 1 {
+		t.Fatalf("検索後のプロファイル = %v, want [prod]", profileNames(m.profiles))
+	}
+	if want := (profileTypeSummary{SSO: 1, Role: 1}); m.typeSummary != want {
+		t.Errorf("検索中の typeSummary = %+v, want %+v", m.typeSummary, want)
+	}
+}
@@ -0,0 +1,152 @@
+package profileselector
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// 画面上部のタブの種類です。
+const (
+	tabProfiles = iota // プロファイル一覧
+	tabRecent          // 最近選択したプロファイル
+)
+
+// tabNames はタブバーに表示するタブの名前です。インデックスはタブの種類に対応します。
+var tabNames = []string{"Profile List", "Recent"}
+
+// maxRecentEntries は Recent タブに表示する選択履歴の最大件数です。
+const maxRecentEntries = 10
+
+// recentEntries は選択履歴のうち新しいものから最大 maxRecentEntries 件を、新しい順に返します。
+func recentEntries(history []historyEntry) []historyEntry {
+	recent := make([]historyEntry, 0, maxRecentEntries)
+	for i := len(history) - 1; i >= 0 && len(recent) < maxRecentEntries; i-- {
+		recent = append(recent, history[i])
+	}
+	return recent
+}
+
+// recentProfileNames は選択履歴のうち新しいものから最大 maxRecentEntries 件のプロファイル名を、重複を除いて新しい順に返します。
+func recentProfileNames(history []historyEntry) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, e := range recentEntries(history) {
+		if !seen[e.Profile] {
+			seen[e.Profile] = true
+			names = append(names, e.Profile)
+		}
+	}
+	return names
+}
+
+// cycleRecent は最近選択したプロファイルを新しい順に 1 つずつたどり、カーソルを移動します。
+// カーソル位置のプロファイルと表示中の一覧にないプロファイルは飛ばします。選択履歴がなければ何もしません。
+func (m model) cycleRecent() model {
+	names := recentProfileNames(m.history)
+	for n := range names {
+		next := (m.recentCycle + n) % len(names)
+		i := profileIndex(m.profiles, names[next])
+		if i < 0 || i == m.cursor {
+			continue
+		}
+		m.cursor = i
+		m.recentCycle = (next + 1) % len(names)
+		return m.clampCursor()
+	}
+	return m
+}
+
+// renderTabBar は枠で囲んだタブを横に並べたタブバーを描画します (2 行)。
+func renderTabBar(current int, th theme) string {
+	tabStyle := th.style().
+		Border(lipgloss.RoundedBorder(), true, true, false, true).
+		Padding(0, 1)
+	activeStyle := tabStyle.Bold(true).Foreground(th.accent).BorderForeground(th.accent)
+	inactiveStyle := tabStyle.Faint(true).BorderForeground(th.muted)
+
+	tabs := make([]string, len(tabNames))
+	for i, name := range tabNames {
+		if i == current {
+			tabs[i] = activeStyle.Render(name)
+		} else {
+			tabs[i] = inactiveStyle.Render(name)
+		}
+	}
+	return lipgloss.JoinHorizontal(lipgloss.Bottom, tabs...)
+}
+
+// renderRecentList は Recent タブの選択履歴の一覧を、選択した日時と共に描画します。
+// 既に存在しないプロファイルは薄く表示します。
+func (m model) renderRecentList() string {
+	recent := recentEntries(m.history)
+	if len(recent) == 0 {
+		return m.theme.style().Italic(true).Render("選択履歴がありません。") + "\n"
+	}
+
+	start := 0
+	if m.listVisibleHeight > 0 && m.recentCursor >= m.listVisibleHeight {
+		start = m.recentCursor - m.listVisibleHeight + 1
+	}
+	end := start + m.listVisibleHeight
+	if end > len(recent) {
+		end = len(recent)
+	}
+
+	all := profilesByName(m.allProfiles)
+	nameWidth := 0
+	for _, e := range recent {
+		if _, ok := all[e.Profile]; ok && len(e.Profile) > nameWidth {
+			nameWidth = len(e.Profile)
+		}
+	}
+	nameWidthStyle := m.theme.style().Width(nameWidth)
+	timeStyle := m.theme.style().Faint(true)
+	var s strings.Builder
+	for i := start; i < end; i++ {
+		e := recent[i]
+		cursorText := m.cursorBlank()
+		nameStyle := m.theme.style()
+		if i == m.recentCursor {
+			cursorText = m.theme.style().Foreground(m.cursorColor).Render(m.cursorIndicator)
+			nameStyle = nameStyle.Bold(true).Underline(true)
+		}
+		name := nameWidthStyle.Render(nameStyle.Render(e.Profile))
+		if _, ok := all[e.Profile]; !ok {
+			name = m.theme.style().Faint(true).Render(e.Profile + " (見つかりません)")
+		}
+		s.WriteString(fmt.Sprintf("%s%s  %s\n", cursorText, name, timeStyle.Render(e.SelectedAt.Local().Format("2006-01-02 15:04:05"))))
+	}
+	return s.String()
+}
+
+// updateRecent は Recent タブでのキー入力を処理します。
+// Enter で選択した履歴のプロファイルを、プロファイル一覧で選択したときと同じように選択します。
+func (m model) updateRecent(key string) (tea.Model, tea.Cmd) {
+	recent := recentEntries(m.history)
+	switch {
+	case m.keys.Matches(actionQuit, key):
+		m.quitting = true
+		return m, tea.Quit
+	case m.keys.Matches(actionUp, key):
+		if m.recentCursor > 0 {
+			m.recentCursor--
+		}
+	case m.keys.Matches(actionDown, key):
+		if m.recentCursor < len(recent)-1 {
+			m.recentCursor++
+		}
+	case m.keys.Matches(actionSelect, key):
+		if m.recentCursor >= len(recent) {
+			return m, nil
+		}
+		p, ok := profilesByName(m.allProfiles)[recent[m.recentCursor].Profile]
+		if !ok {
+			return m, nil
+		}
+		return m.selectProfile(p)
+	}
+	return m, nil
+}
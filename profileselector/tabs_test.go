@@ -0,0 +1,99 @@
+package profileselector
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTabSwitching(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    options
+		keys    []string
+		wantTab int
+	}{
+		{name: "初期状態はプロファイル一覧", wantTab: tabProfiles},
+		{name: "Tab で Recent", keys: []string{"tab"}, wantTab: tabRecent},
+		{name: "Tab で一周して戻る", keys: []string{"tab", "tab"}, wantTab: tabProfiles},
+		{name: "Shift+Tab で逆順", keys: []string{"shift+tab"}, wantTab: tabRecent},
+		{name: "Tab と Shift+Tab で戻る", keys: []string{"tab", "shift+tab"}, wantTab: tabProfiles},
+		{name: "--compact では切り替えない", opts: options{compact: true}, keys: []string{"tab"}, wantTab: tabProfiles},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t, tt.opts, testProfiles("dev", "prod"))
+			m = pressKeys(t, m, tt.keys...)
+			if m.currentTab != tt.wantTab {
+				t.Errorf("currentTab = %d, want %d", m.currentTab, tt.wantTab)
+			}
+		})
+	}
+}
+
+func TestSelectFromRecentTab(t *testing.T) {
+	now := time.Now()
+	history := []historyEntry{
+		{Profile: "dev", SelectedAt: now.Add(-2 * time.Minute)},
+		{Profile: "prod", SelectedAt: now.Add(-time.Minute)},
+		{Profile: "deleted", SelectedAt: now},
+	}
+	tests := []struct {
+		name string
+		keys []string
+		want string
+	}{
+		{name: "一覧にないプロファイルは選択しない", keys: []string{"tab", "enter"}, want: ""},
+		{name: "2 番目の履歴", keys: []string{"tab", "j", "enter"}, want: "prod"},
+		{name: "3 番目の履歴", keys: []string{"tab", "j", "j", "enter"}, want: "dev"},
+		{name: "末尾より下には移動しない", keys: []string{"tab", "j", "j", "j", "enter"}, want: "dev"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t, options{}, testProfiles("dev", "prod"))
+			m.history = history
+			m = pressKeys(t, m, tt.keys...)
+			if m.selectedProfile != tt.want {
+				t.Errorf("selectedProfile = %q, want %q", m.selectedProfile, tt.want)
+			}
+		})
+	}
+}
+
+func TestCycleRecent(t *testing.T) {
+	now := time.Now()
+	history := []historyEntry{
+		{Profile: "a", SelectedAt: now.Add(-4 * time.Minute)},
+		{Profile: "c", SelectedAt: now.Add(-3 * time.Minute)},
+		{Profile: "deleted", SelectedAt: now.Add(-2 * time.Minute)},
+		{Profile: "b", SelectedAt: now.Add(-time.Minute)},
+		{Profile: "c", SelectedAt: now},
+	}
+	tests := []struct {
+		name         string
+		history      []historyEntry
+		keys         []string
+		wantCursor   string
+		wantSelected string
+	}{
+		{name: "履歴がなければ何もしない", keys: []string{"ctrl+r"}, wantCursor: "a"},
+		{name: "最近選択したプロファイル", history: history, keys: []string{"ctrl+r"}, wantCursor: "c"},
+		{name: "続けて押すと次に新しいプロファイル", history: history, keys: []string{"ctrl+r", "ctrl+r"}, wantCursor: "b"},
+		{name: "一覧にないプロファイルは飛ばす", history: history, keys: []string{"ctrl+r", "ctrl+r", "ctrl+r"}, wantCursor: "a"},
+		{name: "最後までたどると最初に戻る", history: history, keys: []string{"ctrl+r", "ctrl+r", "ctrl+r", "ctrl+r"}, wantCursor: "c"},
+		{name: "他のキーを押すと最初からたどる", history: history, keys: []string{"ctrl+r", "ctrl+r", "k", "ctrl+r"}, wantCursor: "c"},
+		{name: "Enter で選択", history: history, keys: []string{"ctrl+r", "ctrl+r", "enter"}, wantCursor: "b", wantSelected: "b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t, options{}, testProfiles("a", "b", "c", "d"))
+			m.history = tt.history
+			m = pressKeys(t, m, tt.keys...)
+			if got := m.profiles[m.cursor].Name; got != tt.wantCursor {
+				t.Errorf("カーソル位置のプロファイル = %q, want %q", got, tt.wantCursor)
+			}
+			if m.selectedProfile != tt.wantSelected {
+				t.Errorf("selectedProfile = %q, want %q", m.selectedProfile, tt.wantSelected)
+			}
+		})
+	}
+}
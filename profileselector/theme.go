@@ -0,0 +1,221 @@
+package profileselector
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// theme は画面の描画に使う色の組み合わせです。
+type theme struct {
+	accent   lipgloss.Color // タイトル、選択中のタブ、プレビューのキー名、[ssm] のタグ
+	cursor   lipgloss.Color // カーソル (環境変数 AWS_PROFILE_SELECTOR_COLOR が優先)
+	danger   lipgloss.Color // エラー、削除モード、循環や有効期限切れの印、差分の左側のみのキー
+	success  lipgloss.Color // 現在のプロファイルの印、有効な認証情報の印、通知、差分の右側のみのキー
+	warning  lipgloss.Color // 警告、[no-mfa] の印、差分の値が異なるキー
+	note     lipgloss.Color // メモ、差分の比較元の印
+	account  lipgloss.Color // アカウント ID
+	endpoint lipgloss.Color // カスタムエンドポイントのタグ
+	muted    lipgloss.Color // 枠線、選択されていないタブの枠
+	activeFg lipgloss.Color // 現在のプロファイルの文字色
+	activeBg lipgloss.Color // 現在のプロファイルの背景色
+	zebra    lipgloss.Color // 縞模様の奇数行の背景色
+
+	renderer *lipgloss.Renderer // スタイルを描画するレンダラー (nil の場合は lipgloss の既定のレンダラー)
+}
+
+// style は t のレンダラーで描画する、装飾のないスタイルを返します。
+func (t theme) style() lipgloss.Style {
+	if t.renderer == nil {
+		return lipgloss.NewStyle()
+	}
+	return t.renderer.NewStyle()
+}
+
+// colorDepth は端末で表示できる色の数です。
+type colorDepth int
+
+const (
+	color8    colorDepth = iota // 8 色 (明るい色を含めて 16 色) の端末
+	color256                    // 256 色の端末
+	truecolor                   // 24 ビットカラーの端末
+)
+
+// detectColorDepth は選択画面を描画するレンダラー r の端末で表示できる色の数を返します。
+func detectColorDepth(r *lipgloss.Renderer) colorDepth {
+	switch r.ColorProfile() {
+	case termenv.TrueColor:
+		return truecolor
+	case termenv.ANSI256:
+		return color256
+	}
+	return color8
+}
+
+// dark16Theme は暗い背景の 8 色の端末向けのテーマです。縞模様の背景色は付けません。
+var dark16Theme = theme{
+	accent:   lipgloss.Color("12"),
+	cursor:   lipgloss.Color("3"),
+	danger:   lipgloss.Color("9"),
+	success:  lipgloss.Color("10"),
+	warning:  lipgloss.Color("11"),
+	note:     lipgloss.Color("13"),
+	account:  lipgloss.Color("6"),
+	endpoint: lipgloss.Color("14"),
+	muted:    lipgloss.Color("8"),
+	activeFg: lipgloss.Color("15"),
+	activeBg: lipgloss.Color("2"),
+	zebra:    lipgloss.Color(""),
+}
+
+// darkTheme は暗い背景の 256 色の端末向けのテーマです。
+var darkTheme = theme{
+	accent:   lipgloss.Color("12"),
+	cursor:   lipgloss.Color(defaultCursorColor),
+	danger:   lipgloss.Color("9"),
+	success:  lipgloss.Color("10"),
+	warning:  lipgloss.Color("11"),
+	note:     lipgloss.Color("13"),
+	account:  lipgloss.Color("6"),
+	endpoint: lipgloss.Color("14"),
+	muted:    lipgloss.Color("8"),
+	activeFg: lipgloss.Color("15"),
+	activeBg: lipgloss.Color("22"),
+	zebra:    lipgloss.Color("236"),
+}
+
+// darkTrueColorTheme は暗い背景の 24 ビットカラーの端末向けのテーマです。
+var darkTrueColorTheme = theme{
+	accent:   lipgloss.Color("#4895EF"),
+	cursor:   lipgloss.Color("#FF8700"),
+	danger:   lipgloss.Color("#FF5F5F"),
+	success:  lipgloss.Color("#5FD75F"),
+	warning:  lipgloss.Color("#FFD75F"),
+	note:     lipgloss.Color("#D787FF"),
+	account:  lipgloss.Color("#5FAFAF"),
+	endpoint: lipgloss.Color("#5FD7FF"),
+	muted:    lipgloss.Color("#6C6C6C"),
+	activeFg: lipgloss.Color("#FFFFFF"),
+	activeBg: lipgloss.Color("#1B5E20"),
+	zebra:    lipgloss.Color("#2A2A2A"),
+}
+
+// light16Theme は明るい背景の 8 色の端末向けのテーマです。縞模様の背景色は付けません。
+var light16Theme = theme{
+	accent:   lipgloss.Color("4"),
+	cursor:   lipgloss.Color("5"),
+	danger:   lipgloss.Color("1"),
+	success:  lipgloss.Color("2"),
+	warning:  lipgloss.Color("3"),
+	note:     lipgloss.Color("13"),
+	account:  lipgloss.Color("6"),
+	endpoint: lipgloss.Color("4"),
+	muted:    lipgloss.Color("8"),
+	activeFg: lipgloss.Color("0"),
+	activeBg: lipgloss.Color("10"),
+	zebra:    lipgloss.Color(""),
+}
+
+// lightTheme は明るい背景の 256 色の端末向けのテーマです。明るい背景で読みにくい明るい色を、濃い色に置き換えます。
+var lightTheme = theme{
+	accent:   lipgloss.Color("4"),
+	cursor:   lipgloss.Color("166"),
+	danger:   lipgloss.Color("1"),
+	success:  lipgloss.Color("28"),
+	warning:  lipgloss.Color("130"),
+	note:     lipgloss.Color("5"),
+	account:  lipgloss.Color("30"),
+	endpoint: lipgloss.Color("31"),
+	muted:    lipgloss.Color("245"),
+	activeFg: lipgloss.Color("0"),
+	activeBg: lipgloss.Color("151"),
+	zebra:    lipgloss.Color("254"),
+}
+
+// lightTrueColorTheme は明るい背景の 24 ビットカラーの端末向けのテーマです。
+var lightTrueColorTheme = theme{
+	accent:   lipgloss.Color("#1D4ED8"),
+	cursor:   lipgloss.Color("#D75F00"),
+	danger:   lipgloss.Color("#C62828"),
+	success:  lipgloss.Color("#2E7D32"),
+	warning:  lipgloss.Color("#AF5F00"),
+	note:     lipgloss.Color("#8E24AA"),
+	account:  lipgloss.Color("#00796B"),
+	endpoint: lipgloss.Color("#0277BD"),
+	muted:    lipgloss.Color("#9E9E9E"),
+	activeFg: lipgloss.Color("#000000"),
+	activeBg: lipgloss.Color("#C8E6C9"),
+	zebra:    lipgloss.Color("#F0F0F0"),
+}
+
+// themeDark, themeLight, themeAuto は --theme に指定できるテーマ名です。
+const (
+	themeDark  = "dark"
+	themeLight = "light"
+	themeAuto  = "auto"
+)
+
+// parseTheme は --theme に指定されたテーマ名を検証します。
+func parseTheme(s string) (string, error) {
+	switch s {
+	case themeDark, themeLight, themeAuto:
+		return s, nil
+	}
+	return "", fmt.Errorf("テーマには %s, %s, %s のいずれかを指定してください: %s", themeDark, themeLight, themeAuto, s)
+}
+
+// resolveTheme はテーマ名と、選択画面を描画するレンダラー r の端末で表示できる色の数に対応するテーマを返します。
+// auto の場合は r の端末の背景色を問い合わせて、暗ければ dark の、明るければ light のテーマを返します。
+// 返すテーマのスタイルは r で描画します。
+func resolveTheme(name string, r *lipgloss.Renderer) theme {
+	dark := name != themeLight
+	if name == themeAuto {
+		dark = r.HasDarkBackground()
+	}
+	th := resolveColor(dark, detectColorDepth(r))
+	th.renderer = r
+	return th
+}
+
+// resolveColor は背景が暗いか (dark) と端末で表示できる色の数 depth に対応するテーマを返します。
+func resolveColor(dark bool, depth colorDepth) theme {
+	switch {
+	case dark && depth == truecolor:
+		return darkTrueColorTheme
+	case dark && depth == color256:
+		return darkTheme
+	case dark:
+		return dark16Theme
+	case depth == truecolor:
+		return lightTrueColorTheme
+	case depth == color256:
+		return lightTheme
+	}
+	return light16Theme
+}
+
+// newStyleRenderer は選択画面を描画する w に合わせたレンダラーを生成します。
+// noColor が true の場合か環境変数 NO_COLOR が設定されている場合は、全てのスタイルを色や太字などの装飾なしで描画します。
+func newStyleRenderer(w io.Writer, noColor bool) *lipgloss.Renderer {
+	r := lipgloss.NewRenderer(w)
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		r.SetColorProfile(termenv.Ascii)
+	}
+	return r
+}
+
+// setupStyleRenderer はコマンドとして実行したときに、lipgloss の既定のレンダラーを選択画面を描画する標準エラー出力に合わせて設定し、そのレンダラーを返します。
+// 選択画面のモデルは返したレンダラーを使い、既定のレンダラーは create サブコマンドの画面などで使います。
+func setupStyleRenderer(noColor bool) *lipgloss.Renderer {
+	r := newStyleRenderer(os.Stderr, noColor)
+	lipgloss.SetDefaultRenderer(r)
+	return r
+}
+
+// noteStyle はプロファイルのメモを表示するときのスタイルを返します。
+func (t theme) noteStyle() lipgloss.Style {
+	return t.style().Italic(true).Foreground(t.note)
+}
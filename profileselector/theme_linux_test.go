@@ -0,0 +1,44 @@
+package profileselector
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/termenv"
+)
+
+func TestNewStyleRendererNoColor(t *testing.T) {
+	f := openFakeTTY(t)
+	t.Setenv("TERM", "xterm-256color")
+	t.Setenv("COLORTERM", "truecolor")
+	tests := []struct {
+		name      string
+		noColor   bool
+		env       string
+		wantColor bool
+	}{
+		{name: "指定なし", wantColor: true},
+		{name: "--no-color", noColor: true},
+		{name: "NO_COLOR", env: "1"},
+		{name: "両方", noColor: true, env: "1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NO_COLOR", tt.env)
+			r := newStyleRenderer(f.tty, tt.noColor)
+			if got := r.ColorProfile() != termenv.Ascii; got != tt.wantColor {
+				t.Fatalf("色を付けるか = %v (プロファイル: %s), want %v", got, r.ColorProfile().Name(), tt.wantColor)
+			}
+
+			newTestModel(t, options{}, nil) // 一時的な設定ディレクトリを使う
+			m := initialModel(options{}, r)
+			m.loading = false
+			m = m.withLoadedProfiles(testProfiles("dev", "prod"), nil)
+			next, _ := m.Update(tea.WindowSizeMsg{Width: 100, Height: 30})
+			if got := strings.Contains(next.(model).View(), "\x1b["); got != tt.wantColor {
+				t.Errorf("View() にエスケープシーケンスを含むか = %v, want %v", got, tt.wantColor)
+			}
+		})
+	}
+}
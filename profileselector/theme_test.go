@@ -0,0 +1,145 @@
+package profileselector
+
+import (
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+func TestParseTheme(t *testing.T) {
+	tests := []struct {
+		value   string
+		wantErr bool
+	}{
+		{value: "dark"},
+		{value: "light"},
+		{value: "auto"},
+		{value: "solarized", wantErr: true},
+		{value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseTheme(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTheme(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.value {
+				t.Errorf("parseTheme(%q) = %q", tt.value, got)
+			}
+		})
+	}
+}
+
+func TestResolveTheme(t *testing.T) {
+	tests := []struct {
+		name           string
+		theme          string
+		profile        termenv.Profile
+		darkBackground bool
+		want           theme
+	}{
+		{name: "dark 256 色", theme: themeDark, profile: termenv.ANSI256, want: darkTheme},
+		{name: "light 256 色", theme: themeLight, profile: termenv.ANSI256, want: lightTheme},
+		{name: "dark 24 ビットカラー", theme: themeDark, profile: termenv.TrueColor, want: darkTrueColorTheme},
+		{name: "light 24 ビットカラー", theme: themeLight, profile: termenv.TrueColor, want: lightTrueColorTheme},
+		{name: "dark 8 色", theme: themeDark, profile: termenv.ANSI, want: dark16Theme},
+		{name: "light 8 色", theme: themeLight, profile: termenv.ANSI, want: light16Theme},
+		{name: "auto で暗い背景", theme: themeAuto, profile: termenv.ANSI256, darkBackground: true, want: darkTheme},
+		{name: "auto で明るい背景", theme: themeAuto, profile: termenv.ANSI256, darkBackground: false, want: lightTheme},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := lipgloss.NewRenderer(io.Discard)
+			r.SetColorProfile(tt.profile)
+			r.SetHasDarkBackground(tt.darkBackground)
+			got := resolveTheme(tt.theme, r)
+			if got.renderer != r {
+				t.Error("resolveTheme() のテーマが指定したレンダラーで描画しません")
+			}
+			got.renderer = nil
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolveTheme(%q) = %+v, want %+v", tt.theme, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDarkAndLightThemesDiffer(t *testing.T) {
+	tests := []struct {
+		name        string
+		dark, light theme
+	}{
+		{name: "8 色", dark: dark16Theme, light: light16Theme},
+		{name: "256 色", dark: darkTheme, light: lightTheme},
+		{name: "24 ビットカラー", dark: darkTrueColorTheme, light: lightTrueColorTheme},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := lipgloss.NewRenderer(io.Discard)
+			r.SetColorProfile(termenv.TrueColor)
+			dark, light := tt.dark, tt.light
+			dark.renderer, light.renderer = r, r
+			render := func(th theme) string {
+				return th.style().Foreground(th.accent).Render("AWS") + th.style().Foreground(th.cursor).Render(">") + th.style().Background(th.activeBg).Render("dev")
+			}
+			if render(dark) == render(light) {
+				t.Errorf("dark と light のテーマで描画結果が同じです: %q", render(dark))
+			}
+		})
+	}
+}
+
+func TestResolveColor(t *testing.T) {
+	tests := []struct {
+		name  string
+		dark  bool
+		depth colorDepth
+		want  theme
+	}{
+		{name: "dark 8 色", dark: true, depth: color8, want: dark16Theme},
+		{name: "dark 256 色", dark: true, depth: color256, want: darkTheme},
+		{name: "dark 24 ビットカラー", dark: true, depth: truecolor, want: darkTrueColorTheme},
+		{name: "light 8 色", depth: color8, want: light16Theme},
+		{name: "light 256 色", depth: color256, want: lightTheme},
+		{name: "light 24 ビットカラー", depth: truecolor, want: lightTrueColorTheme},
+	}
+	seen := map[lipgloss.Color]string{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resolveColor(tt.dark, tt.depth)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolveColor(%v, %d) = %+v, want %+v", tt.dark, tt.depth, got, tt.want)
+			}
+			// 組み合わせごとに異なるテーマを返すことを、カーソルとタイトルの色の組で確かめる
+			key := got.cursor + "/" + got.accent
+			if other, ok := seen[key]; ok {
+				t.Errorf("%s と %s のテーマの色が同じです: %s", tt.name, other, key)
+			}
+			seen[key] = tt.name
+		})
+	}
+}
+
+func TestDetectColorDepth(t *testing.T) {
+	tests := []struct {
+		profile termenv.Profile
+		want    colorDepth
+	}{
+		{profile: termenv.TrueColor, want: truecolor},
+		{profile: termenv.ANSI256, want: color256},
+		{profile: termenv.ANSI, want: color8},
+		{profile: termenv.Ascii, want: color8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.profile.Name(), func(t *testing.T) {
+			r := lipgloss.NewRenderer(io.Discard)
+			r.SetColorProfile(tt.profile)
+			if got := detectColorDepth(r); got != tt.want {
+				t.Errorf("detectColorDepth(%s) = %d, want %d", tt.profile.Name(), got, tt.want)
+			}
+		})
+	}
+}
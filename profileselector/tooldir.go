@@ -0,0 +1,45 @@
+package profileselector
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// toolDirName は設定ディレクトリの中でこのツールが使うディレクトリの名前です。
+const toolDirName = "aws-profile-selector"
+
+// legacyToolDirName は以前のバージョンが設定ファイルを置いていた、ホームディレクトリ直下のディレクトリの名前です。
+const legacyToolDirName = ".aws-profile-selector"
+
+// toolConfigDir はこのツール自身の設定ファイルや状態ファイルを置くディレクトリを返し、存在しなければ作成します。
+// 環境変数 XDG_CONFIG_HOME が絶対パスで設定されていれば $XDG_CONFIG_HOME/aws-profile-selector を、なければ ~/.config/aws-profile-selector を使います。
+// ただし、そのディレクトリがまだなく以前の場所 (~/.aws-profile-selector) がある場合は、既存の設定を引き継ぐため以前の場所を返します。
+func toolConfigDir() (string, error) {
+	home, err := userHomeDir()
+	if err != nil {
+		return "", err
+	}
+	dir := xdgToolConfigDir(os.Getenv("XDG_CONFIG_HOME"), home)
+	if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+		legacy := filepath.Join(home, legacyToolDirName)
+		if info, err := os.Stat(legacy); err == nil && info.IsDir() {
+			return legacy, nil
+		}
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("設定ディレクトリの作成に失敗しました: %w (ディレクトリ: %s)", err, dir)
+	}
+	return dir, nil
+}
+
+// xdgToolConfigDir は XDG Base Directory の仕様に従って、このツールの設定ディレクトリのパスを返します。
+// XDG_CONFIG_HOME の値 xdgConfigHome が空か相対パスの場合は、仕様どおり無視して home/.config を基準にします。
+func xdgToolConfigDir(xdgConfigHome, home string) string {
+	if !filepath.IsAbs(xdgConfigHome) {
+		xdgConfigHome = filepath.Join(home, ".config")
+	}
+	return filepath.Join(xdgConfigHome, toolDirName)
+}
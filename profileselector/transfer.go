@@ -0,0 +1,151 @@
+package profileselector
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// exportProfiles はプロファイルの一覧を整形済みの JSON として w に書き込みます。
+func exportProfiles(profiles []awsProfile, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(profiles); err != nil {
+		return fmt.Errorf("プロファイルの書き出しに失敗しました: %w", err)
+	}
+	return nil
+}
+
+// importProfiles は r から JSON 形式のプロファイル一覧を読み込み、configPath の設定ファイルに追加します。
+// 既に存在するプロファイルは overwrite が true の場合のみ上書きします。
+// 追加 (または上書き) したプロファイルの数を返します。
+func importProfiles(r io.Reader, configPath string, overwrite bool) (int, error) {
+	var profiles []awsProfile
+	if err := json.NewDecoder(r).Decode(&profiles); err != nil {
+		return 0, fmt.Errorf("インポートするプロファイルの解析に失敗しました: %w", err)
+	}
+
+	cfg, err := loadConfigForUpdate(configPath)
+	if err != nil {
+		return 0, err
+	}
+
+	added := 0
+	for _, p := range profiles {
+		if p.Name == "" {
+			continue
+		}
+
+		section := findProfileSection(cfg, p.Name)
+		if section != nil {
+			if !overwrite {
+				continue
+			}
+			for _, key := range section.KeyStrings() {
+				section.DeleteKey(key)
+			}
+		} else {
+			section, err = cfg.NewSection(profileSectionName(p.Name))
+			if err != nil {
+				return 0, fmt.Errorf("セクションの作成に失敗しました: %w (プロファイル: %s)", err, p.Name)
+			}
+		}
+
+		keys := p.RawKeys
+		if len(keys) == 0 && p.RoleArn != "" {
+			keys = map[string]string{"role_arn": p.RoleArn}
+		}
+		keyNames := make([]string, 0, len(keys))
+		for key := range keys {
+			keyNames = append(keyNames, key)
+		}
+		sort.Strings(keyNames)
+		for _, key := range keyNames {
+			if _, err := section.NewKey(key, keys[key]); err != nil {
+				return 0, fmt.Errorf("キーの設定に失敗しました: %w (プロファイル: %s, キー: %s)", err, p.Name, key)
+			}
+		}
+		added++
+	}
+
+	if added == 0 {
+		return 0, nil
+	}
+	if err := saveConfig(cfg, configPath, configFileMode); err != nil {
+		return 0, err
+	}
+	return added, nil
+}
+
+// runExport は export サブコマンドを実行し、終了コードを返します。
+func runExport(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	output := fs.String("output", "", "書き出し先の JSON ファイル (省略時は標準出力)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	profiles, err := loadAWSProfiles(profileSources{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "エラー: %s\n", describeLoadError(err))
+		return 1
+	}
+
+	w := io.Writer(os.Stdout)
+	if *output != "" && *output != "-" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "書き出し先ファイルの作成に失敗しました: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := exportProfiles(profiles, w); err != nil {
+		fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+		return 1
+	}
+	if *output != "" && *output != "-" {
+		fmt.Fprintf(os.Stderr, "%d 件のプロファイルを %s に書き出しました。\n", len(profiles), *output)
+	}
+	return 0
+}
+
+// runImport は import サブコマンドを実行し、終了コードを返します。
+func runImport(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	input := fs.String("input", "", "読み込む JSON ファイル (省略時は標準入力)")
+	overwrite := fs.Bool("overwrite", false, "既存のプロファイルを上書きする")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	configFile, err := resolveConfigPath()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+		return 1
+	}
+
+	r := io.Reader(os.Stdin)
+	if *input != "" && *input != "-" {
+		f, err := os.Open(*input)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "読み込むファイルを開けませんでした: %v\n", err)
+			return 1
+		}
+		defer f.Close()
+		r = f
+	}
+
+	added, err := importProfiles(r, configFile, *overwrite)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "エラー: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "%d 件のプロファイルを %s に追加しました。\n", added, configFile)
+	return 0
+}
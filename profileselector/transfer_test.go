@@ -0,0 +1,86 @@
+package profileselector
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportProfilesOmitsSecrets(t *testing.T) {
+	keys := map[string]string{
+		"aws_access_key_id":     "AKIAEXAMPLE",
+		"aws_secret_access_key": "super-secret",
+		"aws_session_token":     "session-token",
+		"region":                "ap-northeast-1",
+	}
+	tests := []struct {
+		name   string
+		source profileSource
+	}{
+		{name: "設定ファイル", source: sourceConfig},
+		{name: "認証情報ファイル", source: sourceCredentials},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := exportProfiles([]awsProfile{newAWSProfile("dev", keys, tt.source)}, &buf); err != nil {
+				t.Fatalf("exportProfiles() error = %v", err)
+			}
+			out := buf.String()
+			for _, secret := range []string{"aws_secret_access_key", "super-secret", "aws_session_token", "session-token"} {
+				if strings.Contains(out, secret) {
+					t.Errorf("書き出した JSON に %q が含まれています: %s", secret, out)
+				}
+			}
+			if !strings.Contains(out, "ap-northeast-1") {
+				t.Errorf("書き出した JSON に region が含まれていません: %s", out)
+			}
+		})
+	}
+	if keys["aws_secret_access_key"] == "" {
+		t.Error("newAWSProfile が引数のキーを書き換えました")
+	}
+}
+
+func TestImportProfiles(t *testing.T) {
+	input := `[
+  {"name": "dev", "rawKeys": {"region": "ap-northeast-1"}},
+  {"name": "prod", "roleArn": "arn:aws:iam::123456789012:role/Prod"},
+  {"name": "existing", "rawKeys": {"region": "us-west-2"}}
+]`
+	tests := []struct {
+		name      string
+		overwrite bool
+		wantAdded int
+		wantText  []string
+	}{
+		{name: "既存のプロファイルは残す", overwrite: false, wantAdded: 2, wantText: []string{"[profile dev]", "role_arn = arn:aws:iam::123456789012:role/Prod", "region = us-east-1"}},
+		{name: "既存のプロファイルを上書き", overwrite: true, wantAdded: 3, wantText: []string{"[profile existing]\nregion = us-west-2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config")
+			if err := os.WriteFile(path, []byte("[profile existing]\nregion = us-east-1\n"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			added, err := importProfiles(strings.NewReader(input), path, tt.overwrite)
+			if err != nil {
+				t.Fatalf("importProfiles() error = %v", err)
+			}
+			if added != tt.wantAdded {
+				t.Errorf("importProfiles() = %d, want %d", added, tt.wantAdded)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.wantText {
+				if !strings.Contains(string(data), want) {
+					t.Errorf("設定ファイル = %q, want %q を含む", data, want)
+				}
+			}
+		})
+	}
+}
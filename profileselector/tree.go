@@ -0,0 +1,55 @@
+package profileselector
+
+import "strings"
+
+// flattenProfileTree はプロファイルを source_profile の継承関係の木として並べ替え、
+// 深さ優先でたどった順のプロファイルとそれぞれの深さ (ルートが 0) を返します。
+// source_profile がない、参照先が profiles に含まれない、または循環しているプロファイルはルートになります。
+// 兄弟の順は元の並び順のままにします。
+func flattenProfileTree(profiles []awsProfile) ([]awsProfile, []int) {
+	index := make(map[string]int, len(profiles))
+	for i, p := range profiles {
+		if _, ok := index[p.Name]; !ok {
+			index[p.Name] = i
+		}
+	}
+
+	children := make([][]int, len(profiles))
+	var roots []int
+	for i, p := range profiles {
+		parent, ok := index[p.RawKeys["source_profile"]]
+		if !ok || parent == i || p.SourceCycle {
+			roots = append(roots, i)
+			continue
+		}
+		children[parent] = append(children[parent], i)
+	}
+
+	flat := make([]awsProfile, 0, len(profiles))
+	depths := make([]int, 0, len(profiles))
+	visited := make([]bool, len(profiles))
+	var walk func(i, depth int)
+	walk = func(i, depth int) {
+		if visited[i] {
+			return
+		}
+		visited[i] = true
+		flat = append(flat, profiles[i])
+		depths = append(depths, depth)
+		for _, child := range children[i] {
+			walk(child, depth+1)
+		}
+	}
+	for _, root := range roots {
+		walk(root, 0)
+	}
+	return flat, depths
+}
+
+// treeIndent は木表示で深さ depth のプロファイル名の前に置く字下げを返します。
+func treeIndent(depth int) string {
+	if depth <= 0 {
+		return ""
+	}
+	return strings.Repeat("  ", depth-1) + "└ "
+}
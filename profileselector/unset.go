@@ -0,0 +1,54 @@
+package profileselector
+
+import (
+	"os"
+	"slices"
+	"strings"
+)
+
+// unsetProfileName は --allow-unset の指定時に一覧の先頭に表示する、プロファイルの設定を解除する項目の名前です。
+const unsetProfileName = "⟨ unset profile ⟩"
+
+// unsetProfile はプロファイルの設定を解除する項目を表すプロファイルを返します。
+func unsetProfile() awsProfile {
+	return awsProfile{Name: unsetProfileName, Unset: true}
+}
+
+// withUnsetEntry は profiles の先頭にプロファイルの設定を解除する項目を追加します。
+// 木表示の深さ depths が指定されている場合は、追加した項目の深さ 0 も先頭に追加します。
+func withUnsetEntry(profiles []awsProfile, depths []int) ([]awsProfile, []int) {
+	profiles = append([]awsProfile{unsetProfile()}, profiles...)
+	if depths != nil {
+		depths = append([]int{0}, depths...)
+	}
+	return profiles, depths
+}
+
+// formatUnset はプロファイルの設定を解除するシェルのコマンドを返します。
+// format.cleanEnv が true の場合は認証情報の環境変数も削除し、direnv の形式では AWS_DEFAULT_REGION も削除します。
+// format.envPrefix が指定されている場合は、環境変数の名前の AWS_DEFAULT をその接頭辞に置き換えます。
+func formatUnset(format outputFormat) string {
+	names := []string{envVarName(format.envPrefix, "PROFILE")}
+	if format.shell == direnvShell {
+		names = append(names, envVarName(format.envPrefix, "REGION"))
+	}
+	if format.cleanEnv {
+		names = append(slices.Clone(conflictingEnvVars), names...)
+	}
+	if format.shell != "fish" {
+		return "unset " + strings.Join(names, " ")
+	}
+	lines := make([]string, len(names))
+	for i, name := range names {
+		lines[i] = "set -e " + name
+	}
+	return strings.Join(lines, "\n")
+}
+
+// environWithoutProfile は現在の環境変数から、プロファイルを指定する環境変数 (接頭辞が envPrefix のもの) と AWS_PROFILE を除いたものを返します。
+func environWithoutProfile(envPrefix string) []string {
+	profileVar := envVarName(envPrefix, "PROFILE")
+	return slices.DeleteFunc(os.Environ(), func(kv string) bool {
+		return strings.HasPrefix(kv, profileVar+"=") || strings.HasPrefix(kv, "AWS_PROFILE=")
+	})
+}
@@ -0,0 +1,73 @@
+package profileselector
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFormatSelectionUnset(t *testing.T) {
+	tests := []struct {
+		name   string
+		format outputFormat
+		want   string
+	}{
+		{name: "bash", format: outputFormat{withExport: true}, want: "unset AWS_DEFAULT_PROFILE"},
+		{name: "fish", format: outputFormat{withExport: true, shell: "fish"}, want: "set -e AWS_DEFAULT_PROFILE"},
+		{name: "direnv", format: outputFormat{withExport: true, shell: direnvShell}, want: "unset AWS_DEFAULT_PROFILE AWS_DEFAULT_REGION"},
+		{name: "接頭辞を指定", format: outputFormat{withExport: true, envPrefix: "MY"}, want: "unset MY_PROFILE"},
+		{
+			name:   "認証情報の環境変数も削除",
+			format: outputFormat{withExport: true, cleanEnv: true},
+			want:   "unset AWS_ACCESS_KEY_ID AWS_SECRET_ACCESS_KEY AWS_SESSION_TOKEN AWS_DEFAULT_PROFILE",
+		},
+		{
+			name:   "fish で認証情報の環境変数も削除",
+			format: outputFormat{withExport: true, shell: "fish", cleanEnv: true},
+			want:   "set -e AWS_ACCESS_KEY_ID\nset -e AWS_SECRET_ACCESS_KEY\nset -e AWS_SESSION_TOKEN\nset -e AWS_DEFAULT_PROFILE",
+		},
+		{name: "プロファイル名だけ", format: outputFormat{profileOnly: true}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := formatSelection(unsetProfile(), tt.format)
+			if err != nil {
+				t.Fatalf("formatSelection() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("formatSelection() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllowUnsetEntry(t *testing.T) {
+	tests := []struct {
+		name         string
+		allowUnset   bool
+		keys         []string
+		wantNames    []string
+		wantSelected string
+		wantUnset    bool
+	}{
+		{name: "指定なし", wantNames: []string{"dev", "prod"}},
+		{name: "先頭に表示", allowUnset: true, wantNames: []string{unsetProfileName, "dev", "prod"}},
+		{name: "検索中は表示しない", allowUnset: true, keys: []string{"/", "d", "e", "v", "enter"}, wantNames: []string{"dev"}},
+		{name: "選択すると設定を解除", allowUnset: true, keys: []string{"enter"}, wantNames: []string{unsetProfileName, "dev", "prod"}, wantSelected: unsetProfileName, wantUnset: true},
+		{name: "次の項目は通常のプロファイル", allowUnset: true, keys: []string{"down", "enter"}, wantNames: []string{unsetProfileName, "dev", "prod"}, wantSelected: "dev"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestModel(t, options{allowUnset: tt.allowUnset}, testProfiles("dev", "prod"))
+			m = pressKeys(t, m, tt.keys...)
+			if got := profileNames(m.profiles); !reflect.DeepEqual(got, tt.wantNames) {
+				t.Errorf("一覧 = %v, want %v", got, tt.wantNames)
+			}
+			if m.selectedProfile != tt.wantSelected {
+				t.Errorf("selectedProfile = %q, want %q", m.selectedProfile, tt.wantSelected)
+			}
+			if tt.wantSelected != "" && m.selectedAWSProfile().Unset != tt.wantUnset {
+				t.Errorf("selectedAWSProfile().Unset = %v, want %v", m.selectedAWSProfile().Unset, tt.wantUnset)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package profileselector
+
+import (
+	"sort"
+	"strings"
+)
+
+// noMFATag は --warn-no-mfa の指定時に、mfa_serial のないロールのプロファイルに付けるタグです。
+const noMFATag = "[no-mfa]"
+
+// customKeyPrefix はこのツール独自のキー (x_description など) の接頭辞です。この接頭辞を持つキーは不明なキーとして扱いません。
+const customKeyPrefix = "x_"
+
+// knownAWSConfigKeys は AWS CLI の設定ファイルと認証情報ファイルで使われる既知のキーです。
+// s3 などのサービスごとの設定はインデントされたキーとしてプロファイルのキーに含まれるため、それらも対象にします。
+var knownAWSConfigKeys = map[string]struct{}{
+	// 認証情報
+	"aws_access_key_id":     {},
+	"aws_secret_access_key": {},
+	"aws_session_token":     {},
+	"aws_account_id":        {},
+	"credential_process":    {},
+	"credential_source":     {},
+
+	// ロールの引き受け
+	"role_arn":                {},
+	"source_profile":          {},
+	"external_id":             {},
+	"mfa_serial":              {},
+	"role_session_name":       {},
+	"duration_seconds":        {},
+	"web_identity_token_file": {},
+
+	// IAM Identity Center (SSO)
+	"sso_session":             {},
+	"sso_start_url":           {},
+	"sso_region":              {},
+	"sso_account_id":          {},
+	"sso_role_name":           {},
+	"sso_registration_scopes": {},
+
+	// 一般設定
+	"region":                             {},
+	"output":                             {},
+	"ca_bundle":                          {},
+	"parameter_validation":               {},
+	"tcp_keepalive":                      {},
+	"max_attempts":                       {},
+	"retry_mode":                         {},
+	"defaults_mode":                      {},
+	"api_versions":                       {},
+	"account_id_endpoint_mode":           {},
+	"disable_request_compression":        {},
+	"request_min_compression_size_bytes": {},
+	"request_checksum_calculation":       {},
+	"response_checksum_validation":       {},
+	"sigv4a_signing_region_set":          {},
+	"sts_regional_endpoints":             {},
+
+	// エンドポイント
+	"endpoint_url":                   {},
+	"ignore_configure_endpoint_urls": {},
+	"services":                       {},
+	"use_dualstack_endpoint":         {},
+	"use_fips_endpoint":              {},
+
+	// インスタンスメタデータサービス
+	"metadata_service_timeout":           {},
+	"metadata_service_num_attempts":      {},
+	"ec2_metadata_service_endpoint":      {},
+	"ec2_metadata_service_endpoint_mode": {},
+	"ec2_metadata_v1_disabled":           {},
+
+	// AWS CLI の動作
+	"cli_auto_prompt":       {},
+	"cli_binary_format":     {},
+	"cli_history":           {},
+	"cli_pager":             {},
+	"cli_timestamp_format":  {},
+	"cli_follow_urlparam":   {},
+	"cli_help_output":       {},
+	"cli_error_format":      {},
+	"cli_connect_timeout":   {},
+	"cli_read_timeout":      {},
+	"cli_legacy_pagination": {},
+
+	// サービスごとの設定 (s3 = の下にインデントして記述するキーを含む)
+	"s3":                          {},
+	"s3api":                       {},
+	"max_concurrent_requests":     {},
+	"max_queue_size":              {},
+	"multipart_threshold":         {},
+	"multipart_chunksize":         {},
+	"max_bandwidth":               {},
+	"preferred_transfer_client":   {},
+	"target_bandwidth":            {},
+	"use_accelerate_endpoint":     {},
+	"addressing_style":            {},
+	"payload_signing_enabled":     {},
+	"use_arn_region":              {},
+	"us_east_1_regional_endpoint": {},
+}
+
+// validateProfileKeys はプロファイルのキーのうち、既知のキーに含まれないものを名前順に返します。
+// "regoin" のような書き間違いを見つけるために使用します。
+func validateProfileKeys(p awsProfile) []string {
+	var unknown []string
+	for key := range p.RawKeys {
+		if strings.HasPrefix(key, customKeyPrefix) {
+			continue
+		}
+		if _, ok := knownAWSConfigKeys[key]; !ok {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
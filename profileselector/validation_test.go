@@ -0,0 +1,41 @@
+package profileselector
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestValidateProfileKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		rawKeys map[string]string
+		want    []string
+	}{
+		{
+			name:    "既知のキーだけ",
+			rawKeys: map[string]string{"region": "ap-northeast-1", "output": "json", "role_arn": "arn:aws:iam::123456789012:role/Admin", "source_profile": "base"},
+		},
+		{name: "書き間違い", rawKeys: map[string]string{"regoin": "ap-northeast-1", "output": "json"}, want: []string{"regoin"}},
+		{name: "無関係なキー", rawKeys: map[string]string{"foo": "bar", "region": "us-east-1", "baz": "1"}, want: []string{"baz", "foo"}},
+		{name: "このツール独自のキーは対象外", rawKeys: map[string]string{"x_description": "開発用"}},
+		{name: "サービスごとの設定", rawKeys: map[string]string{"s3": "", "max_concurrent_requests": "20"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validateProfileKeys(awsProfile{Name: "dev", RawKeys: tt.rawKeys}); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("validateProfileKeys() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderPreviewWarnsUnknownKeys(t *testing.T) {
+	p := awsProfile{Name: "dev", Source: sourceConfig, RawKeys: map[string]string{"regoin": "ap-northeast-1"}}
+	out := ansi.Strip(renderPreview(p, map[string]awsProfile{"dev": p}, "", 80, 20, darkTheme))
+	if !strings.Contains(out, "不明なキー: regoin") {
+		t.Errorf("プレビューに不明なキーの警告がありません:\n%s", out)
+	}
+}
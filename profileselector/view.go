@@ -0,0 +1,292 @@
+package profileselector
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// View は現在のモデルの状態に基づいてUIを描画し、文字列として返します。
+func (m model) View() string {
+	// 代替スクリーンを使わない場合は、終了時の画面をそのままスクロールバックに残す
+	if (m.quitting || m.selectedProfile != "" || m.deletedProfile != "" || m.renamedProfile != "") && m.altScreen {
+		return ""
+	}
+
+	if m.err != nil {
+		return renderErrorScreen(m.err, m.theme)
+	}
+
+	if m.loading {
+		return m.renderLoadingScreen()
+	}
+
+	if !m.ready {
+		return "Initializing, please wait..."
+	}
+
+	if len(m.profiles) == 0 && m.currentTab == tabProfiles && m.searchQuery == "" {
+		return renderEmptyScreen(m.sourceFilter, len(m.allProfiles) > 0, m.keys.help(actionSwitchSource), m.configPath, m.theme)
+	}
+
+	if m.compact {
+		return renderCompact(m)
+	}
+
+	var s strings.Builder
+	if m.border {
+		// 枠付きの表示では、タイトルを枠の上辺に置き、タブバーから一覧までを枠で囲む
+		label := renderTitle(max(m.windowWidth-5, 0), m.deleteMode, m.renameMode, m.sourceFilter, m.theme)
+		s.WriteString(m.renderBox(label, m.renderBody()) + "\n")
+	} else {
+		s.WriteString(renderTitle(m.windowWidth, m.deleteMode, m.renameMode, m.sourceFilter, m.theme) + "\n" + m.renderBody())
+	}
+	s.WriteString(renderFooter(m))
+	return s.String()
+}
+
+// renderErrorScreen は読み込みなどでエラーが発生したときの画面を描画します。
+// エラーの種類に応じた対処を describeLoadError で添え、設定ファイルが存在しない場合は、そのパスを含むプロファイルの追加方法の案内も表示します。
+func renderErrorScreen(err error, th theme) string {
+	errorStyle := th.style().Bold(true).Foreground(th.danger)
+	help := ""
+	var notFound *ConfigNotFoundError
+	if errors.As(err, &notFound) {
+		// 設定ファイルが存在しない場合は、プロファイルがない場合と同じ案内を表示する
+		help = "\n" + emptyProfilesHelp(notFound.Path)
+	}
+	return fmt.Sprintf("\n%s\n%s\n qキーまたはCtrl+Cで終了します。\n", errorStyle.Render("初期化エラー: "+describeLoadError(err)), help)
+}
+
+// renderEmptyScreen は表示するプロファイルがないときの画面を描画します。
+// 他の読み込み元にプロファイルがある場合 (hasOtherSource) は、switchKey で読み込み元を切り替えるよう案内します。
+func renderEmptyScreen(source profileSource, hasOtherSource bool, switchKey, configPath string, th theme) string {
+	infoStyle := th.style().Foreground(th.warning)
+	if hasOtherSource {
+		return fmt.Sprintf("\n%s\n\n %sキーで読み込み元を切り替えます。qキー、Ctrl+C、またはEnterキーで終了します。\n",
+			infoStyle.Render(fmt.Sprintf("%s に利用可能なAWSプロファイルが見つかりませんでした。", source)), switchKey)
+	}
+	return fmt.Sprintf("\n%s\n\n%s\n qキー、Ctrl+C、またはEnterキーで終了します。\n", infoStyle.Render("利用可能なAWSプロファイルが見つかりませんでした。"), emptyProfilesHelp(configPath))
+}
+
+// renderTitle は画面のタイトルと読み込み元の切り替えタブを幅 width に収めて描画します。
+// 削除モードと名前の変更モードでは、それぞれのモードのタイトルにします。
+// タブまで収まらない場合はタブを省き、タイトルも収まらない場合は切り詰めます。
+func renderTitle(width int, deleteMode, renameMode bool, source profileSource, th theme) string {
+	titleStyle := th.style().Bold(true).Foreground(th.accent)
+	title := "AWSプロファイルを選択してください"
+	if deleteMode {
+		titleStyle = titleStyle.Foreground(th.danger)
+		title = "削除するAWSプロファイルを選択してください"
+	} else if renameMode {
+		title = "名前を変更するAWSプロファイルを選択してください"
+	}
+	tabs := renderSourceTabs(source, th)
+	if lipgloss.Width(title)+2+lipgloss.Width(tabs) > width {
+		return titleStyle.Render(ansi.Truncate(title, width, "…"))
+	}
+	return titleStyle.Render(title) + "  " + tabs
+}
+
+// renderBody はタブバーから一覧 (または選択履歴、差分) までを描画します。
+func (m model) renderBody() string {
+	var body strings.Builder
+	body.WriteString(renderTabBar(m.currentTab, m.theme) + "\n")
+	body.WriteString(m.theme.style().Faint(true).Render(ansi.Truncate(m.typeSummary.String(), m.innerWidth(), "…")) + "\n")
+	if !m.border {
+		body.WriteString(m.theme.style().Faint(true).Render(strings.Repeat("─", m.windowWidth)) + "\n")
+	}
+
+	switch {
+	case m.listVisibleHeight <= 0:
+		body.WriteString(m.theme.style().Italic(true).Render("ウィンドウサイズが小さすぎます。") + "\n")
+	case m.currentTab == tabRecent:
+		body.WriteString(m.renderRecentList())
+	case m.diffState == diffShowing:
+		body.WriteString(m.renderDiff())
+	case len(m.profiles) == 0:
+		body.WriteString(m.theme.style().Italic(true).Render(fmt.Sprintf("%q に一致するプロファイルがありません。", m.searchQuery)) + "\n")
+	default:
+		body.WriteString(m.renderProfileList())
+		if m.previewHeight > 0 {
+			body.WriteString(renderBottomPreview(m.profiles[m.cursor], m.innerWidth(), m.previewHeight, m.theme))
+		}
+	}
+	return body.String()
+}
+
+// renderProfileDetails は一覧の行でプロファイル名と印の後ろに続く、アカウント ID、説明、RoleARN などを base のスタイルを土台にして描画します。
+// showAccount はアカウント ID を、showDetail は RoleARN と output を表示するかで、shortRoleArn が true の場合は RoleARN を短い形式で表示します。
+// note が空でなければメモも表示します。
+func renderProfileDetails(p awsProfile, base lipgloss.Style, showAccount, showDetail, shortRoleArn bool, note string, th theme) string {
+	roleArnStyle := base.Faint(true).Italic(true)
+
+	details := ""
+	if showAccount && p.AccountID != "" {
+		details += base.Foreground(th.account).Render(" [" + p.AccountID + "]")
+	}
+	if p.Description != "" {
+		details += base.Faint(true).Render("  " + p.Description)
+	}
+	if showDetail && p.RoleArn != "" {
+		roleArn := p.RoleArn
+		if shortRoleArn {
+			roleArn = shortenRoleArn(roleArn)
+		}
+		details += roleArnStyle.Render(fmt.Sprintf(" (RoleARN: %s)", roleArn))
+	}
+	if showDetail && p.Output != "" {
+		details += roleArnStyle.Render(fmt.Sprintf(" (output: %s)", p.Output))
+	}
+	if note != "" {
+		details += th.noteStyle().Inherit(base).Render(" メモ: " + note)
+	}
+	return details
+}
+
+// footerTexts は画面下部に表示する操作の説明と状態を、現在のモードに合わせて返します。
+func (m model) footerTexts() (helpText, statusText string) {
+	statusText = fmt.Sprintf("プロファイル %d/%d", m.cursor+1, len(m.profiles)) + m.pendingMotion()
+	if m.searchQuery != "" {
+		statusText += fmt.Sprintf("  検索: %q (%s で変更)", m.searchQuery, m.keys.help(actionSearch))
+	}
+	helpText = m.listHelpText(m.roleArnMode)
+	switch m.diffState {
+	case diffPickLeft:
+		statusText += "  差分: 比較元のプロファイルを選択してください"
+		helpText = fmt.Sprintf("%s:上, %s:下, %s:比較元を選択, Esc:キャンセル", m.keys.help(actionUp), m.keys.help(actionDown), m.keys.help(actionSelect))
+	case diffPickRight:
+		statusText += fmt.Sprintf("  差分: %s と比較するプロファイルを選択してください", m.diffLeft.Name)
+		helpText = fmt.Sprintf("%s:上, %s:下, %s:比較先を選択, Esc:キャンセル", m.keys.help(actionUp), m.keys.help(actionDown), m.keys.help(actionSelect))
+	case diffShowing:
+		statusText = fmt.Sprintf("差分: %s ↔ %s", m.diffLeft.Name, m.diffRight.Name)
+		helpText = fmt.Sprintf("Esc:戻る, %s:終了", m.keys.help(actionQuit))
+	}
+	if m.deleteMode {
+		helpText = fmt.Sprintf("%s:上, %s:下, %s:削除, %s:読込元切替, %s:終了",
+			m.keys.help(actionUp), m.keys.help(actionDown), m.keys.help(actionSelect),
+			m.keys.help(actionSwitchSource), m.keys.help(actionQuit))
+		if m.confirmingDelete {
+			statusText = m.theme.style().Bold(true).Foreground(m.theme.danger).
+				Render(fmt.Sprintf("'%s' を完全に削除しますか? (y/N)", m.profiles[m.cursor].Name))
+		}
+	}
+	if m.renameMode {
+		helpText = fmt.Sprintf("%s:上, %s:下, %s:名前を変更, %s:読込元切替, %s:終了",
+			m.keys.help(actionUp), m.keys.help(actionDown), m.keys.help(actionSelect),
+			m.keys.help(actionSwitchSource), m.keys.help(actionQuit))
+	}
+	if m.currentTab == tabRecent {
+		statusText = fmt.Sprintf("最近の選択 %d/%d", m.recentCursor+1, len(recentEntries(m.history)))
+		helpText = fmt.Sprintf("%s:上, %s:下, %s:選択, %s/%s:タブ切替, %s:終了",
+			m.keys.help(actionUp), m.keys.help(actionDown), m.keys.help(actionSelect),
+			m.keys.help(actionNextTab), m.keys.help(actionPrevTab), m.keys.help(actionQuit))
+	} else if m.columnCount() > 1 {
+		helpText = m.columnsHelpPrefix() + helpText
+	}
+	return helpText, statusText
+}
+
+// listHelpText はプロファイルの一覧を表示しているときの操作の説明を、RoleARN の表示方法 mode に合わせて返します。
+func (m model) listHelpText(mode roleArnMode) string {
+	return fmt.Sprintf("%s:上, %s:下, %s:選択, %s:RoleARN表示切替 (%s), %s:RoleARN短縮表示, %s:編集, %s:再読込, %s:読込元切替, %s:列表示切替, %s:名前をコピー, %s:メモ, %s:差分, %s:最近の選択をたどる, %s:検索, %s%s/%s:先頭/末尾, %s/%s:タブ切替, %s:終了",
+		m.keys.help(actionUp), m.keys.help(actionDown), m.keys.help(actionSelect),
+		m.keys.help(actionToggleDetail), mode, m.keys.help(actionShortRoleArn), m.keys.help(actionEdit), m.keys.help(actionReload),
+		m.keys.help(actionSwitchSource), m.keys.help(actionToggleColumns), m.keys.help(actionCopy),
+		m.keys.help(actionNote), m.keys.help(actionDiff), m.keys.help(actionCycleRecent), m.keys.help(actionSearch), m.keys.help(actionTop), m.keys.help(actionTop), m.keys.help(actionBottom),
+		m.keys.help(actionNextTab), m.keys.help(actionPrevTab), m.keys.help(actionQuit))
+}
+
+// columnsHelpPrefix は複数列表示のときに操作の説明の先頭に加える、左右の移動の説明を返します。
+func (m model) columnsHelpPrefix() string {
+	return fmt.Sprintf("%s:左, %s:右, ", m.keys.help(actionLeft), m.keys.help(actionRight))
+}
+
+// helpHeight はフッターの操作の説明をウィンドウの幅で折り返したときの行数を返します。
+// 操作の説明はモードや表示方法で変わるため、最も長くなる一覧の表示 (複数列表示の左右の移動を含む) が、
+// どの RoleARN の表示方法でも収まる行数を返します。
+func (m model) helpHeight() int {
+	height := 1
+	for _, mode := range []roleArnMode{roleArnHidden, roleArnCursor, roleArnAll} {
+		height = max(height, lipgloss.Height(wrapHelpText(m.columnsHelpPrefix()+m.listHelpText(mode), m.windowWidth)))
+	}
+	return height
+}
+
+// wrapHelpText は ", " で区切った操作の説明を、項目の途中で改行しないように幅 width で折り返します。
+// 1 つの項目だけで width を超える場合は、末尾を省略して width に収めます。width が 0 以下の場合は折り返しません。
+func wrapHelpText(text string, width int) string {
+	if width <= 0 {
+		return text
+	}
+	items := strings.Split(text, ", ")
+	var lines []string
+	line := ""
+	for i, item := range items {
+		if i < len(items)-1 {
+			item += ","
+		}
+		if line != "" && ansi.StringWidth(line+" "+item) <= width {
+			line += " " + item
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		line = ansi.Truncate(item, width, "…")
+	}
+	return strings.Join(append(lines, line), "\n")
+}
+
+// renderFooter は一覧の下に表示する入力欄または操作の説明と状態、通知、警告を描画します。
+func renderFooter(m model) string {
+	var s strings.Builder
+	faintStyle := m.theme.style().Faint(true)
+	if !m.border {
+		s.WriteString(faintStyle.Render(strings.Repeat("─", m.windowWidth)) + "\n")
+	}
+
+	switch {
+	case m.editingNote:
+		s.WriteString(m.noteInput.View() + "\n")
+		s.WriteString(faintStyle.Render(wrapHelpText("Enter:保存 (空にするとメモを削除), Esc:キャンセル", m.windowWidth)))
+	case m.searching:
+		s.WriteString(m.searchInput.View() + faintStyle.Render(fmt.Sprintf(" (showing %d of %d)", len(m.profiles), len(filterBySource(m.allProfiles, m.sourceFilter)))) + "\n")
+		s.WriteString(faintStyle.Render(wrapHelpText("Enter:絞り込みを確定, Esc:検索を解除, ↑/↓:移動 (入力が空か先頭では検索履歴), Ctrl+P/Ctrl+N:検索履歴", m.windowWidth)))
+	case m.enteringMFA:
+		s.WriteString(m.mfaInput.View() + "\n")
+		if m.mfaPending {
+			s.WriteString(faintStyle.Render("一時的な認証情報を取得しています..."))
+		} else {
+			s.WriteString(faintStyle.Render(wrapHelpText(fmt.Sprintf("'%s' の MFA コード (6 桁) を入力してください。Enter:認証, Esc:キャンセル", m.mfaTarget.Name), m.windowWidth)))
+		}
+	case m.renaming:
+		s.WriteString(m.renameInput.View() + "\n")
+		s.WriteString(faintStyle.Render(wrapHelpText("Enter:変更 (source_profile の参照も書き換えます), Esc:キャンセル", m.windowWidth)))
+	default:
+		helpText, statusText := m.footerTexts()
+		s.WriteString(faintStyle.Render(wrapHelpText(helpText, m.windowWidth)) + "\n")
+		s.WriteString(faintStyle.Render(statusText))
+	}
+
+	if m.toast != "" {
+		s.WriteString(m.theme.style().Foreground(m.theme.success).Render("  " + m.toast))
+	}
+	if m.envProfileMissing {
+		s.WriteString(faintStyle.Render(fmt.Sprintf("  環境変数 %s のプロファイル '%s' が見つかりません", m.profileEnvName, m.activeProfile)))
+	}
+	warningStyle := m.theme.style().Foreground(m.theme.warning)
+	if m.timeoutRemaining > 0 {
+		s.WriteString(warningStyle.Render(fmt.Sprintf("  %d 秒後に %s を自動選択します (キー入力で取り消し)", m.timeoutRemaining, m.profiles[m.cursor].Name)))
+	}
+	if len(m.allProfiles) < m.totalProfiles {
+		s.WriteString(warningStyle.Render(fmt.Sprintf("  (全 %d 件のうち %d 件のみ表示しています)", m.totalProfiles, len(m.allProfiles))))
+	}
+	if cyclic := cyclicProfileNames(m.allProfiles); len(cyclic) > 0 {
+		s.WriteString(warningStyle.Render(fmt.Sprintf("  ⚠ source_profile が循環しています: %s", strings.Join(cyclic, ", "))))
+	}
+	return s.String()
+}
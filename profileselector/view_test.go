@@ -0,0 +1,170 @@
+package profileselector
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// update が指定された場合は、ゴールデンファイルを現在の描画結果で書き換えます (go test -run Golden -update)。
+var update = flag.Bool("update", false, "testdata のゴールデンファイルを更新する")
+
+// asciiTheme は色を付けずに描画する、ゴールデンファイルとの比較用のテーマを返します。
+func asciiTheme() theme {
+	th := darkTheme
+	th.renderer = newStyleRenderer(io.Discard, true)
+	return th
+}
+
+// assertGolden は got を testdata/<name>.golden の内容と比較します。
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ゴールデンファイルを読み込めません (-update で作成してください): %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("描画結果が %s と異なります:\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+func TestRenderTitleGolden(t *testing.T) {
+	tests := []struct {
+		name       string
+		width      int
+		deleteMode bool
+		renameMode bool
+		source     profileSource
+	}{
+		{name: "title_wide", width: 200, source: sourceConfig},
+		{name: "title_narrow", width: 40, source: sourceConfig},
+		{name: "title_tiny", width: 5, source: sourceConfig},
+		{name: "title_zero", width: 0, source: sourceConfig},
+		{name: "title_delete", width: 120, deleteMode: true, source: sourceCredentials},
+		{name: "title_rename", width: 120, renameMode: true, source: sourceConfig},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertGolden(t, tt.name, renderTitle(tt.width, tt.deleteMode, tt.renameMode, tt.source, asciiTheme()))
+		})
+	}
+}
+
+func TestRenderProfileRowGolden(t *testing.T) {
+	th := asciiTheme()
+	plain := rowStyle{theme: th, base: th.style(), cursorIndicator: defaultCursor}
+	detailed := plain
+	detailed.showAccount = true
+	detailed.note = "本番環境"
+	detailed.lastUsed = "3 分前"
+	role := awsProfile{
+		Name:        "prod-admin",
+		Source:      sourceConfig,
+		AccountID:   "123456789012",
+		Description: "本番の管理者",
+		RoleArn:     "arn:aws:iam::123456789012:role/Admin",
+		Output:      "json",
+		RequiresMFA: true,
+		MFASerial:   "arn:aws:iam::123456789012:mfa/user",
+	}
+	tests := []struct {
+		name       string
+		style      rowStyle
+		profile    awsProfile
+		selected   bool
+		active     bool
+		showDetail bool
+		width      int
+	}{
+		{name: "row_plain", style: plain, profile: awsProfile{Name: "dev"}, width: 40},
+		{name: "row_selected", style: plain, profile: awsProfile{Name: "dev"}, selected: true, width: 40},
+		{name: "row_active", style: plain, profile: awsProfile{Name: "dev"}, active: true, width: 40},
+		{name: "row_detail", style: detailed, profile: role, selected: true, showDetail: true, width: 160},
+		{name: "row_empty_name", style: plain, profile: awsProfile{}, width: 20},
+		{name: "row_very_wide", style: detailed, profile: role, width: 400},
+		{name: "row_tiny", style: detailed, profile: role, selected: true, showDetail: true, width: 6},
+		{name: "row_jump_key_indent", style: rowStyle{theme: th, base: th.style(), cursorIndicator: defaultCursor, jumpKey: 3, indent: treeIndent(1)}, profile: awsProfile{Name: "child"}, width: 40},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertGolden(t, tt.name, tt.style.renderProfileRow(tt.profile, tt.selected, tt.active, tt.showDetail, tt.width))
+		})
+	}
+}
+
+func TestRenderErrorScreenGolden(t *testing.T) {
+	t.Setenv(configFileEnv, "")
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{name: "error_generic", err: errors.New("予期しないエラー")},
+		{name: "error_not_found", err: &ConfigNotFoundError{Path: "/nonexistent/aws/config"}},
+		{name: "error_parse", err: &ConfigParseError{Path: "/tmp/aws/config", Cause: errors.New("unclosed section: [profile dev")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertGolden(t, tt.name, renderErrorScreen(tt.err, asciiTheme()))
+		})
+	}
+}
+
+func TestRenderFooterGolden(t *testing.T) {
+	m := newTestModel(t, options{}, testProfiles("dev", "prod"))
+	assertGolden(t, "footer", renderFooter(m))
+}
+
+func TestWrapHelpText(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  string
+	}{
+		{name: "収まる", text: "a:上, b:下", width: 20, want: "a:上, b:下"},
+		{name: "項目の区切りで折り返す", text: "a:上, b:下, c:選択", width: 12, want: "a:上, b:下,\nc:選択"},
+		{name: "1 項目ずつ", text: "a:上, b:下", width: 5, want: "a:上,\nb:下"},
+		{name: "長すぎる項目は省略する", text: "a:とても長い説明, b:下", width: 8, want: "a:とて…\nb:下"},
+		{name: "幅が 0", text: "a:上, b:下", width: 0, want: "a:上, b:下"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wrapHelpText(tt.text, tt.width); got != tt.want {
+				t.Errorf("wrapHelpText(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHelpHeight(t *testing.T) {
+	tests := []struct {
+		width int
+		want  int
+	}{
+		{width: 400, want: 1},
+		{width: 120, want: 3},
+		{width: 80, want: 4},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.width), func(t *testing.T) {
+			m := newTestModel(t, options{}, testProfiles("dev"))
+			m.windowWidth = tt.width
+			if got := m.helpHeight(); got != tt.want {
+				t.Errorf("helpHeight() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
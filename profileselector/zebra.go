@@ -0,0 +1,29 @@
+package profileselector
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// noZebraEnv は一覧の縞模様を無効にする環境変数です ("1" で無効)。
+const noZebraEnv = "AWS_PROFILE_SELECTOR_NO_ZEBRA"
+
+// rowBaseStyle は一覧の i 番目の行の土台となるスタイルを返します。
+// 縞模様が有効な場合は奇数行に背景色を付けます。カーソル行はカーソルの強調が埋もれないように背景色を付けません。
+func (m model) rowBaseStyle(i int) lipgloss.Style {
+	if m.zebra && i%2 == 1 && i != m.cursor {
+		return m.theme.style().Background(m.theme.zebra)
+	}
+	return m.theme.style()
+}
+
+// padRow は行を幅 width に切り詰め、余白を base のスタイル (縞模様の背景色) で埋めます。
+func padRow(row string, width int, base lipgloss.Style) string {
+	row = ansi.Truncate(row, width, "…")
+	if gap := width - ansi.StringWidth(row); gap > 0 {
+		row += base.Render(strings.Repeat(" ", gap))
+	}
+	return row
+}